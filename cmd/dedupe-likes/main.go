@@ -0,0 +1,74 @@
+// Command dedupe-likes menghapus like ganda di Mongo (sisa race toggle lama) supaya
+// unique index di EnsureIndexes bisa dibuat, lalu menghitung ulang like_count.
+// Jalankan sekali sebelum deploy; server tidak lagi menghapus duplikat saat start.
+package main
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/wafi04/chatting-app/config/database"
+	"github.com/wafi04/chatting-app/config/env"
+	"github.com/wafi04/chatting-app/services/counters"
+	"github.com/wafi04/chatting-app/services/likes"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "count duplicate likes without deleting them")
+	flag.Parse()
+
+	log := logger.NewLogger()
+
+	dbURL := env.LoadEnv("DB_URL")
+	if dbURL == "" {
+		log.Log(logger.ErrorLevel, "DB_URL environment variable is not set")
+		return
+	}
+
+	db, err := database.NewDB(dbURL)
+	if err != nil {
+		log.Log(logger.ErrorLevel, "Failed to initialize database: %v", err)
+		return
+	}
+	defer db.Close()
+
+	mongodb, err := database.ConnectMongoDB(log)
+	if err != nil {
+		log.Log(logger.ErrorLevel, "Failed to initialize mongo database: %v", err)
+		return
+	}
+	defer mongodb.Close()
+
+	ctx := context.Background()
+	start := time.Now()
+
+	counterRepo := counters.NewCounterRepository(db.DB)
+	likerepo := likes.NewLikeRepository(mongodb.Client, counterRepo).(*likes.LikeRepository)
+
+	removed, err := likerepo.RemoveDuplicateLikes(ctx, *dryRun)
+	if err != nil {
+		log.Log(logger.ErrorLevel, "Failed to remove duplicate likes: %v", err)
+		return
+	}
+
+	if *dryRun {
+		log.Log(logger.InfoLevel, "Found %d duplicate likes in %s (dry run, nothing deleted)", removed, time.Since(start))
+		return
+	}
+	log.Log(logger.InfoLevel, "Removed %d duplicate likes in %s", removed, time.Since(start))
+
+	if err := likerepo.EnsureIndexes(ctx); err != nil {
+		log.Log(logger.ErrorLevel, "Failed to create like indexes: %v", err)
+		return
+	}
+
+	if err := counters.NewReconciler(counterRepo, likerepo).ReconcileOnce(ctx); err != nil {
+		log.Log(logger.ErrorLevel, "Failed to reconcile counters: %v", err)
+		return
+	}
+
+	log.Log(logger.InfoLevel, "Likes dedupe finished")
+}
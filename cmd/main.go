@@ -10,6 +10,7 @@ import (
 	"github.com/wafi04/chatting-app/config/env"
 	"github.com/wafi04/chatting-app/services/gateway"
 	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func validateCloudinaryConfig() (cloudName, apiKey, apiSecret string, err error) {
@@ -42,12 +43,17 @@ func main() {
 	}
 	defer db.Close()
 
-	mongo, err := database.ConnectMongoDB(log)
-	if err != nil {
-		log.Log(logger.ErrorLevel, "Failed to initialize mongo database: %v", err)
-		return
+	// Mongo hanya dibutuhkan untuk likes backend Mongo dan reactions
+	var mongoClient *mongo.Client
+	if env.LoadEnv("MONGO_URL") != "" {
+		mongodb, err := database.ConnectMongoDB(log)
+		if err != nil {
+			log.Log(logger.ErrorLevel, "Failed to initialize mongo database: %v", err)
+			return
+		}
+		defer mongodb.Close()
+		mongoClient = mongodb.Client
 	}
-	defer mongo.Close()
 
 	// Cloudinary configuration validation
 	cloudName, apiKey, apiSecret, err := validateCloudinaryConfig()
@@ -71,7 +77,11 @@ func main() {
 
 	logs.Info("Starting Server gateway")
 
-	router := gateway.SetUpRoutes(db, mongo.Client, cld)
+	router, err := gateway.SetUpRoutes(db, mongoClient, cld)
+	if err != nil {
+		log.Log(logger.ErrorLevel, "Failed to set up routes: %v", err)
+		return
+	}
 	port := env.LoadEnv("PORT")
 	if port == "" {
 		port = ":8080" // default port if not set
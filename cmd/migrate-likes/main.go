@@ -0,0 +1,181 @@
+// Command migrate-likes menyalin semua like dari Mongo ke tabel likes di Postgres,
+// lalu menghitung ulang like_count di posts dan comments. Aman dijalankan ulang:
+// like yang sudah ada di Postgres dilewati.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jmoiron/sqlx"
+	"github.com/wafi04/chatting-app/config/database"
+	"github.com/wafi04/chatting-app/config/env"
+	"github.com/wafi04/chatting-app/services/counters"
+	"github.com/wafi04/chatting-app/services/likes"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+	"github.com/wafi04/chatting-app/services/shared/types"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func main() {
+	batchSize := flag.Int("batch", 500, "number of likes inserted per statement")
+	dryRun := flag.Bool("dry-run", false, "read likes from Mongo without writing to Postgres")
+	flag.Parse()
+
+	log := logger.NewLogger()
+
+	dbURL := env.LoadEnv("DB_URL")
+	if dbURL == "" {
+		log.Log(logger.ErrorLevel, "DB_URL environment variable is not set")
+		return
+	}
+
+	db, err := database.NewDB(dbURL)
+	if err != nil {
+		log.Log(logger.ErrorLevel, "Failed to initialize database: %v", err)
+		return
+	}
+	defer db.Close()
+
+	mongodb, err := database.ConnectMongoDB(log)
+	if err != nil {
+		log.Log(logger.ErrorLevel, "Failed to initialize mongo database: %v", err)
+		return
+	}
+	defer mongodb.Close()
+
+	ctx := context.Background()
+	collection := mongodb.Client.Database(likes.MongoDatabase()).Collection(likes.MongoCollection)
+
+	cursor, err := collection.Find(ctx, bson.M{})
+	if err != nil {
+		log.Log(logger.ErrorLevel, "Failed to read likes from Mongo: %v", err)
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var (
+		batch                                     []types.Like
+		read, inserted, existing, orphan, invalid int64
+		start                                     = time.Now()
+	)
+
+	flush := func() error {
+		if len(batch) == 0 || *dryRun {
+			batch = batch[:0]
+			return nil
+		}
+		result, err := insertLikes(ctx, db.DB, batch)
+		if err != nil {
+			return err
+		}
+		inserted += result.Inserted
+		existing += result.Existing
+		orphan += result.Orphan
+		batch = batch[:0]
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var like types.Like
+		if err := cursor.Decode(&like); err != nil {
+			log.Log(logger.ErrorLevel, "Failed to decode like: %v", err)
+			return
+		}
+		read++
+
+		// Setiap like harus punya tepat satu target
+		if (like.PostID == nil) == (like.CommentID == nil) {
+			invalid++
+			continue
+		}
+
+		batch = append(batch, like)
+		if len(batch) >= *batchSize {
+			if err := flush(); err != nil {
+				log.Log(logger.ErrorLevel, "Failed to insert likes: %v", err)
+				return
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		log.Log(logger.ErrorLevel, "Failed to iterate likes: %v", err)
+		return
+	}
+	if err := flush(); err != nil {
+		log.Log(logger.ErrorLevel, "Failed to insert likes: %v", err)
+		return
+	}
+
+	if *dryRun {
+		log.Log(logger.InfoLevel, "Dry run: read %d likes, %d invalid, nothing written in %s",
+			read, invalid, time.Since(start))
+		return
+	}
+
+	log.Log(logger.InfoLevel, "Read %d likes: inserted %d, skipped %d already in Postgres, %d with missing user or target, %d invalid in %s",
+		read, inserted, existing, orphan, invalid, time.Since(start))
+
+	reconciler := counters.NewReconciler(counters.NewCounterRepository(db.DB), likes.NewPostgresLikeRepository(db.DB))
+	if err := reconciler.ReconcileOnce(ctx); err != nil {
+		log.Log(logger.ErrorLevel, "Failed to reconcile counters: %v", err)
+		return
+	}
+
+	log.Log(logger.InfoLevel, "Likes migration finished")
+}
+
+// insertResult merinci nasib satu batch like
+type insertResult struct {
+	Inserted int64
+	Existing int64
+	Orphan   int64
+}
+
+// insertLikes menulis satu batch like, melewati like yang sudah ada
+func insertLikes(ctx context.Context, db *sqlx.DB, batch []types.Like) (insertResult, error) {
+	var (
+		placeholders []string
+		args         []interface{}
+	)
+	for i, like := range batch {
+		n := i * 5
+		placeholders = append(placeholders, fmt.Sprintf("($%d::text, $%d::text, $%d::text, $%d::text, $%d::timestamptz)", n+1, n+2, n+3, n+4, n+5))
+		args = append(args, like.ID.Hex(), like.UserID, like.PostID, like.CommentID, like.CreatedAt)
+	}
+
+	// Like dari user, post atau comment yang sudah tidak ada dilewati supaya tidak
+	// melanggar foreign key.
+	// Jumlah baris valid dan yang benar-benar tertulis dihitung terpisah supaya
+	// konflik ON CONFLICT DO NOTHING tidak tercampur dengan like yatim.
+	query := `
+    WITH valid AS (
+        SELECT v.id, v.user_id, v.post_id, v.comment_id, v.created_at
+        FROM (VALUES ` + strings.Join(placeholders, ", ") + `) AS v(id, user_id, post_id, comment_id, created_at)
+        JOIN users u ON u.user_id = v.user_id
+        WHERE (v.post_id IS NULL OR EXISTS (SELECT 1 FROM posts p WHERE p.id = v.post_id))
+        AND (v.comment_id IS NULL OR EXISTS (SELECT 1 FROM comments c WHERE c.id = v.comment_id))
+    ), inserted AS (
+        INSERT INTO likes (id, user_id, post_id, comment_id, created_at)
+        SELECT id, user_id, post_id, comment_id, created_at FROM valid
+        ON CONFLICT DO NOTHING
+        RETURNING 1
+    )
+    SELECT (SELECT COUNT(*) FROM valid), (SELECT COUNT(*) FROM inserted)
+    `
+
+	var valid, inserted int64
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&valid, &inserted); err != nil {
+		return insertResult{}, err
+	}
+
+	return insertResult{
+		Inserted: inserted,
+		Existing: valid - inserted,
+		Orphan:   int64(len(batch)) - valid,
+	}, nil
+}
@@ -0,0 +1,21 @@
+// Command vapid-keys membuat pasangan kunci VAPID untuk Web Push dan mencetaknya
+// dalam format env (VAPID_PUBLIC_KEY, VAPID_PRIVATE_KEY)
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/wafi04/chatting-app/services/push"
+)
+
+func main() {
+	publicKey, privateKey, err := push.GenerateVAPIDKeys()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to generate vapid keys: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("VAPID_PUBLIC_KEY=%s\n", publicKey)
+	fmt.Printf("VAPID_PRIVATE_KEY=%s\n", privateKey)
+}
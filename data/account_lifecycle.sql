@@ -0,0 +1,29 @@
+-- Nonaktif mandiri & penghapusan akun dengan masa tenggang.
+-- is_active = false tanpa deactivated_at berarti diblokir admin dan tidak bisa dipulihkan lewat login.
+ALTER TABLE public.users
+    ADD COLUMN IF NOT EXISTS deactivated_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX IF NOT EXISTS idx_users_deletion_scheduled
+    ON public.users (deletion_scheduled_at)
+    WHERE deletion_scheduled_at IS NOT NULL;
+
+-- Job "download my data"
+CREATE TABLE IF NOT EXISTS public.data_exports (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES public.users (user_id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL,
+    file_path TEXT,
+    error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    expires_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_data_exports_user ON public.data_exports (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_data_exports_pending ON public.data_exports (created_at) WHERE status = 'pending';
+
+-- Diisi worker sebelum menghapus data di luar Postgres (media, like, reaction);
+-- akun yang sudah mulai di-purge tidak bisa dipulihkan lewat login
+ALTER TABLE public.users ADD COLUMN IF NOT EXISTS purge_started_at TIMESTAMP WITH TIME ZONE;
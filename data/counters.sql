@@ -0,0 +1,16 @@
+-- Counter denormalisasi untuk like dan comment
+ALTER TABLE public.posts ADD COLUMN like_count bigint NOT NULL DEFAULT 0;
+ALTER TABLE public.posts ADD COLUMN comment_count bigint NOT NULL DEFAULT 0;
+ALTER TABLE public.comments ADD COLUMN like_count bigint NOT NULL DEFAULT 0;
+ALTER TABLE public.comments ADD COLUMN reply_count bigint NOT NULL DEFAULT 0;
+
+-- Isi awal comment counter, like counter akan diisi oleh job reconciliation
+UPDATE public.posts p
+SET comment_count = c.cnt
+FROM (SELECT post_id, COUNT(*) AS cnt FROM public.comments GROUP BY post_id) c
+WHERE c.post_id = p.id;
+
+UPDATE public.comments p
+SET reply_count = c.cnt
+FROM (SELECT parent_comment_id, COUNT(*) AS cnt FROM public.comments WHERE parent_comment_id IS NOT NULL GROUP BY parent_comment_id) c
+WHERE c.parent_comment_id = p.id;
@@ -0,0 +1,10 @@
+-- Digest email untuk user yang lama tidak membuka aplikasi
+ALTER TABLE public.notification_settings ADD COLUMN IF NOT EXISTS digest_enabled BOOLEAN NOT NULL DEFAULT true;
+
+-- last_run_at: terakhir user diperiksa (juga batas awal isi digest berikutnya),
+-- last_sent_at: terakhir email benar-benar dikirim
+CREATE TABLE IF NOT EXISTS public.email_digests (
+    user_id VARCHAR(36) PRIMARY KEY REFERENCES public.users (user_id) ON DELETE CASCADE,
+    last_run_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    last_sent_at TIMESTAMP WITH TIME ZONE
+);
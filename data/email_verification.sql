@@ -0,0 +1,7 @@
+-- Dipakai untuk throttling resend verifikasi email
+CREATE INDEX IF NOT EXISTS idx_verification_tokens_user_type
+    ON public.verification_tokens USING btree (user_id, token_type, created_at DESC);
+
+-- Kode verifikasi salah per token; token dinonaktifkan setelah batas tercapai.
+-- Kolom token kini berisi hash SHA-256, token lama (plaintext) otomatis kedaluwarsa dalam 1 jam.
+ALTER TABLE public.verification_tokens ADD COLUMN IF NOT EXISTS failed_attempts INTEGER NOT NULL DEFAULT 0;
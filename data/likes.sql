@@ -0,0 +1,28 @@
+-- Backend Postgres untuk likes (LIKES_BACKEND=postgres)
+CREATE TABLE public.likes (
+    id character varying(24) PRIMARY KEY,
+    user_id character varying(36) NOT NULL,
+    post_id character varying(50),
+    comment_id character varying(50),
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT likes_single_target CHECK ((post_id IS NULL) <> (comment_id IS NULL)),
+    CONSTRAINT likes_user_id_fkey FOREIGN KEY (user_id)
+        REFERENCES public.users (user_id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX uniq_likes_user_post ON public.likes (user_id, post_id) WHERE post_id IS NOT NULL;
+CREATE UNIQUE INDEX uniq_likes_user_comment ON public.likes (user_id, comment_id) WHERE comment_id IS NOT NULL;
+CREATE INDEX idx_likes_post_created_at ON public.likes (post_id, created_at DESC) WHERE post_id IS NOT NULL;
+CREATE INDEX idx_likes_comment_created_at ON public.likes (comment_id, created_at DESC) WHERE comment_id IS NOT NULL;
+
+-- Like harus menunjuk ke post atau comment yang ada; like yatim dibuang dulu
+-- sebelum constraint dipasang
+DELETE FROM public.likes l
+WHERE (l.post_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM public.posts p WHERE p.id = l.post_id))
+   OR (l.comment_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM public.comments c WHERE c.id = l.comment_id));
+
+ALTER TABLE public.likes
+    ADD CONSTRAINT likes_post_id_fkey FOREIGN KEY (post_id)
+        REFERENCES public.posts (id) ON DELETE CASCADE,
+    ADD CONSTRAINT likes_comment_id_fkey FOREIGN KEY (comment_id)
+        REFERENCES public.comments (id) ON DELETE CASCADE;
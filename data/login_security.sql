@@ -0,0 +1,27 @@
+-- Counter gagal login per akun dan per IP untuk lockout eksponensial
+CREATE TABLE IF NOT EXISTS public.login_attempts (
+    key_type VARCHAR(16) NOT NULL,
+    key TEXT NOT NULL,
+    failed_count INTEGER NOT NULL DEFAULT 0,
+    last_failed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    PRIMARY KEY (key_type, key)
+);
+
+-- Audit login yang gagal
+CREATE TABLE IF NOT EXISTS public.login_audit (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    identifier TEXT NOT NULL,
+    user_id VARCHAR(36) REFERENCES public.users (user_id) ON DELETE SET NULL,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    reason VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_audit_user ON public.login_audit (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_login_audit_ip ON public.login_audit (ip_address, created_at DESC);
+
+-- Login by email / username tidak case-sensitive
+CREATE INDEX IF NOT EXISTS idx_users_email_lower ON public.users (LOWER(email));
+CREATE INDEX IF NOT EXISTS idx_user_profile_username_lower ON public.user_profile (LOWER(username));
@@ -0,0 +1,30 @@
+-- Preferensi notifikasi. Hanya pengaturan yang berbeda dari default (semua aktif) yang disimpan.
+CREATE TABLE IF NOT EXISTS public.notification_settings (
+    user_id VARCHAR(36) PRIMARY KEY REFERENCES public.users (user_id) ON DELETE CASCADE,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+    quiet_hours_enabled BOOLEAN NOT NULL DEFAULT false,
+    -- Menit sejak tengah malam di timezone user; start > end berarti melewati tengah malam
+    quiet_start SMALLINT NOT NULL DEFAULT 1320,
+    quiet_end SMALLINT NOT NULL DEFAULT 420,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS public.notification_preferences (
+    user_id VARCHAR(36) NOT NULL REFERENCES public.users (user_id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    channel VARCHAR(16) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    PRIMARY KEY (user_id, type, channel)
+);
+
+-- Post, thread comment atau percakapan yang tidak ingin dinotifikasi lagi
+CREATE TABLE IF NOT EXISTS public.notification_mutes (
+    user_id VARCHAR(36) NOT NULL REFERENCES public.users (user_id) ON DELETE CASCADE,
+    target_id VARCHAR(100) NOT NULL,
+    target_type VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, target_id)
+);
+
+-- Event tetap dicatat walau in-app dimatikan (untuk dedupe & channel lain), tapi tidak ditampilkan
+ALTER TABLE public.notifications ADD COLUMN IF NOT EXISTS in_app BOOLEAN NOT NULL DEFAULT true;
@@ -0,0 +1,21 @@
+-- Notifikasi in-app. Satu baris per event; pengelompokan ("X and 12 others liked your post")
+-- dilakukan saat dibaca berdasarkan group_key.
+CREATE TABLE IF NOT EXISTS public.notifications (
+    id BIGSERIAL PRIMARY KEY,
+    recipient_id VARCHAR(36) NOT NULL REFERENCES public.users (user_id) ON DELETE CASCADE,
+    actor_id VARCHAR(36) NOT NULL REFERENCES public.users (user_id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    group_key VARCHAR(200) NOT NULL,
+    target_id VARCHAR(100),
+    source_id VARCHAR(100),
+    preview TEXT,
+    -- Event yang sama (misal like -> unlike -> like) hanya dicatat sekali
+    dedupe_key VARCHAR(400) NOT NULL,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (recipient_id, dedupe_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_recipient ON public.notifications (recipient_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_unread ON public.notifications (recipient_id, group_key) WHERE read_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notifications_actor ON public.notifications (actor_id);
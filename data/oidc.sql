@@ -0,0 +1,21 @@
+-- State login OIDC (authorization code + PKCE), dihapus saat callback
+CREATE TABLE IF NOT EXISTS public.oidc_states (
+    state_hash CHAR(64) PRIMARY KEY,
+    provider VARCHAR(50) NOT NULL,
+    nonce TEXT NOT NULL,
+    code_verifier TEXT NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Identitas eksternal yang ditautkan ke user
+CREATE TABLE IF NOT EXISTS public.user_identities (
+    provider VARCHAR(50) NOT NULL,
+    subject TEXT NOT NULL,
+    user_id VARCHAR(36) NOT NULL REFERENCES public.users (user_id) ON DELETE CASCADE,
+    email VARCHAR(255),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user ON public.user_identities (user_id);
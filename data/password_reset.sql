@@ -0,0 +1,11 @@
+-- Token reset lama disimpan sebagai JWT mentah dengan token_type yang tidak konsisten,
+-- matikan semuanya; token baru disimpan sebagai sha256 dengan token_type 'PASSWORD_RESET'
+UPDATE public.verification_tokens
+SET is_used = true
+WHERE token_type IN ('PASSWORD', 'password_reset') AND is_used = false;
+
+-- IP peminta reset, untuk membatasi permintaan reset per IP
+ALTER TABLE public.verification_tokens ADD COLUMN IF NOT EXISTS requested_ip VARCHAR(45);
+CREATE INDEX IF NOT EXISTS idx_verification_tokens_reset_ip
+    ON public.verification_tokens (requested_ip, created_at)
+    WHERE token_type = 'PASSWORD_RESET';
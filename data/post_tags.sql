@@ -0,0 +1,20 @@
+-- Index hashtag ternormalisasi untuk setiap post
+CREATE TABLE public.post_tags (
+    post_id character varying(50) NOT NULL,
+    tag character varying(64) NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (post_id, tag),
+    CONSTRAINT post_tags_post_id_fkey FOREIGN KEY (post_id)
+        REFERENCES public.posts (id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_post_tags_tag_created_at ON public.post_tags USING btree (tag, created_at DESC);
+CREATE INDEX idx_post_tags_created_at ON public.post_tags USING btree (created_at);
+CREATE INDEX idx_posts_tags ON public.posts USING gin (tags);
+
+-- Backfill dari kolom posts.tags yang sudah ada
+INSERT INTO public.post_tags (post_id, tag, created_at)
+SELECT DISTINCT p.id, lower(trim(leading '#' from t.tag)), p.created_at
+FROM public.posts p, unnest(p.tags) AS t(tag)
+WHERE trim(leading '#' from t.tag) <> ''
+ON CONFLICT DO NOTHING;
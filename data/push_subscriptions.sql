@@ -0,0 +1,17 @@
+-- Subscription push per perangkat/browser. Endpoint unik: subscribe ulang dari browser yang
+-- sama memperbarui baris lama (termasuk pindah ke user lain setelah logout/login).
+CREATE TABLE IF NOT EXISTS public.push_subscriptions (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES public.users (user_id) ON DELETE CASCADE,
+    platform VARCHAR(16) NOT NULL,
+    endpoint TEXT NOT NULL UNIQUE,
+    p256dh TEXT NOT NULL DEFAULT '',
+    auth TEXT NOT NULL DEFAULT '',
+    user_agent TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    -- Kegagalan berturut-turut; subscription dihapus saat mencapai PUSH_MAX_FAILURES
+    failure_count INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_subscriptions_user ON public.push_subscriptions (user_id);
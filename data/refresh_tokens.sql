@@ -0,0 +1,17 @@
+-- Riwayat refresh token per sesi (satu sesi = satu keluarga rotasi).
+-- Token yang sudah dirotasi disimpan agar pemakaian ulangnya bisa dideteksi.
+CREATE TABLE IF NOT EXISTS public.session_refresh_tokens (
+    token_hash CHAR(64) PRIMARY KEY,
+    session_id VARCHAR(50) NOT NULL REFERENCES public.sessions (session_id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    rotated_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_session_refresh_tokens_session
+    ON public.session_refresh_tokens (session_id);
+
+-- Kolom access_token/refresh_token di sessions sekarang berisi sha256, bukan token mentah.
+-- Sesi lama menyimpan JWT mentah sehingga dinonaktifkan; user perlu login ulang.
+UPDATE public.sessions SET is_active = false, updated_at = CURRENT_TIMESTAMP WHERE is_active = true;
+CREATE INDEX IF NOT EXISTS idx_sessions_refresh_token ON public.sessions USING btree (refresh_token);
@@ -0,0 +1,11 @@
+-- Role user untuk RBAC: user, moderator, admin
+ALTER TABLE public.users
+    ADD COLUMN IF NOT EXISTS role VARCHAR(20) NOT NULL DEFAULT 'user';
+
+ALTER TABLE public.users
+    ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'moderator', 'admin'));
+
+CREATE INDEX IF NOT EXISTS idx_users_role ON public.users (role) WHERE role <> 'user';
+
+-- Admin pertama dibuat manual, contoh:
+-- UPDATE public.users SET role = 'admin' WHERE email = 'admin@example.com';
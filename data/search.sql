@@ -0,0 +1,22 @@
+-- Full-text search untuk users, user_profile, posts dan comments
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE public.users ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(name, ''))) STORED;
+CREATE INDEX idx_users_search_vector ON public.users USING gin (search_vector);
+
+ALTER TABLE user_profile ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(username, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(bio, '')), 'B')
+    ) STORED;
+CREATE INDEX idx_user_profile_search_vector ON user_profile USING gin (search_vector);
+CREATE INDEX idx_user_profile_username_trgm ON user_profile USING gin (username gin_trgm_ops);
+
+ALTER TABLE public.posts ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(caption, ''))) STORED;
+CREATE INDEX idx_posts_search_vector ON public.posts USING gin (search_vector);
+
+ALTER TABLE public.comments ADD COLUMN search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(content, ''))) STORED;
+CREATE INDEX idx_comments_search_vector ON public.comments USING gin (search_vector);
@@ -0,0 +1,41 @@
+-- Riwayat aktivitas keamanan per user (login, perangkat baru, password, 2FA, sesi)
+CREATE TABLE IF NOT EXISTS public.security_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES public.users (user_id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    device VARCHAR(20),
+    browser VARCHAR(50),
+    os VARCHAR(50),
+    metadata JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_user ON public.security_events (user_id, id DESC);
+
+-- Perangkat yang pernah dipakai login; fingerprint = browser|os|device tanpa versi
+CREATE TABLE IF NOT EXISTS public.known_devices (
+    user_id VARCHAR(36) NOT NULL REFERENCES public.users (user_id) ON DELETE CASCADE,
+    fingerprint VARCHAR(150) NOT NULL,
+    first_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_ip_address VARCHAR(45),
+    PRIMARY KEY (user_id, fingerprint)
+);
+
+-- Fingerprint lama menyimpan versi OS (misal "Safari|iOS 17.1|mobile"); samakan ke nama OS saja
+-- supaya perangkat yang sudah dikenal tidak memicu email perangkat baru setelah deploy
+INSERT INTO public.known_devices (user_id, fingerprint, first_seen_at, last_seen_at, last_ip_address)
+SELECT DISTINCT ON (user_id, normalized)
+    user_id, normalized, first_seen_at, last_seen_at, last_ip_address
+FROM (
+    SELECT *, regexp_replace(fingerprint, '\|(iOS|iPadOS|Android) [0-9.]+\|', '|\1|') AS normalized
+    FROM public.known_devices
+) d
+WHERE normalized <> fingerprint
+ORDER BY user_id, normalized, last_seen_at DESC
+ON CONFLICT (user_id, fingerprint) DO NOTHING;
+
+DELETE FROM public.known_devices
+WHERE fingerprint ~ '\|(iOS|iPadOS|Android) [0-9.]+\|';
@@ -0,0 +1,22 @@
+-- TOTP 2FA; last_used_step mencegah kode yang sama dipakai dua kali
+CREATE TABLE IF NOT EXISTS public.user_totp (
+    user_id VARCHAR(36) PRIMARY KEY REFERENCES public.users (user_id) ON DELETE CASCADE,
+    secret TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    confirmed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Recovery code sekali pakai, hanya sha256 yang disimpan
+CREATE TABLE IF NOT EXISTS public.user_recovery_codes (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES public.users (user_id) ON DELETE CASCADE,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, code_hash)
+);
+
+-- Challenge login 2FA disimpan di verification_tokens dengan token_type 'LOGIN_2FA'
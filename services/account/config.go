@@ -0,0 +1,54 @@
+package account
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wafi04/chatting-app/config/env"
+)
+
+type Config struct {
+	// DeletionGrace masa tenggang sebelum akun benar-benar dihapus; login membatalkannya
+	DeletionGrace time.Duration
+	ExportDir     string
+	ExportTTL     time.Duration
+	// WorkerInterval jeda antar putaran purge dan pemrosesan export
+	WorkerInterval time.Duration
+}
+
+// LoadConfig membaca ACCOUNT_DELETION_GRACE, DATA_EXPORT_DIR, DATA_EXPORT_TTL dan ACCOUNT_WORKER_INTERVAL
+func LoadConfig() (*Config, error) {
+	config := &Config{
+		DeletionGrace:  30 * 24 * time.Hour,
+		ExportDir:      env.LoadEnv("DATA_EXPORT_DIR"),
+		ExportTTL:      7 * 24 * time.Hour,
+		WorkerInterval: time.Minute,
+	}
+	if config.ExportDir == "" {
+		config.ExportDir = filepath.Join(os.TempDir(), "chatapp-exports")
+	}
+
+	durations := []struct {
+		key    string
+		target *time.Duration
+	}{
+		{"ACCOUNT_DELETION_GRACE", &config.DeletionGrace},
+		{"DATA_EXPORT_TTL", &config.ExportTTL},
+		{"ACCOUNT_WORKER_INTERVAL", &config.WorkerInterval},
+	}
+	for _, d := range durations {
+		raw := env.LoadEnv(d.key)
+		if raw == "" {
+			continue
+		}
+		value, err := time.ParseDuration(raw)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("invalid %s: %q", d.key, raw)
+		}
+		*d.target = value
+	}
+
+	return config, nil
+}
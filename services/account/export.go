@@ -0,0 +1,152 @@
+package account
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+// exportSections adalah data Postgres yang masuk ke arsip, satu file JSON per section.
+// Secret (password hash, secret TOTP, hash token) sengaja tidak ikut diekspor.
+var exportSections = []struct {
+	file  string
+	query string
+}{
+	{"account.json", `SELECT user_id, name, email, picture, is_email_verified, is_active, role, created_at, updated_at, last_login_at FROM users WHERE user_id = $1`},
+	{"profile.json", `SELECT username, place_birth, date_birth, bio, is_privacy, phone_number, gender, updated_at FROM user_profile WHERE user_id = $1`},
+	{"posts.json", `SELECT id, caption, location, tags, mentions, like_count, comment_count, created_at, updated_at FROM posts WHERE user_id = $1 ORDER BY created_at`},
+	{"media.json", `SELECT m.id, m.post_id, m.file_url, m.file_type, m.file_name, m.created_at FROM media m JOIN posts p ON p.id = m.post_id WHERE p.user_id = $1 ORDER BY m.created_at`},
+	{"comments.json", `SELECT id, post_id, parent_comment_id, content, like_count, reply_count, created_at FROM comments WHERE user_id = $1 ORDER BY created_at`},
+	{"followers.json", `SELECT follower_id, is_close_friend, is_blocked, created_at FROM followers WHERE following_id = $1 ORDER BY created_at`},
+	{"following.json", `SELECT following_id, is_close_friend, is_blocked, created_at FROM followers WHERE follower_id = $1 ORDER BY created_at`},
+	{"follow_requests.json", `SELECT id, follower_id, following_id, status, created_at FROM follow_request WHERE follower_id = $1 OR following_id = $1 ORDER BY created_at`},
+	{"sessions.json", `SELECT session_id, ip_address, device_info, is_active, created_at, last_activity_at, expires_at FROM sessions WHERE user_id = $1 ORDER BY created_at`},
+	{"login_history.json", `SELECT ip_address, user_agent, reason, created_at FROM login_audit WHERE user_id = $1 ORDER BY created_at`},
+	{"linked_accounts.json", `SELECT provider, email, created_at FROM user_identities WHERE user_id = $1 ORDER BY created_at`},
+	{"access_tokens.json", `SELECT name, token_prefix, scopes, created_at, expires_at, last_used_at, revoked_at FROM personal_access_tokens WHERE user_id = $1 ORDER BY created_at`},
+	{"two_factor.json", `SELECT enabled, confirmed_at FROM user_totp WHERE user_id = $1`},
+	{"security_events.json", `SELECT event_type, ip_address, user_agent, device, browser, os, metadata, created_at FROM security_events WHERE user_id = $1 ORDER BY id`},
+	{"notifications.json", `SELECT type, actor_id, target_id, preview, read_at, created_at FROM notifications WHERE recipient_id = $1 ORDER BY id`},
+	{"push_subscriptions.json", `SELECT platform, user_agent, created_at, last_used_at FROM push_subscriptions WHERE user_id = $1 ORDER BY created_at`},
+}
+
+type archiveEntry struct {
+	name string
+	data interface{}
+}
+
+// buildExport mengumpulkan semua data user dan menulis arsip zip ke dir
+func (w *Worker) buildExport(ctx context.Context, export *types.DataExport) (string, error) {
+	entries := []archiveEntry{{"export.json", map[string]interface{}{
+		"export_id":    export.Id,
+		"user_id":      export.UserId,
+		"generated_at": time.Now().UTC(),
+	}}}
+
+	for _, section := range exportSections {
+		rows, err := queryMaps(ctx, w.db, section.query, export.UserId)
+		if err != nil {
+			return "", fmt.Errorf("failed to export %s: %w", section.file, err)
+		}
+		entries = append(entries, archiveEntry{section.file, rows})
+	}
+
+	postLikes, err := w.likes.GetUserPostLikes(ctx, export.UserId)
+	if err != nil {
+		return "", err
+	}
+	commentLikes, err := w.likes.GetUserCommentLikes(ctx, export.UserId)
+	if err != nil {
+		return "", err
+	}
+	entries = append(entries, archiveEntry{"likes.json", map[string]interface{}{
+		"posts":    postLikes,
+		"comments": commentLikes,
+	}})
+
+	if w.reactions != nil {
+		reactions, err := w.reactions.GetUserReactions(ctx, export.UserId)
+		if err != nil {
+			return "", err
+		}
+		entries = append(entries, archiveEntry{"reactions.json", reactions})
+	}
+
+	path := filepath.Join(w.exportDir, export.Id+".zip")
+	if err := writeArchive(path, entries); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// queryMaps menjalankan query dan mengubah setiap baris menjadi map kolom -> nilai
+func queryMaps(ctx context.Context, db *sqlx.DB, query string, args ...interface{}) ([]map[string]interface{}, error) {
+	rows, err := db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := []map[string]interface{}{}
+	for rows.Next() {
+		row := map[string]interface{}{}
+		if err := rows.MapScan(row); err != nil {
+			return nil, err
+		}
+		for k, v := range row {
+			// Kolom text/array dikembalikan driver sebagai []byte
+			if b, ok := v.([]byte); ok {
+				row[k] = string(b)
+			}
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// writeArchive menulis entries sebagai file JSON ke zip di path. File ditulis ke
+// file sementara dulu supaya arsip setengah jadi tidak pernah bisa diunduh.
+func writeArchive(path string, entries []archiveEntry) (err error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create export dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".export-*")
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	zw := zip.NewWriter(tmp)
+	for _, entry := range entries {
+		f, err := zw.Create(entry.name)
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", entry.name, err)
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(entry.data); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", entry.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close export file: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,44 @@
+package account
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteArchive(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "exports", "abc.zip")
+
+	err := writeArchive(path, []archiveEntry{
+		{"account.json", []map[string]interface{}{{"user_id": "u1", "email": "a@example.com"}}},
+		{"posts.json", []map[string]interface{}{}},
+	})
+	require.NoError(t, err)
+
+	zr, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	defer zr.Close()
+
+	require.Len(t, zr.File, 2)
+	assert.Equal(t, "account.json", zr.File[0].Name)
+	assert.Equal(t, "posts.json", zr.File[1].Name)
+
+	f, err := zr.File[0].Open()
+	require.NoError(t, err)
+	defer f.Close()
+
+	var account []map[string]string
+	require.NoError(t, json.NewDecoder(f).Decode(&account))
+	assert.Equal(t, "a@example.com", account[0]["email"])
+
+	// file sementara tidak boleh tertinggal
+	files, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
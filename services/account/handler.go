@@ -0,0 +1,137 @@
+package account
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	authrepository "github.com/wafi04/chatting-app/services/auth/pkg/repository"
+	"github.com/wafi04/chatting-app/services/shared/middleware"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+	"github.com/wafi04/chatting-app/services/shared/pkg/response"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+type AccountHandler struct {
+	srv      *AccountService
+	sessions *middleware.SessionCache
+	log      *logger.Logger
+}
+
+func NewAccountHandler(srv *AccountService, sessions *middleware.SessionCache) *AccountHandler {
+	return &AccountHandler{
+		srv:      srv,
+		sessions: sessions,
+		log:      logger.NewLogger(),
+	}
+}
+
+func (h *AccountHandler) HandleDeactivate(c *gin.Context) {
+	h.handleDeactivate(c, false)
+}
+
+func (h *AccountHandler) HandleDelete(c *gin.Context) {
+	h.handleDeactivate(c, true)
+}
+
+func (h *AccountHandler) handleDeactivate(c *gin.Context, deleteAccount bool) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req types.AccountPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Password == "" {
+		response.SendErrorResponse(c, http.StatusBadRequest, "Password is required")
+		return
+	}
+
+	var resp *types.DeactivateAccountResponse
+	if deleteAccount {
+		resp, err = h.srv.RequestDeletion(c.Request.Context(), user.UserId, req.Password)
+	} else {
+		resp, err = h.srv.Deactivate(c.Request.Context(), user.UserId, req.Password)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, authrepository.ErrInvalidCredentials):
+			response.SendErrorResponse(c, http.StatusUnauthorized, "Invalid password")
+		case errors.Is(err, ErrAccountInactive):
+			response.SendErrorResponse(c, http.StatusConflict, "Account is already deactivated")
+		default:
+			h.log.Log(logger.ErrorLevel, "Failed to deactivate account: %v", err)
+			response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to deactivate account")
+		}
+		return
+	}
+
+	h.sessions.Invalidate(resp.RevokedSessions...)
+	middleware.ClearTokens(c)
+
+	if deleteAccount {
+		response.SendSuccessResponse(c, http.StatusOK, "Account scheduled for deletion, log in again before the deadline to cancel", resp)
+		return
+	}
+	response.SendSuccessResponse(c, http.StatusOK, "Account deactivated, log in again to reactivate", resp)
+}
+
+func (h *AccountHandler) HandleRequestExport(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	export, err := h.srv.RequestExport(c.Request.Context(), user.UserId)
+	if err != nil {
+		h.log.Log(logger.ErrorLevel, "Failed to request data export: %v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to request data export")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusAccepted, "Data export requested", export)
+}
+
+func (h *AccountHandler) HandleGetExport(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	export, err := h.srv.GetExport(c.Request.Context(), user.UserId, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, ErrExportNotFound) {
+			response.SendErrorResponse(c, http.StatusNotFound, "Data export not found")
+			return
+		}
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get data export")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Data export retrieved", export)
+}
+
+func (h *AccountHandler) HandleDownloadExport(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	path, err := h.srv.ExportFile(c.Request.Context(), user.UserId, c.Param("id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrExportNotFound):
+			response.SendErrorResponse(c, http.StatusNotFound, "Data export not found or expired")
+		case errors.Is(err, ErrExportNotReady):
+			response.SendErrorResponse(c, http.StatusConflict, "Data export is not ready yet")
+		default:
+			response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to download data export")
+		}
+		return
+	}
+
+	c.FileAttachment(path, "chatapp-data-"+c.Param("id")+".zip")
+}
@@ -0,0 +1,347 @@
+package account
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+var (
+	ErrAccountInactive   = errors.New("account is already deactivated")
+	ErrExportNotFound    = errors.New("data export not found")
+	ErrExportNotReady    = errors.New("data export is not ready")
+	ErrDeletionCancelled = errors.New("account deletion was cancelled")
+)
+
+type AccountRepository struct {
+	db *sqlx.DB
+}
+
+func NewAccountRepository(db *sqlx.DB) *AccountRepository {
+	return &AccountRepository{
+		db: db,
+	}
+}
+
+// Deactivate menonaktifkan akun dan mencabut semua sesinya. deleteAt tidak nil berarti
+// akun sekaligus dijadwalkan untuk dihapus permanen. Mengembalikan id sesi yang dicabut.
+func (r *AccountRepository) Deactivate(ctx context.Context, userId string, deleteAt *time.Time) ([]string, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+        UPDATE users
+        SET is_active = false,
+            deactivated_at = NOW(),
+            deletion_scheduled_at = $2,
+            updated_at = NOW()
+        WHERE user_id = $1 AND is_active = true
+    `, userId, deleteAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to deactivate account: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, ErrAccountInactive
+	}
+
+	var revoked []string
+	err = tx.SelectContext(ctx, &revoked, `
+        UPDATE sessions
+        SET is_active = false, updated_at = NOW()
+        WHERE user_id = $1 AND is_active = true
+        RETURNING session_id
+    `, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return revoked, nil
+}
+
+// Purge yang sudah diklaim tapi tidak selesai (worker mati) boleh diambil alih setelah lease ini
+const purgeLease = 15 * time.Minute
+
+// DueDeletions mengembalikan user yang masa tenggang penghapusannya sudah lewat
+// dan belum sedang di-purge oleh worker lain
+func (r *AccountRepository) DueDeletions(ctx context.Context, limit int) ([]string, error) {
+	var ids []string
+	err := r.db.SelectContext(ctx, &ids, `
+        SELECT user_id FROM users
+        WHERE is_active = false AND deletion_scheduled_at <= NOW()
+        AND (purge_started_at IS NULL OR purge_started_at < $2)
+        ORDER BY deletion_scheduled_at
+        LIMIT $1
+    `, limit, time.Now().Add(-purgeLease))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due deletions: %w", err)
+	}
+	return ids, nil
+}
+
+// UserContent berisi id konten milik user yang perlu dibersihkan di luar Postgres
+type UserContent struct {
+	PostIds        []string
+	CommentIds     []string
+	MediaPublicIds []string
+}
+
+// GetUserContent mengumpulkan post, comment (termasuk balasan orang lain di bawahnya dan
+// komentar pada post user) dan media milik user
+func (r *AccountRepository) GetUserContent(ctx context.Context, userId string) (*UserContent, error) {
+	content := &UserContent{}
+
+	if err := r.db.SelectContext(ctx, &content.PostIds, `SELECT id FROM posts WHERE user_id = $1`, userId); err != nil {
+		return nil, fmt.Errorf("failed to get user posts: %w", err)
+	}
+
+	err := r.db.SelectContext(ctx, &content.CommentIds, `
+        WITH RECURSIVE comment_tree AS (
+            SELECT id FROM comments
+            WHERE user_id = $1 OR post_id IN (SELECT id FROM posts WHERE user_id = $1)
+            UNION
+            SELECT c.id FROM comments c
+            INNER JOIN comment_tree ct ON c.parent_comment_id = ct.id
+        )
+        SELECT id FROM comment_tree
+    `, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user comments: %w", err)
+	}
+
+	err = r.db.SelectContext(ctx, &content.MediaPublicIds, `
+        SELECT m.public_id FROM media m
+        JOIN posts p ON p.id = m.post_id
+        WHERE p.user_id = $1 AND m.public_id IS NOT NULL AND m.public_id <> ''
+    `, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user media: %w", err)
+	}
+
+	return content, nil
+}
+
+// ClaimPurge menandai akun sedang di-purge sebelum data di luar Postgres dihapus. Setelah
+// diklaim, akun tidak bisa dipulihkan lewat login. false berarti penghapusan sudah
+// dibatalkan atau sedang dikerjakan worker lain.
+func (r *AccountRepository) ClaimPurge(ctx context.Context, userId string) (bool, error) {
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE users
+        SET purge_started_at = NOW()
+        WHERE user_id = $1 AND is_active = false AND deletion_scheduled_at <= NOW()
+        AND (purge_started_at IS NULL OR purge_started_at < $2)
+    `, userId, time.Now().Add(-purgeLease))
+	if err != nil {
+		return false, fmt.Errorf("failed to claim account purge: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to claim account purge: %w", err)
+	}
+	return n > 0, nil
+}
+
+// PurgeUser menghapus semua data user di Postgres dalam satu transaksi. Akun harus sudah
+// diklaim lewat ClaimPurge; klaim dicek ulang dengan lock sebelum data dihapus.
+// Counter like/comment pada konten orang lain dibetulkan oleh job reconciliation.
+func (r *AccountRepository) PurgeUser(ctx context.Context, userId string, content *UserContent) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var email string
+	err = tx.QueryRowContext(ctx, `
+        SELECT email FROM users
+        WHERE user_id = $1 AND is_active = false AND deletion_scheduled_at <= NOW()
+        AND purge_started_at IS NOT NULL
+        FOR UPDATE
+    `, userId).Scan(&email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrDeletionCancelled
+		}
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	steps := []struct {
+		name  string
+		query string
+		args  []interface{}
+	}{
+		{"comments", `DELETE FROM comments WHERE id = ANY($1)`, []interface{}{pq.Array(content.CommentIds)}},
+		{"media", `DELETE FROM media WHERE post_id IN (SELECT id FROM posts WHERE user_id = $1)`, []interface{}{userId}},
+		{"post tags", `DELETE FROM post_tags WHERE post_id IN (SELECT id FROM posts WHERE user_id = $1)`, []interface{}{userId}},
+		{"posts", `DELETE FROM posts WHERE user_id = $1`, []interface{}{userId}},
+		{"followers", `DELETE FROM followers WHERE follower_id = $1 OR following_id = $1`, []interface{}{userId}},
+		{"follow requests", `DELETE FROM follow_request WHERE follower_id = $1 OR following_id = $1`, []interface{}{userId}},
+		{"sessions", `DELETE FROM sessions WHERE user_id = $1`, []interface{}{userId}},
+		{"verification tokens", `DELETE FROM verification_tokens WHERE user_id = $1`, []interface{}{userId}},
+		{"login audit", `DELETE FROM login_audit WHERE user_id = $1 OR LOWER(identifier) = LOWER($2)`, []interface{}{userId, email}},
+		{"data exports", `DELETE FROM data_exports WHERE user_id = $1`, []interface{}{userId}},
+		// Sisa tabel (profile, identities, totp, likes postgres, dst.) ikut terhapus lewat ON DELETE CASCADE
+		{"user", `DELETE FROM users WHERE user_id = $1`, []interface{}{userId}},
+	}
+	for _, step := range steps {
+		if _, err := tx.ExecContext(ctx, step.query, step.args...); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", step.name, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ActiveExport mengembalikan export yang masih berjalan atau masih bisa diunduh
+func (r *AccountRepository) ActiveExport(ctx context.Context, userId string) (*types.DataExport, error) {
+	export, err := r.scanExport(r.db.QueryRowContext(ctx, exportSelect+`
+        WHERE user_id = $1
+        AND (status IN ($2, $3) OR (status = $4 AND expires_at > NOW()))
+        ORDER BY created_at DESC
+        LIMIT 1
+    `, userId, types.DataExportPending, types.DataExportProcessing, types.DataExportCompleted))
+	if err == ErrExportNotFound {
+		return nil, nil
+	}
+	return export, err
+}
+
+func (r *AccountRepository) CreateExport(ctx context.Context, userId string) (*types.DataExport, error) {
+	return r.scanExport(r.db.QueryRowContext(ctx, `
+        INSERT INTO data_exports (id, user_id, status)
+        VALUES ($1, $2, $3)
+        RETURNING `+exportColumns, uuid.New().String(), userId, types.DataExportPending))
+}
+
+func (r *AccountRepository) GetExport(ctx context.Context, userId, exportId string) (*types.DataExport, error) {
+	return r.scanExport(r.db.QueryRowContext(ctx, exportSelect+`
+        WHERE id = $1 AND user_id = $2
+    `, exportId, userId))
+}
+
+// ClaimPendingExport mengambil satu export pending; SKIP LOCKED agar aman untuk banyak instance
+func (r *AccountRepository) ClaimPendingExport(ctx context.Context) (*types.DataExport, error) {
+	export, err := r.scanExport(r.db.QueryRowContext(ctx, `
+        UPDATE data_exports
+        SET status = $1, updated_at = NOW()
+        WHERE id = (
+            SELECT id FROM data_exports
+            WHERE status = $2
+            ORDER BY created_at
+            LIMIT 1
+            FOR UPDATE SKIP LOCKED
+        )
+        RETURNING `+exportColumns, types.DataExportProcessing, types.DataExportPending))
+	if err == ErrExportNotFound {
+		return nil, nil
+	}
+	return export, err
+}
+
+func (r *AccountRepository) CompleteExport(ctx context.Context, exportId, filePath string, expiresAt time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE data_exports
+        SET status = $2, file_path = $3, completed_at = NOW(), expires_at = $4, updated_at = NOW()
+        WHERE id = $1
+    `, exportId, types.DataExportCompleted, filePath, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to complete export: %w", err)
+	}
+	return nil
+}
+
+func (r *AccountRepository) FailExport(ctx context.Context, exportId string, cause error) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE data_exports
+        SET status = $2, error = $3, completed_at = NOW(), updated_at = NOW()
+        WHERE id = $1
+    `, exportId, types.DataExportFailed, cause.Error())
+	if err != nil {
+		return fmt.Errorf("failed to mark export as failed: %w", err)
+	}
+	return nil
+}
+
+// ResetStaleExports mengembalikan export processing yang tertinggal (misal proses mati) ke pending
+func (r *AccountRepository) ResetStaleExports(ctx context.Context, olderThan time.Duration) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE data_exports
+        SET status = $1, updated_at = NOW()
+        WHERE status = $2 AND updated_at < $3
+    `, types.DataExportPending, types.DataExportProcessing, time.Now().Add(-olderThan))
+	if err != nil {
+		return fmt.Errorf("failed to reset stale exports: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredExports menghapus baris export kedaluwarsa dan mengembalikan file yang harus dihapus
+func (r *AccountRepository) DeleteExpiredExports(ctx context.Context) ([]string, error) {
+	var paths []string
+	err := r.db.SelectContext(ctx, &paths, `
+        DELETE FROM data_exports
+        WHERE expires_at <= NOW() OR (status = $1 AND completed_at <= NOW() - INTERVAL '7 days')
+        RETURNING COALESCE(file_path, '')
+    `, types.DataExportFailed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete expired exports: %w", err)
+	}
+	return paths, nil
+}
+
+func (r *AccountRepository) ExportFiles(ctx context.Context, userId string) ([]string, error) {
+	var paths []string
+	err := r.db.SelectContext(ctx, &paths, `
+        SELECT file_path FROM data_exports WHERE user_id = $1 AND file_path IS NOT NULL
+    `, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export files: %w", err)
+	}
+	return paths, nil
+}
+
+const exportColumns = `
+    id,
+    user_id,
+    status,
+    COALESCE(file_path, ''),
+    COALESCE(error, ''),
+    EXTRACT(EPOCH FROM created_at)::bigint,
+    COALESCE(EXTRACT(EPOCH FROM completed_at)::bigint, 0),
+    COALESCE(EXTRACT(EPOCH FROM expires_at)::bigint, 0)
+`
+
+const exportSelect = `SELECT ` + exportColumns + ` FROM data_exports`
+
+func (r *AccountRepository) scanExport(row *sql.Row) (*types.DataExport, error) {
+	export := &types.DataExport{}
+	err := row.Scan(
+		&export.Id,
+		&export.UserId,
+		&export.Status,
+		&export.FilePath,
+		&export.Error,
+		&export.CreatedAt,
+		&export.CompletedAt,
+		&export.ExpiresAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrExportNotFound
+		}
+		return nil, fmt.Errorf("failed to scan data export: %w", err)
+	}
+	return export, nil
+}
@@ -0,0 +1,11 @@
+package account
+
+import "github.com/gin-gonic/gin"
+
+func RegisterRoutes(r *gin.RouterGroup, h *AccountHandler) {
+	r.POST("/deactivate", h.HandleDeactivate)
+	r.POST("/delete", h.HandleDelete)
+	r.POST("/exports", h.HandleRequestExport)
+	r.GET("/exports/:id", h.HandleGetExport)
+	r.GET("/exports/:id/download", h.HandleDownloadExport)
+}
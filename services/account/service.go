@@ -0,0 +1,99 @@
+package account
+
+import (
+	"context"
+	"os"
+	"time"
+
+	authrepository "github.com/wafi04/chatting-app/services/auth/pkg/repository"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+type AccountService struct {
+	repo          *AccountRepository
+	authRepo      *authrepository.AuthRepository
+	worker        *Worker
+	deletionGrace time.Duration
+}
+
+func NewAccountService(repo *AccountRepository, authRepo *authrepository.AuthRepository, worker *Worker, config *Config) *AccountService {
+	return &AccountService{
+		repo:          repo,
+		authRepo:      authRepo,
+		worker:        worker,
+		deletionGrace: config.DeletionGrace,
+	}
+}
+
+// Deactivate menyembunyikan profil dan konten user sampai user login lagi
+func (s *AccountService) Deactivate(ctx context.Context, userId, password string) (*types.DeactivateAccountResponse, error) {
+	if err := s.authRepo.CheckPassword(ctx, userId, password); err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.repo.Deactivate(ctx, userId, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.DeactivateAccountResponse{
+		Success:         true,
+		RevokedSessions: revoked,
+	}, nil
+}
+
+// RequestDeletion menonaktifkan akun dan menjadwalkan penghapusan permanen setelah masa tenggang
+func (s *AccountService) RequestDeletion(ctx context.Context, userId, password string) (*types.DeactivateAccountResponse, error) {
+	if err := s.authRepo.CheckPassword(ctx, userId, password); err != nil {
+		return nil, err
+	}
+
+	deleteAt := time.Now().Add(s.deletionGrace)
+	revoked, err := s.repo.Deactivate(ctx, userId, &deleteAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.DeactivateAccountResponse{
+		Success:             true,
+		DeletionScheduledAt: deleteAt.Unix(),
+		RevokedSessions:     revoked,
+	}, nil
+}
+
+// RequestExport membuat job export baru, atau mengembalikan job yang masih aktif
+func (s *AccountService) RequestExport(ctx context.Context, userId string) (*types.DataExport, error) {
+	export, err := s.repo.ActiveExport(ctx, userId)
+	if err != nil || export != nil {
+		return export, err
+	}
+
+	export, err = s.repo.CreateExport(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	s.worker.Notify()
+	return export, nil
+}
+
+func (s *AccountService) GetExport(ctx context.Context, userId, exportId string) (*types.DataExport, error) {
+	return s.repo.GetExport(ctx, userId, exportId)
+}
+
+// ExportFile mengembalikan path arsip yang siap diunduh
+func (s *AccountService) ExportFile(ctx context.Context, userId, exportId string) (string, error) {
+	export, err := s.repo.GetExport(ctx, userId, exportId)
+	if err != nil {
+		return "", err
+	}
+	if export.Status != types.DataExportCompleted {
+		return "", ErrExportNotReady
+	}
+	if export.ExpiresAt <= time.Now().Unix() {
+		return "", ErrExportNotFound
+	}
+	if _, err := os.Stat(export.FilePath); err != nil {
+		return "", ErrExportNotFound
+	}
+	return export.FilePath, nil
+}
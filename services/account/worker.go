@@ -0,0 +1,203 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/wafi04/chatting-app/services/likes"
+	"github.com/wafi04/chatting-app/services/reactions"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+)
+
+// MediaStore menghapus file media di storage (Cloudinary)
+type MediaStore interface {
+	DeleteFile(ctx context.Context, publicID string) error
+}
+
+const purgeBatchSize = 20
+
+// Worker memproses export data dan menghapus permanen akun yang masa tenggangnya habis
+type Worker struct {
+	db        *sqlx.DB
+	repo      *AccountRepository
+	likes     likes.Repository
+	reactions reactions.Repository
+	media     MediaStore
+	exportDir string
+	exportTTL time.Duration
+	notify    chan struct{}
+	log       *logger.Logger
+}
+
+// NewWorker membuat worker; reactionRepo boleh nil jika reactions dinonaktifkan
+func NewWorker(db *sqlx.DB, repo *AccountRepository, likeRepo likes.Repository, reactionRepo reactions.Repository, media MediaStore, config *Config) *Worker {
+	return &Worker{
+		db:        db,
+		repo:      repo,
+		likes:     likeRepo,
+		reactions: reactionRepo,
+		media:     media,
+		exportDir: config.ExportDir,
+		exportTTL: config.ExportTTL,
+		notify:    make(chan struct{}, 1),
+		log:       logger.NewLogger(),
+	}
+}
+
+// Notify membangunkan worker tanpa menunggu interval, misal setelah export baru diminta
+func (w *Worker) Notify() {
+	select {
+	case w.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Start menjalankan worker setiap interval sampai ctx dibatalkan
+func (w *Worker) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			w.RunOnce(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			case <-w.notify:
+			}
+		}
+	}()
+}
+
+func (w *Worker) RunOnce(ctx context.Context) {
+	if err := w.repo.ResetStaleExports(ctx, time.Hour); err != nil {
+		w.log.Log(logger.ErrorLevel, "%v", err)
+	}
+	for {
+		processed, err := w.processNextExport(ctx)
+		if err != nil {
+			w.log.Log(logger.ErrorLevel, "Data export failed: %v", err)
+		}
+		if !processed {
+			break
+		}
+	}
+
+	if err := w.purgeDueAccounts(ctx); err != nil {
+		w.log.Log(logger.ErrorLevel, "Account purge failed: %v", err)
+	}
+	w.cleanupExports(ctx)
+}
+
+func (w *Worker) processNextExport(ctx context.Context) (bool, error) {
+	export, err := w.repo.ClaimPendingExport(ctx)
+	if err != nil || export == nil {
+		return false, err
+	}
+
+	path, err := w.buildExport(ctx, export)
+	if err != nil {
+		if failErr := w.repo.FailExport(ctx, export.Id, err); failErr != nil {
+			w.log.Log(logger.ErrorLevel, "%v", failErr)
+		}
+		return true, err
+	}
+
+	if err := w.repo.CompleteExport(ctx, export.Id, path, time.Now().Add(w.exportTTL)); err != nil {
+		os.Remove(path)
+		return true, err
+	}
+	w.log.Log(logger.InfoLevel, "Data export %s completed", export.Id)
+	return true, nil
+}
+
+func (w *Worker) purgeDueAccounts(ctx context.Context) error {
+	userIds, err := w.repo.DueDeletions(ctx, purgeBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for _, userId := range userIds {
+		if err := w.PurgeAccount(ctx, userId); err != nil {
+			// Dicoba lagi di putaran berikutnya
+			w.log.Log(logger.ErrorLevel, "Failed to purge account %s: %v", userId, err)
+			continue
+		}
+		w.log.Log(logger.InfoLevel, "Account %s purged", userId)
+	}
+	return nil
+}
+
+// PurgeAccount mengklaim akun dulu (setelah itu login tidak bisa lagi membatalkan
+// penghapusan), lalu menghapus media di storage dan like/reaction di Mongo, baru kemudian
+// data Postgres. Jika langkah eksternal gagal, klaim dilepas setelah lease habis dan
+// purge diulang; semua langkah aman diulang.
+func (w *Worker) PurgeAccount(ctx context.Context, userId string) error {
+	claimed, err := w.repo.ClaimPurge(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		w.log.Log(logger.InfoLevel, "Deletion of %s was cancelled or is already in progress", userId)
+		return nil
+	}
+
+	content, err := w.repo.GetUserContent(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	for _, publicId := range content.MediaPublicIds {
+		if err := w.media.DeleteFile(ctx, publicId); err != nil {
+			return err
+		}
+	}
+
+	if _, err := w.likes.DeleteUserLikes(ctx, userId, content.PostIds, content.CommentIds); err != nil {
+		return err
+	}
+	if w.reactions != nil {
+		targets := append(append([]string{}, content.PostIds...), content.CommentIds...)
+		if _, err := w.reactions.DeleteUserReactions(ctx, userId, targets); err != nil {
+			return err
+		}
+	}
+
+	// File export lama milik user ikut dihapus, barisnya dihapus oleh PurgeUser
+	paths, err := w.repo.ExportFiles(ctx, userId)
+	if err != nil {
+		return err
+	}
+	w.removeFiles(paths)
+
+	err = w.repo.PurgeUser(ctx, userId, content)
+	if errors.Is(err, ErrDeletionCancelled) {
+		w.log.Log(logger.InfoLevel, "Deletion of %s was cancelled before purge", userId)
+		return nil
+	}
+	return err
+}
+
+func (w *Worker) cleanupExports(ctx context.Context) {
+	paths, err := w.repo.DeleteExpiredExports(ctx)
+	if err != nil {
+		w.log.Log(logger.ErrorLevel, "%v", err)
+		return
+	}
+	w.removeFiles(paths)
+}
+
+func (w *Worker) removeFiles(paths []string) {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			w.log.Log(logger.WarnLevel, "Failed to remove export file %s: %v", path, err)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package authhandler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	authrepository "github.com/wafi04/chatting-app/services/auth/pkg/repository"
+	authservice "github.com/wafi04/chatting-app/services/auth/pkg/service"
+	"github.com/wafi04/chatting-app/services/shared/middleware"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+	"github.com/wafi04/chatting-app/services/shared/pkg/response"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+// RegisterAdminRoutes dipasang di group yang sudah dilindungi RequireRole(admin)
+func RegisterAdminRoutes(r *gin.RouterGroup, h *AuthHandler) {
+	r.GET("", h.HandleListUsers)
+	r.GET("/:id", h.HandleAdminGetUser)
+	r.PUT("/:id/role", h.HandleUpdateUserRole)
+	r.DELETE("/:id/sessions", h.HandleRevokeUserSessions)
+}
+
+func (h *AuthHandler) HandleListUsers(c *gin.Context) {
+	var req types.ListUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid query", err.Error())
+		return
+	}
+
+	resp, err := h.authservice.ListUsers(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, authrepository.ErrInvalidRole) {
+			response.SendErrorResponse(c, http.StatusBadRequest, "Invalid role")
+			return
+		}
+		h.logger.Log(logger.ErrorLevel, "Failed to list users: %v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to list users")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Users retrieved successfully", resp)
+}
+
+func (h *AuthHandler) HandleAdminGetUser(c *gin.Context) {
+	user, err := h.authservice.GetUser(c.Request.Context(), &types.GetUserRequest{
+		UserId: c.Param("id"),
+	})
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusNotFound, "User not found")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "User retrieved successfully", user)
+}
+
+func (h *AuthHandler) HandleUpdateUserRole(c *gin.Context) {
+	admin, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req types.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+
+	err = h.authservice.UpdateUserRole(c.Request.Context(), admin.UserId, c.Param("id"), req.Role)
+	if err != nil {
+		switch {
+		case errors.Is(err, authrepository.ErrInvalidRole):
+			response.SendErrorResponse(c, http.StatusBadRequest, "Invalid role")
+		case errors.Is(err, authrepository.ErrUserNotFound):
+			response.SendErrorResponse(c, http.StatusNotFound, "User not found")
+		case errors.Is(err, authservice.ErrChangeOwnRole), errors.Is(err, authrepository.ErrLastAdmin):
+			response.SendErrorResponse(c, http.StatusConflict, err.Error())
+		default:
+			h.logger.Log(logger.ErrorLevel, "Failed to update role: %v", err)
+			response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to update role")
+		}
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Role updated successfully", gin.H{
+		"user_id": c.Param("id"),
+		"role":    req.Role,
+	})
+}
+
+func (h *AuthHandler) HandleRevokeUserSessions(c *gin.Context) {
+	resp, err := h.authservice.RevokeUserSessions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to revoke sessions")
+		return
+	}
+
+	h.sessions.Invalidate(resp.Revoked...)
+	response.SendSuccessResponse(c, http.StatusOK, "User signed out of all devices", resp)
+}
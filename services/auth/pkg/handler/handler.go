@@ -1,10 +1,14 @@
 package authhandler
 
 import (
+	"errors"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
+	authrepository "github.com/wafi04/chatting-app/services/auth/pkg/repository"
 	authservice "github.com/wafi04/chatting-app/services/auth/pkg/service"
 	"github.com/wafi04/chatting-app/services/shared/middleware"
 	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
@@ -14,12 +18,14 @@ import (
 
 type AuthHandler struct {
 	authservice *authservice.AuthService
+	sessions    *middleware.SessionCache
 	logger      logger.Logger
 }
 
-func NewGateway(authservice *authservice.AuthService) *AuthHandler {
+func NewGateway(authservice *authservice.AuthService, sessions *middleware.SessionCache) *AuthHandler {
 	return &AuthHandler{
 		authservice: authservice,
+		sessions:    sessions,
 	}
 }
 
@@ -59,8 +65,10 @@ func (h *AuthHandler) HandleCreateUser(c *gin.Context) {
 }
 
 type Login struct {
-	Name     string `json:"name"`
-	Password string `json:"password"`
+	// Identifier berisi email atau username; name tetap diterima untuk client lama
+	Identifier string `json:"identifier"`
+	Name       string `json:"name"`
+	Password   string `json:"password"`
 }
 
 func (h *AuthHandler) HandleLogin(c *gin.Context) {
@@ -71,7 +79,10 @@ func (h *AuthHandler) HandleLogin(c *gin.Context) {
 		return
 	}
 
-	if req.Name == "" || req.Password == "" {
+	if req.Identifier == "" {
+		req.Identifier = req.Name
+	}
+	if req.Identifier == "" || req.Password == "" {
 		response.SendErrorResponse(c, http.StatusBadRequest, "Credentials failed")
 		return
 	}
@@ -80,29 +91,42 @@ func (h *AuthHandler) HandleLogin(c *gin.Context) {
 	userAgent := c.Request.UserAgent()
 
 	resp, err := h.authservice.Login(c.Request.Context(), &types.LoginRequest{
-		Name:       req.Name,
+		Identifier: req.Identifier,
 		Password:   req.Password,
 		DeviceInfo: userAgent,
 		IpAddress:  clientIP,
 	})
 
 	if err != nil {
-		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "user not found : %s", err.Error())
+		var locked *authrepository.LoginLockedError
+		switch {
+		case errors.As(err, &locked):
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+			response.SendErrorResponse(c, http.StatusTooManyRequests, locked.Error())
+		case errors.Is(err, authrepository.ErrInvalidCredentials):
+			response.SendErrorResponse(c, http.StatusUnauthorized, "Invalid credentials")
+		default:
+			response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to login")
+		}
+		return
+	}
+	if resp.TwoFactorRequired {
+		response.SendSuccessResponse(c, http.StatusOK, "Two-factor authentication required", resp)
 		return
 	}
 	c.Header("Access-Control-Allow-Credentials", "true")
-	middleware.SetRefreshTokenCookie(c, resp.RefreshToken)
-	middleware.SetAccressTokenCookie(c, resp.AccessToken)
-	middleware.SetSessionCookie(c, resp.SessionInfo.SessionId)
+	if err := middleware.SetAuthCookies(c, resp.AccessToken, resp.RefreshToken, resp.SessionInfo.SessionId); err != nil {
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to set auth cookies")
+		return
+	}
 	response.SendSuccessResponse(c, http.StatusOK, "Login user successfully", resp)
 }
 
 func (h *AuthHandler) HandleGetProfile(c *gin.Context) {
-	h.logger.Log((logger.InfoLevel), "called profile")
-	sessionID, err := c.Cookie("auth_session")
-
-	if err != nil {
+	sessionID := middleware.GetSessionIDFromGinContext(c)
+	if sessionID == "" {
 		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
 	}
 
 	users, err := h.authservice.GetSession(c, &types.GetSessionRequest{
@@ -122,14 +146,12 @@ func (h *AuthHandler) HandleLogout(c *gin.Context) {
 		response.Error(http.StatusUnauthorized, "Unauthorized")
 		return
 	}
-	token, err := c.Cookie("access-token")
-	if err != nil {
-		response.Error(http.StatusUnauthorized, "Unauthorized")
-		return
-	}
+	token := middleware.GetAccessTokenFromGinContext(c)
 
+	sessionID := middleware.GetSessionIDFromGinContext(c)
 	logout, err := h.authservice.Logout(c, &types.LogoutRequest{
 		AccessToken: token,
+		SessionId:   sessionID,
 		UserId:      user.UserId,
 	})
 
@@ -137,6 +159,205 @@ func (h *AuthHandler) HandleLogout(c *gin.Context) {
 		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
 		return
 	}
+	h.sessions.Invalidate(sessionID)
 
+	middleware.ClearTokens(c)
 	response.SendSuccessResponse(c, http.StatusOK, "Logout Successfully", logout)
 }
+
+func (h *AuthHandler) HandleVerifyEmail(c *gin.Context) {
+	var req types.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.VerificationToken == "" || req.VerifyCode == "" {
+		response.SendErrorResponse(c, http.StatusBadRequest, "verification_token and verify_code are required")
+		return
+	}
+
+	resp, err := h.authservice.VerifyEmail(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, authrepository.ErrInvalidVerificationCode) {
+			response.SendErrorResponse(c, http.StatusBadRequest, "Invalid or expired verification code")
+			return
+		}
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to verify email")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Email verified successfully", resp)
+}
+
+func (h *AuthHandler) HandleResendVerification(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	resp, err := h.authservice.ResendVerification(c.Request.Context(), &types.ResendVerificationRequest{
+		UserId: user.UserId,
+	})
+	if err != nil {
+		var throttled *authrepository.ResendThrottledError
+		switch {
+		case errors.As(err, &throttled):
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			response.SendErrorResponse(c, http.StatusTooManyRequests, throttled.Error())
+		case errors.Is(err, authrepository.ErrEmailAlreadyVerified):
+			response.SendErrorResponse(c, http.StatusConflict, "Email already verified")
+		default:
+			response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to resend verification")
+		}
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Verification email sent", resp)
+}
+
+func (h *AuthHandler) HandleForgotPassword(c *gin.Context) {
+	var req types.RequestPasswordResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Email == "" {
+		response.SendErrorResponse(c, http.StatusBadRequest, "email is required")
+		return
+	}
+
+	if _, err := h.authservice.RequestPasswordReset(c.Request.Context(), &req); err != nil {
+		var throttled *authrepository.ResendThrottledError
+		if errors.As(err, &throttled) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(throttled.RetryAfter.Seconds()))))
+			response.SendErrorResponse(c, http.StatusTooManyRequests, throttled.Error())
+			return
+		}
+		h.logger.Log(logger.ErrorLevel, "Failed to request password reset: %v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to request password reset")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "If an account exists for that email, a reset link has been sent", nil)
+}
+
+func (h *AuthHandler) HandleResetPassword(c *gin.Context) {
+	var req types.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.ResetToken == "" || req.NewPassword == "" {
+		response.SendErrorResponse(c, http.StatusBadRequest, "reset_token and new_password are required")
+		return
+	}
+
+	resp, err := h.authservice.ResetPassword(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, authrepository.ErrInvalidResetToken):
+			response.SendErrorResponse(c, http.StatusBadRequest, "Invalid or expired reset token")
+		case errors.Is(err, authservice.ErrWeakPassword):
+			response.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+		default:
+			response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to reset password")
+		}
+		return
+	}
+
+	h.sessions.Invalidate(resp.RevokedSessions...)
+	middleware.ClearTokens(c)
+	response.SendSuccessResponse(c, http.StatusOK, "Password reset successfully", resp)
+}
+
+func (h *AuthHandler) HandleRefreshToken(c *gin.Context) {
+	var req types.RefreshTokenRequest
+	// Body opsional, refresh token bisa juga diambil dari cookie
+	_ = c.ShouldBindJSON(&req)
+	if req.RefreshToken == "" {
+		req.RefreshToken, _ = c.Cookie(middleware.RefreshTokenCookie)
+	}
+	if req.RefreshToken == "" {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Refresh token is required")
+		return
+	}
+
+	resp, err := h.authservice.RefreshToken(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, authrepository.ErrRefreshTokenReused):
+			middleware.ClearTokens(c)
+			response.SendErrorResponse(c, http.StatusUnauthorized, "Refresh token reuse detected, session revoked")
+		case errors.Is(err, authrepository.ErrInvalidRefreshToken), errors.Is(err, authrepository.ErrSessionExpired):
+			middleware.ClearTokens(c)
+			response.SendErrorResponse(c, http.StatusUnauthorized, err.Error())
+		default:
+			response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to refresh token")
+		}
+		return
+	}
+
+	if err := middleware.SetAuthCookies(c, resp.AccessToken, resp.RefreshToken, resp.SessionId); err != nil {
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to set auth cookies")
+		return
+	}
+	response.SendSuccessResponse(c, http.StatusOK, "Token refreshed successfully", resp)
+}
+
+func (h *AuthHandler) HandleListSessions(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	resp, err := h.authservice.ListSessions(c.Request.Context(), &types.ListSessionsRequest{
+		UserId: user.UserId,
+	})
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to list sessions")
+		return
+	}
+
+	current := middleware.GetSessionIDFromGinContext(c)
+	for _, session := range resp.Sessions {
+		session.Current = session.SessionId == current
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Sessions retrieved successfully", resp)
+}
+
+func (h *AuthHandler) HandleRevokeSession(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	sessionID := c.Param("id")
+	resp, err := h.authservice.RevokeSession(c.Request.Context(), &types.RevokeSessionRequest{
+		UserId:    user.UserId,
+		SessionId: sessionID,
+	})
+	if err != nil {
+		if errors.Is(err, authrepository.ErrSessionNotFound) {
+			response.SendErrorResponse(c, http.StatusNotFound, "Session not found")
+			return
+		}
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to revoke session")
+		return
+	}
+
+	h.sessions.Invalidate(sessionID)
+	if sessionID == middleware.GetSessionIDFromGinContext(c) {
+		middleware.ClearTokens(c)
+	}
+	response.SendSuccessResponse(c, http.StatusOK, "Session revoked successfully", resp)
+}
+
+// HandleRevokeOtherSessions: "sign out of all other devices"
+func (h *AuthHandler) HandleRevokeOtherSessions(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	resp, err := h.authservice.RevokeOtherSessions(c.Request.Context(), user.UserId, middleware.GetSessionIDFromGinContext(c))
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to revoke sessions")
+		return
+	}
+
+	h.sessions.Invalidate(resp.Revoked...)
+	response.SendSuccessResponse(c, http.StatusOK, "Signed out of all other devices", resp)
+}
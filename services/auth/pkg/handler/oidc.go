@@ -0,0 +1,108 @@
+package authhandler
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/chatting-app/services/auth/pkg/oidc"
+	authrepository "github.com/wafi04/chatting-app/services/auth/pkg/repository"
+	authservice "github.com/wafi04/chatting-app/services/auth/pkg/service"
+	"github.com/wafi04/chatting-app/services/shared/middleware"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+	"github.com/wafi04/chatting-app/services/shared/pkg/response"
+	"github.com/wafi04/chatting-app/services/shared/types"
+	"github.com/wafi04/chatting-app/services/shared/utils"
+)
+
+// Sama dengan masa berlaku state di database
+const oidcStateCookieTTL = 10 * time.Minute
+
+func (h *AuthHandler) HandleOIDCLogin(c *gin.Context) {
+	authURL, state, err := h.authservice.BeginOIDCLogin(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		if errors.Is(err, authservice.ErrUnknownOIDCProvider) {
+			response.SendErrorResponse(c, http.StatusNotFound, "Unknown identity provider")
+			return
+		}
+		h.logger.Log(logger.ErrorLevel, "Failed to begin oidc login: %v", err)
+		response.SendErrorResponse(c, http.StatusBadGateway, "Failed to start login with identity provider")
+		return
+	}
+
+	middleware.SetOIDCStateCookie(c, utils.HashToken(state), int(oidcStateCookieTTL.Seconds()))
+	c.Redirect(http.StatusFound, authURL)
+}
+
+func (h *AuthHandler) HandleOIDCCallback(c *gin.Context) {
+	if providerErr := c.Query("error"); providerErr != "" {
+		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Identity provider returned an error", providerErr)
+		return
+	}
+
+	code, state := c.Query("code"), c.Query("state")
+	if code == "" || state == "" {
+		response.SendErrorResponse(c, http.StatusBadRequest, "code and state are required")
+		return
+	}
+
+	// State harus berasal dari browser ini; mencegah login CSRF dengan URL callback milik orang lain
+	cookieState, _ := c.Cookie(middleware.OIDCStateCookie)
+	middleware.SetOIDCStateCookie(c, "", -1)
+	if cookieState == "" || subtle.ConstantTimeCompare([]byte(cookieState), []byte(utils.HashToken(state))) != 1 {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Login with identity provider failed")
+		return
+	}
+
+	resp, err := h.authservice.CompleteOIDCLogin(c.Request.Context(), c.Param("provider"), code, state, &types.LoginRequest{
+		DeviceInfo: c.Request.UserAgent(),
+		IpAddress:  c.ClientIP(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, authservice.ErrUnknownOIDCProvider):
+			response.SendErrorResponse(c, http.StatusNotFound, "Unknown identity provider")
+		case errors.Is(err, authrepository.ErrInvalidOIDCState),
+			errors.Is(err, oidc.ErrInvalidIDToken),
+			errors.Is(err, authrepository.ErrInvalidCredentials):
+			response.SendErrorResponse(c, http.StatusUnauthorized, "Login with identity provider failed")
+		case errors.Is(err, authrepository.ErrUnverifiedLocalAccount),
+			errors.Is(err, authrepository.ErrUnverifiedExternalEmail):
+			response.SendErrorResponse(c, http.StatusConflict, err.Error())
+		default:
+			h.logger.Log(logger.ErrorLevel, "Failed to complete oidc login: %v", err)
+			response.SendErrorResponse(c, http.StatusBadGateway, "Login with identity provider failed")
+		}
+		return
+	}
+
+	if !resp.TwoFactorRequired {
+		if err := middleware.SetAuthCookies(c, resp.AccessToken, resp.RefreshToken, resp.SessionInfo.SessionId); err != nil {
+			response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to set auth cookies")
+			return
+		}
+	}
+
+	// Flow browser: kembali ke frontend. Challenge 2FA dikirim lewat query agar frontend bisa lanjut ke /auth/2fa/login
+	if redirect := h.authservice.OIDCLoginRedirect(); redirect != "" {
+		target, err := url.Parse(redirect)
+		if err == nil {
+			if resp.TwoFactorRequired {
+				q := target.Query()
+				q.Set("challenge_token", resp.ChallengeToken)
+				target.RawQuery = q.Encode()
+			}
+			c.Redirect(http.StatusFound, target.String())
+			return
+		}
+	}
+
+	if resp.TwoFactorRequired {
+		response.SendSuccessResponse(c, http.StatusOK, "Two-factor authentication required", resp)
+		return
+	}
+	response.SendSuccessResponse(c, http.StatusOK, "Login user successfully", resp)
+}
@@ -0,0 +1,80 @@
+package authhandler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	authrepository "github.com/wafi04/chatting-app/services/auth/pkg/repository"
+	authservice "github.com/wafi04/chatting-app/services/auth/pkg/service"
+	"github.com/wafi04/chatting-app/services/shared/middleware"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+	"github.com/wafi04/chatting-app/services/shared/pkg/response"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+func (h *AuthHandler) HandleCreateAccessToken(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req types.CreateAccessTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Invalid request body", err.Error())
+		return
+	}
+	req.UserId = user.UserId
+
+	resp, err := h.authservice.CreateAccessToken(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, authservice.ErrInvalidAccessTokenRequest):
+			response.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+		case errors.Is(err, authrepository.ErrTooManyAccessTokens):
+			response.SendErrorResponse(c, http.StatusConflict, err.Error())
+		default:
+			h.logger.Log(logger.ErrorLevel, "Failed to create access token: %v", err)
+			response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to create access token")
+		}
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusCreated, "Access token created, copy it now because it will not be shown again", resp)
+}
+
+func (h *AuthHandler) HandleListAccessTokens(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	tokens, err := h.authservice.ListAccessTokens(c.Request.Context(), user.UserId)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to list access tokens")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Access tokens retrieved successfully", tokens)
+}
+
+func (h *AuthHandler) HandleRevokeAccessToken(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.authservice.RevokeAccessToken(c.Request.Context(), user.UserId, c.Param("id")); err != nil {
+		if errors.Is(err, authrepository.ErrAccessTokenNotFound) {
+			response.SendErrorResponse(c, http.StatusNotFound, "Access token not found")
+			return
+		}
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to revoke access token")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Access token revoked", nil)
+}
@@ -8,12 +8,33 @@ import (
 func RegisterRoutes(r *gin.RouterGroup, h *AuthHandler) {
 	r.POST("/register", h.HandleCreateUser)
 	r.POST("/login", h.HandleLogin)
+	r.POST("/refresh", h.HandleRefreshToken)
+	r.POST("/2fa/login", h.HandleTwoFactorLogin)
+	r.GET("/oidc/:provider/login", h.HandleOIDCLogin)
+	r.GET("/oidc/:provider/callback", h.HandleOIDCCallback)
+	r.POST("/verify-email", h.HandleVerifyEmail)
+	r.POST("/forgot-password", h.HandleForgotPassword)
+	r.POST("/reset-password", h.HandleResetPassword)
 
 	authenticated := r.Group("")
-	authenticated.Use(middleware.AuthMiddleware())
+	authenticated.Use(middleware.AuthMiddleware(h.sessions))
 	{
 		authenticated.GET("/profile", h.HandleGetProfile)
 		authenticated.POST("/logout", h.HandleLogout)
+		authenticated.POST("/resend-verification", h.HandleResendVerification)
+		authenticated.GET("/sessions", h.HandleListSessions)
+		authenticated.DELETE("/sessions", h.HandleRevokeOtherSessions)
+		authenticated.DELETE("/sessions/:id", h.HandleRevokeSession)
+		authenticated.GET("/security/events", h.HandleListSecurityEvents)
+		authenticated.GET("/2fa", h.HandleTwoFactorStatus)
+		authenticated.POST("/2fa/enroll", h.HandleEnrollTwoFactor)
+		authenticated.POST("/2fa/confirm", h.HandleConfirmTwoFactor)
+		authenticated.POST("/2fa/disable", h.HandleDisableTwoFactor)
+		authenticated.POST("/2fa/recovery-codes", h.HandleRegenerateRecoveryCodes)
+		// Token hanya bisa dikelola dari sesi login, bukan dengan PAT lain
+		authenticated.GET("/tokens", h.HandleListAccessTokens)
+		authenticated.POST("/tokens", h.HandleCreateAccessToken)
+		authenticated.DELETE("/tokens/:id", h.HandleRevokeAccessToken)
 	}
 
 }
@@ -0,0 +1,35 @@
+package authhandler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/chatting-app/services/shared/middleware"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+	"github.com/wafi04/chatting-app/services/shared/pkg/response"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+func (h *AuthHandler) HandleListSecurityEvents(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req types.ListSecurityEventsRequest
+	if err := c.ShouldBindQuery(&req); err != nil || req.Limit < 0 || req.Before < 0 {
+		response.SendErrorResponse(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+	req.UserId = user.UserId
+
+	resp, err := h.authservice.ListSecurityEvents(c.Request.Context(), &req)
+	if err != nil {
+		h.logger.Log(logger.ErrorLevel, "Failed to list security events: %v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to list security events")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Security events retrieved successfully", resp)
+}
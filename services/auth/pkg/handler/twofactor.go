@@ -0,0 +1,156 @@
+package authhandler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	authrepository "github.com/wafi04/chatting-app/services/auth/pkg/repository"
+	"github.com/wafi04/chatting-app/services/shared/middleware"
+	"github.com/wafi04/chatting-app/services/shared/pkg/response"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+// sendTwoFactorError memetakan error 2FA ke status HTTP
+func sendTwoFactorError(c *gin.Context, err error, fallback string) {
+	var locked *authrepository.LoginLockedError
+	switch {
+	case errors.As(err, &locked):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+		response.SendErrorResponse(c, http.StatusTooManyRequests, locked.Error())
+	case errors.Is(err, authrepository.ErrInvalidTwoFactorCode),
+		errors.Is(err, authrepository.ErrInvalidCredentials),
+		errors.Is(err, authrepository.ErrInvalidChallenge):
+		response.SendErrorResponse(c, http.StatusUnauthorized, err.Error())
+	case errors.Is(err, authrepository.ErrTwoFactorNotEnrolled),
+		errors.Is(err, authrepository.ErrTwoFactorEnabled):
+		response.SendErrorResponse(c, http.StatusConflict, err.Error())
+	default:
+		response.SendErrorResponse(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+func (h *AuthHandler) HandleTwoFactorStatus(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	status, err := h.authservice.TwoFactorStatus(c.Request.Context(), user.UserId)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get two-factor status")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Two-factor status retrieved successfully", status)
+}
+
+func (h *AuthHandler) HandleEnrollTwoFactor(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	resp, err := h.authservice.EnrollTwoFactor(c.Request.Context(), user.UserId)
+	if err != nil {
+		sendTwoFactorError(c, err, "Failed to enroll two-factor authentication")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Scan the provisioning URI with your authenticator app", resp)
+}
+
+func (h *AuthHandler) HandleConfirmTwoFactor(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req types.TwoFactorConfirmRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Code == "" {
+		response.SendErrorResponse(c, http.StatusBadRequest, "code is required")
+		return
+	}
+	req.UserId = user.UserId
+
+	resp, err := h.authservice.ConfirmTwoFactor(c.Request.Context(), &req)
+	if err != nil {
+		sendTwoFactorError(c, err, "Failed to enable two-factor authentication")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Two-factor authentication enabled", resp)
+}
+
+func (h *AuthHandler) HandleDisableTwoFactor(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req types.TwoFactorDisableRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Password == "" || req.Code == "" {
+		response.SendErrorResponse(c, http.StatusBadRequest, "password and code are required")
+		return
+	}
+	req.UserId = user.UserId
+
+	if err := h.authservice.DisableTwoFactor(c.Request.Context(), &req); err != nil {
+		sendTwoFactorError(c, err, "Failed to disable two-factor authentication")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+func (h *AuthHandler) HandleRegenerateRecoveryCodes(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req types.TwoFactorRegenerateRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Password == "" || req.Code == "" {
+		response.SendErrorResponse(c, http.StatusBadRequest, "password and code are required")
+		return
+	}
+	req.UserId = user.UserId
+
+	resp, err := h.authservice.RegenerateRecoveryCodes(c.Request.Context(), &req)
+	if err != nil {
+		sendTwoFactorError(c, err, "Failed to regenerate recovery codes")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Recovery codes regenerated", resp)
+}
+
+func (h *AuthHandler) HandleTwoFactorLogin(c *gin.Context) {
+	var req types.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.ChallengeToken == "" || req.Code == "" {
+		response.SendErrorResponse(c, http.StatusBadRequest, "challenge_token and code are required")
+		return
+	}
+	req.IpAddress = c.ClientIP()
+	req.DeviceInfo = c.Request.UserAgent()
+
+	resp, err := h.authservice.CompleteTwoFactorLogin(c.Request.Context(), &req)
+	if err != nil {
+		sendTwoFactorError(c, err, "Failed to login")
+		return
+	}
+
+	c.Header("Access-Control-Allow-Credentials", "true")
+	if err := middleware.SetAuthCookies(c, resp.AccessToken, resp.RefreshToken, resp.SessionInfo.SessionId); err != nil {
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to set auth cookies")
+		return
+	}
+	response.SendSuccessResponse(c, http.StatusOK, "Login user successfully", resp)
+}
@@ -0,0 +1,54 @@
+package oidc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wafi04/chatting-app/config/env"
+)
+
+type ProviderConfig struct {
+	// Name dipakai di URL: /auth/oidc/:provider/login
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+// LoadProvidersFromEnv membaca provider dari env:
+//
+//	OIDC_PROVIDERS=google,keycloak
+//	OIDC_GOOGLE_ISSUER, OIDC_GOOGLE_CLIENT_ID, OIDC_GOOGLE_CLIENT_SECRET,
+//	OIDC_GOOGLE_REDIRECT_URL, OIDC_GOOGLE_SCOPES (opsional, dipisah spasi)
+func LoadProvidersFromEnv() ([]ProviderConfig, error) {
+	names := env.LoadEnv("OIDC_PROVIDERS")
+	if names == "" {
+		return nil, nil
+	}
+
+	var configs []ProviderConfig
+	for _, name := range strings.Split(names, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		config := ProviderConfig{
+			Name:         name,
+			Issuer:       env.LoadEnv(prefix + "ISSUER"),
+			ClientID:     env.LoadEnv(prefix + "CLIENT_ID"),
+			ClientSecret: env.LoadEnv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  env.LoadEnv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(env.LoadEnv(prefix + "SCOPES")),
+		}
+		if config.Issuer == "" || config.ClientID == "" || config.RedirectURL == "" {
+			return nil, fmt.Errorf("oidc provider %s: ISSUER, CLIENT_ID and REDIRECT_URL are required", name)
+		}
+		configs = append(configs, config)
+	}
+
+	return configs, nil
+}
@@ -0,0 +1,19 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/wafi04/chatting-app/services/shared/utils"
+)
+
+// NewCodeVerifier membuat PKCE code_verifier (RFC 7636), 64 karakter hex
+func NewCodeVerifier() (string, error) {
+	return utils.GenerateSecureToken(32)
+}
+
+// CodeChallengeS256 menghitung code_challenge dari verifier dengan metode S256
+func CodeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,95 @@
+package oidc_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/chatting-app/services/auth/pkg/oidc"
+	"github.com/wafi04/chatting-app/services/auth/pkg/oidc/oidctest"
+)
+
+// authorize menjalankan redirect ke provider palsu dan mengembalikan code dari callback
+func authorize(t *testing.T, client *http.Client, authURL string) (code, state string) {
+	noRedirect := *client
+	noRedirect.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, err := noRedirect.Get(authURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	require.NoError(t, err)
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func TestProviderAuthorizationCodeFlow(t *testing.T) {
+	fake := oidctest.NewProvider("client-1", oidctest.User{
+		Subject:       "sub-1",
+		Email:         "alice@example.com",
+		EmailVerified: true,
+		Name:          "Alice",
+	})
+	defer fake.Close()
+
+	provider := oidc.NewProvider(oidc.ProviderConfig{
+		Name:        "fake",
+		Issuer:      fake.Issuer(),
+		ClientID:    "client-1",
+		RedirectURL: "http://localhost/callback",
+	}, fake.Client())
+
+	ctx := context.Background()
+	verifier, err := oidc.NewCodeVerifier()
+	require.NoError(t, err)
+
+	authURL, err := provider.AuthCodeURL(ctx, "state-1", "nonce-1", verifier)
+	require.NoError(t, err)
+
+	code, state := authorize(t, fake.Client(), authURL)
+	assert.Equal(t, "state-1", state)
+
+	claims, err := provider.Exchange(ctx, code, verifier, "nonce-1")
+	require.NoError(t, err)
+	assert.Equal(t, "sub-1", claims.Subject)
+	assert.Equal(t, "alice@example.com", claims.Email)
+	assert.True(t, claims.EmailVerified)
+
+	// Code hanya bisa dipakai sekali
+	_, err = provider.Exchange(ctx, code, verifier, "nonce-1")
+	assert.Error(t, err)
+}
+
+func TestProviderRejectsWrongVerifierAndNonce(t *testing.T) {
+	fake := oidctest.NewProvider("client-1", oidctest.User{Subject: "sub-1"})
+	defer fake.Close()
+
+	provider := oidc.NewProvider(oidc.ProviderConfig{
+		Name:        "fake",
+		Issuer:      fake.Issuer(),
+		ClientID:    "client-1",
+		RedirectURL: "http://localhost/callback",
+	}, fake.Client())
+
+	ctx := context.Background()
+	verifier, err := oidc.NewCodeVerifier()
+	require.NoError(t, err)
+
+	authURL, err := provider.AuthCodeURL(ctx, "s", "nonce-1", verifier)
+	require.NoError(t, err)
+	code, _ := authorize(t, fake.Client(), authURL)
+	_, err = provider.Exchange(ctx, code, "wrong-verifier", "nonce-1")
+	assert.Error(t, err)
+
+	authURL, err = provider.AuthCodeURL(ctx, "s", "nonce-1", verifier)
+	require.NoError(t, err)
+	code, _ = authorize(t, fake.Client(), authURL)
+	_, err = provider.Exchange(ctx, code, verifier, "other-nonce")
+	assert.ErrorIs(t, err, oidc.ErrInvalidIDToken)
+}
@@ -3,12 +3,13 @@ package authrepository
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/wafi04/chatting-app/services/shared/middleware"
 	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
 	"github.com/wafi04/chatting-app/services/shared/types"
@@ -53,29 +54,26 @@ func (r *AuthRepository) CreateUser(ctx context.Context, req *types.CreateUserRe
 		return types.CreateUserResponse{}, fmt.Errorf("failed to create verification token: %w", err)
 	}
 
+	sessionID := utils.GenerateCustomID(utils.IDOptions{
+		NumberLength: 10,
+	})
 	access_token, err := middleware.GenerateToken(&types.UserInfo{
 		UserId:          userID,
 		Name:            req.Name,
 		Email:           req.Email,
 		IsEmailVerified: false,
-	}, 24)
+		Role:            types.RoleUser,
+	}, sessionID, AccessTokenTTLHours)
 	if err != nil {
 		return types.CreateUserResponse{}, fmt.Errorf("failed to generate tokens: %w", err)
 	}
-	refresh_token, err := middleware.GenerateToken(&types.UserInfo{
-		UserId:          userID,
-		Name:            req.Name,
-		Email:           req.Email,
-		IsEmailVerified: false,
-	}, 168)
+	refresh_token, err := NewRefreshToken()
 	if err != nil {
 		return types.CreateUserResponse{}, fmt.Errorf("failed to generate tokens: %w", err)
 	}
 
 	session := types.Session{
-		SessionId: utils.GenerateCustomID(utils.IDOptions{
-			NumberLength: 10,
-		}),
+		SessionId:      sessionID,
 		UserId:         userID,
 		AccessToken:    access_token,
 		RefreshToken:   refresh_token,
@@ -118,29 +116,40 @@ type dbUser struct {
 	UpdatedAt       int64
 	LastLoginAt     int64
 	IsActive        bool
+	Role            string
+	// Reactivatable: dinonaktifkan sendiri oleh user dan bisa dipulihkan dengan login
+	Reactivatable bool
 }
 
-func (r *AuthRepository) Login(ctx context.Context, login *types.LoginRequest) (*types.LoginResponse, error) {
+func (r *AuthRepository) findLoginUser(ctx context.Context, identifier string) (*dbUser, error) {
+	// Identifier dengan "@" dianggap email, selain itu username di user_profile
+	where := "LOWER(u.email) = LOWER($1)"
+	if !strings.Contains(identifier, "@") {
+		where = "LOWER(up.username) = LOWER($1)"
+	}
 
 	query := `
     SELECT
-        user_id,
-        name,
-        email,
-        password_hash,
-        COALESCE(picture, ''),
-        COALESCE(is_email_verified, false)::boolean,  
-        EXTRACT(EPOCH FROM created_at)::bigint,
-        EXTRACT(EPOCH FROM updated_at)::bigint,
-        EXTRACT(EPOCH FROM COALESCE(last_login_at, created_at))::bigint,
-        is_active::boolean
-    FROM users
-    WHERE name = $1
+        u.user_id,
+        u.name,
+        u.email,
+        u.password_hash,
+        COALESCE(u.picture, ''),
+        COALESCE(u.is_email_verified, false)::boolean,  
+        EXTRACT(EPOCH FROM u.created_at)::bigint,
+        EXTRACT(EPOCH FROM u.updated_at)::bigint,
+        EXTRACT(EPOCH FROM COALESCE(u.last_login_at, u.created_at))::bigint,
+        u.is_active::boolean,
+        u.role,
+        ` + reactivatableUser + `
+    FROM users u
+    LEFT JOIN user_profile up ON up.user_id = u.user_id
+    WHERE ` + where + `
+    LIMIT 1
 `
 
 	var dbuser dbUser
-
-	err := r.DB.QueryRowContext(ctx, query, login.Name).Scan(
+	err := r.DB.QueryRowContext(ctx, query, identifier).Scan(
 		&dbuser.UserID,
 		&dbuser.Name,
 		&dbuser.Email,
@@ -151,43 +160,84 @@ func (r *AuthRepository) Login(ctx context.Context, login *types.LoginRequest) (
 		&dbuser.UpdatedAt,
 		&dbuser.LastLoginAt,
 		&dbuser.IsActive,
+		&dbuser.Role,
+		&dbuser.Reactivatable,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("user not found")
+			return nil, nil
 		}
 		return nil, fmt.Errorf("database error: %w", err)
 	}
 
-	userInfo := &types.UserInfo{
+	return &dbuser, nil
+}
+
+// VerifyCredentials mengecek identifier (email atau username) dan password dengan
+// lockout per akun/IP. User tidak dikenal, user nonaktif dan password salah
+// menghasilkan error dan waktu respons yang sama.
+func (r *AuthRepository) VerifyCredentials(ctx context.Context, login *types.LoginRequest) (*types.UserInfo, error) {
+	identifier := strings.TrimSpace(login.Identifier)
+	if identifier == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	dbuser, err := r.findLoginUser(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var userID string
+	passwordHash := dummyPasswordHash
+	if dbuser != nil {
+		userID = dbuser.UserID
+		passwordHash = []byte(dbuser.Password)
+	}
+
+	accountKey := accountLockKey(userID, identifier)
+	keys := loginLockKeys(accountKey, login.IpAddress)
+	if err := r.checkLoginLock(ctx, keys); err != nil {
+		return nil, err
+	}
+
+	passwordErr := bcrypt.CompareHashAndPassword(passwordHash, []byte(login.Password))
+
+	var reason string
+	switch {
+	case dbuser == nil:
+		reason = "unknown_user"
+	case passwordErr != nil:
+		reason = "bad_password"
+	case !dbuser.IsActive && !dbuser.Reactivatable:
+		reason = "inactive"
+	}
+	if reason != "" {
+		if err := r.recordFailedLogin(ctx, keys, identifier, userID, login.IpAddress, login.DeviceInfo, reason); err != nil {
+			r.logger.Log(logger.ErrorLevel, "Failed to record failed login: %v", err)
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if err := r.resetLoginFailures(ctx, accountKey); err != nil {
+		r.logger.Log(logger.ErrorLevel, "Failed to reset login failures: %v", err)
+	}
+
+	return &types.UserInfo{
 		UserId:          dbuser.UserID,
 		Name:            dbuser.Name,
 		Email:           dbuser.Email,
 		IsEmailVerified: dbuser.IsEmailVerified,
-	}
+		Role:            dbuser.Role,
+	}, nil
+}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(dbuser.Password), []byte(login.Password)); err != nil {
-		return nil, errors.New("invalid credentials")
+// CreateLoginSession membuat (atau memakai ulang per device) sesi untuk user yang sudah terautentikasi
+func (r *AuthRepository) CreateLoginSession(ctx context.Context, userInfo *types.UserInfo, login *types.LoginRequest) (*types.LoginResponse, error) {
+	if err := r.reactivateAccount(ctx, userInfo.UserId); err != nil {
+		return nil, err
 	}
-	access_token, err := middleware.GenerateToken(&types.UserInfo{
-		UserId:          userInfo.UserId,
-		Name:            userInfo.Name,
-		Email:           userInfo.Email,
-		IsEmailVerified: userInfo.IsEmailVerified,
-	}, 24)
-	if err != nil {
-		return &types.LoginResponse{}, fmt.Errorf("failed to generate tokens: %w", err)
-	}
-	refresh_token, err := middleware.GenerateToken(&types.UserInfo{
-		UserId:          userInfo.UserId,
-		Name:            userInfo.Name,
-		Email:           userInfo.Email,
-		IsEmailVerified: userInfo.IsEmailVerified,
-	}, 168)
-	if err != nil {
-		return &types.LoginResponse{}, fmt.Errorf("failed to generate tokens: %w", err)
-	}
-	query = `
+
+	query := `
         SELECT 
             session_id, 
             ip_address,
@@ -195,11 +245,11 @@ func (r *AuthRepository) Login(ctx context.Context, login *types.LoginRequest) (
             EXTRACT(EPOCH FROM created_at)::bigint, 
           	EXTRACT(EPOCH FROM last_activity_at)::bigint
         FROM sessions 
-        WHERE user_id = $1 AND is_active = true AND device_info = $2
+        WHERE user_id = $1 AND is_active = true AND expires_at > NOW() AND device_info = $2
     `
 
 	var existingSession types.Session
-	err = r.DB.QueryRowContext(ctx, query, userInfo.UserId, login.DeviceInfo).Scan(
+	err := r.DB.QueryRowContext(ctx, query, userInfo.UserId, login.DeviceInfo).Scan(
 		&existingSession.SessionId,
 		&existingSession.IpAddress,
 		&existingSession.DeviceInfo,
@@ -211,11 +261,25 @@ func (r *AuthRepository) Login(ctx context.Context, login *types.LoginRequest) (
 		return nil, fmt.Errorf("error checking existing session: %w", err)
 	}
 
+	sessionID := existingSession.SessionId
 	if err == sql.ErrNoRows {
+		sessionID = utils.GenerateCustomID(utils.IDOptions{
+			NumberLength: 10,
+		})
+	}
+
+	access_token, err := middleware.GenerateToken(userInfo, sessionID, AccessTokenTTLHours)
+	if err != nil {
+		return &types.LoginResponse{}, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+	refresh_token, err := NewRefreshToken()
+	if err != nil {
+		return &types.LoginResponse{}, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+
+	if sessionID != existingSession.SessionId {
 		existingSession = types.Session{
-			SessionId: utils.GenerateCustomID(utils.IDOptions{
-				NumberLength: 10,
-			}),
+			SessionId:      sessionID,
 			UserId:         userInfo.UserId,
 			AccessToken:    access_token,
 			RefreshToken:   refresh_token,
@@ -231,6 +295,13 @@ func (r *AuthRepository) Login(ctx context.Context, login *types.LoginRequest) (
 		if err != nil {
 			return nil, fmt.Errorf("failed to create session: %w", err)
 		}
+	} else {
+		existingSession.AccessToken = access_token
+		existingSession.RefreshToken = refresh_token
+		existingSession.IpAddress = login.IpAddress
+		if err := r.ReissueSession(ctx, &existingSession); err != nil {
+			return nil, fmt.Errorf("failed to reissue session: %w", err)
+		}
 	}
 
 	_, err = r.DB.ExecContext(
@@ -268,7 +339,8 @@ func (sr *AuthRepository) GetUser(ctx context.Context, req *types.GetUserRequest
             is_email_verified,
             created_at, 
             updated_at, 
-            last_login_at
+            COALESCE(last_login_at, created_at),
+            role
         FROM users
         WHERE user_id = $1
     `
@@ -291,6 +363,7 @@ func (sr *AuthRepository) GetUser(ctx context.Context, req *types.GetUserRequest
 		&createdAt,
 		&updatedAt,
 		&lastLoginAt,
+		&user.Role,
 	)
 
 	if err != nil {
@@ -307,12 +380,58 @@ func (sr *AuthRepository) GetUser(ctx context.Context, req *types.GetUserRequest
 	return user, nil
 }
 
+// ActiveUserIDs mengembalikan subset userIds yang akunnya masih aktif
+func (sr *AuthRepository) ActiveUserIDs(ctx context.Context, userIds []string) (map[string]bool, error) {
+	active := make(map[string]bool, len(userIds))
+	if len(userIds) == 0 {
+		return active, nil
+	}
+
+	var ids []string
+	if err := sqlx.SelectContext(ctx, sr.DB, &ids,
+		`SELECT user_id FROM users WHERE user_id = ANY($1) AND is_active = true`,
+		pq.Array(userIds),
+	); err != nil {
+		return nil, fmt.Errorf("failed to check active users: %w", err)
+	}
+	for _, id := range ids {
+		active[id] = true
+	}
+	return active, nil
+}
+
+func (sr *AuthRepository) GetUserByEmail(ctx context.Context, email string) (*types.UserInfo, error) {
+	query := `
+        SELECT user_id, name, email, COALESCE(is_email_verified, false), role
+        FROM users
+        WHERE LOWER(email) = LOWER($1) AND is_active = true
+    `
+
+	user := &types.UserInfo{}
+	err := sr.DB.QueryRowContext(ctx, query, email).Scan(
+		&user.UserId,
+		&user.Name,
+		&user.Email,
+		&user.IsEmailVerified,
+		&user.Role,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
+	}
+
+	return user, nil
+}
+
 func (sr *AuthRepository) Logout(ctx context.Context, req *types.LogoutRequest) (*types.LogoutResponse, error) {
 	query := `
-	DELETE FROM sessions
-    WHERE access_token = $1 AND user_id = $2
+	UPDATE sessions
+	SET is_active = false, updated_at = CURRENT_TIMESTAMP
+    WHERE (session_id = $1 OR access_token = $2) AND user_id = $3
 	`
-	_, err := sr.DB.ExecContext(ctx, query, req.AccessToken, req.UserId)
+	_, err := sr.DB.ExecContext(ctx, query, req.SessionId, utils.HashToken(req.AccessToken), req.UserId)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed : %s", err.Error())
@@ -323,3 +442,25 @@ func (sr *AuthRepository) Logout(ctx context.Context, req *types.LogoutRequest)
 		Success: true,
 	}, nil
 }
+
+// reactivatableUser bernilai true untuk akun yang dinonaktifkan sendiri (termasuk yang
+// dijadwalkan dihapus tapi masih dalam masa tenggang dan belum mulai di-purge).
+// Query harus memakai alias u untuk users.
+const reactivatableUser = `(u.deactivated_at IS NOT NULL AND u.purge_started_at IS NULL AND (u.deletion_scheduled_at IS NULL OR u.deletion_scheduled_at > NOW()))`
+
+// reactivateAccount memulihkan akun yang dinonaktifkan sendiri dan membatalkan jadwal penghapusan.
+// Dipanggil setelah login berhasil penuh (termasuk 2FA); akun yang diblokir admin tidak tersentuh.
+func (r *AuthRepository) reactivateAccount(ctx context.Context, userId string) error {
+	res, err := r.DB.ExecContext(ctx, `
+        UPDATE users u
+        SET is_active = true, deactivated_at = NULL, deletion_scheduled_at = NULL, updated_at = CURRENT_TIMESTAMP
+        WHERE u.user_id = $1 AND `+reactivatableUser+`
+    `, userId)
+	if err != nil {
+		return fmt.Errorf("failed to reactivate account: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n > 0 {
+		r.logger.Log(logger.InfoLevel, "Account %s reactivated by login", userId)
+	}
+	return nil
+}
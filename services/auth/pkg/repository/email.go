@@ -2,117 +2,217 @@ package authrepository
 
 import (
 	"context"
+	"crypto/subtle"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/wafi04/chatting-app/services/shared/middleware"
 	"github.com/wafi04/chatting-app/services/shared/types"
+	"github.com/wafi04/chatting-app/services/shared/utils"
 	"golang.org/x/crypto/bcrypt"
 )
 
-func (s *AuthRepository) ResendVerification(ctx context.Context, req *types.ResendVerificationRequest) (*types.ResendVerificationResponse, error) {
-	// user, err := s.GetUser(ctx, &types.GetUserRequest{
-	// 	UserId: req.UserId,
-	// })
-	// if err != nil {
-	// 	s.logger.Log(logger.ErrorLevel, "Unauthorized : %v", err)
-	// 	return nil, fmt.Errorf("Unauthorized : %v", err)
-	// }
-
-	// verifyCode := logger.GenerateVerificationCode()
-	expiresAt := time.Now().Add(1 * time.Hour)
-
-	// appPW := logger.LoadEnv("APP_PASSWORD")
-	// cleanPassword := strings.ReplaceAll(appPW, " ", "")
-	// emailSender := mailer.NewEmailSender(
-	// 	"smtp.gmail.com",
-	// 	587,
-	// 	"wafiq3040@gmail.com",
-	// 	cleanPassword,
-	// )
-
-	// toEmail := user.User.Email
-
-	// if err := emailSender.SendVerificationEmail(toEmail, user.User.Name, 0); err != nil {
-	// 	return nil, fmt.Errorf("failed to send email : %w", err)
-	// }
-
-	// query := `
-	//     INSERT INTO verification_tokens (
-	//         token,
-	//         user_id,
-	//         verify_code,
-	//         token_type,
-	//         expires_at
-	//     ) VALUES ($1, $2, $3, $4, $5)
-	// `
-
-	// _, err = s.DB.ExecContext(ctx, query,
-	// 	req.Token,
-	// 	req.UserId,
-	// 	verifyCode,
-	// 	"EMAIL_VERIFICATION",
-	// 	expiresAt,
-	// )
-
-	// if err != nil {
-	// 	return nil, fmt.Errorf("failed to  verification token: %w", err)
-	// }
+const (
+	EmailVerificationTokenType = "EMAIL_VERIFICATION"
+	PasswordResetTokenType     = "PASSWORD_RESET"
+	verificationTokenTTL       = 1 * time.Hour
+	passwordResetTokenTTL      = 30 * time.Minute
+	resendCooldown             = 1 * time.Minute
+	maxResendPerHour           = 5
+	// Batas permintaan reset password dari satu IP, untuk semua akun
+	maxResetPerIPHour = 20
+	// Kode salah sebanyak ini menonaktifkan token; user harus meminta kode baru
+	maxVerifyAttempts = 5
+)
 
-	return &types.ResendVerificationResponse{
-		VerificationToken: req.Token,
-		// VerifyCode:        verifyCode,
-		Success:   true,
-		ExpiresAt: expiresAt.Unix(),
-	}, nil
+var (
+	ErrEmailAlreadyVerified    = errors.New("email already verified")
+	ErrInvalidVerificationCode = errors.New("invalid or expired verification code")
+	ErrInvalidResetToken       = errors.New("invalid or expired reset token")
+	ErrUserNotFound            = errors.New("user not found")
+)
+
+// ResendThrottledError dikembalikan jika user atau IP meminta kode verifikasi
+// atau reset password terlalu sering
+type ResendThrottledError struct {
+	RetryAfter time.Duration
 }
 
-func (s *AuthRepository) VerifyEmail(ctx context.Context, req *types.VerifyEmailRequest) (*types.VerifyEmailResponse, error) {
-	query := `
-    SELECT user_id, expires_at  
-    FROM verification_tokens 
-    WHERE token = $1 
-    AND verify_code = $2 
-    AND is_used = false 
-    AND expires_at > NOW()
-`
+func (e *ResendThrottledError) Error() string {
+	return fmt.Sprintf("too many requests, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// checkTokenThrottle membatasi pembuatan token berdasarkan riwayat satu jam terakhir.
+// column adalah kolom pengelompokan (user_id atau requested_ip), bukan input user.
+func (s *AuthRepository) checkTokenThrottle(ctx context.Context, column, value, tokenType string, cooldown time.Duration, limit int) error {
 	var (
-		userId    string
-		expiresAt time.Time
+		count    int
+		lastSent sql.NullTime
+		oldest   sql.NullTime
 	)
+	err := s.DB.QueryRowContext(ctx, fmt.Sprintf(`
+        SELECT COUNT(*), MAX(created_at), MIN(created_at)
+        FROM verification_tokens
+        WHERE %s = $1
+        AND token_type = $2
+        AND created_at > NOW() - INTERVAL '1 hour'
+    `, column), value, tokenType).Scan(&count, &lastSent, &oldest)
+	if err != nil {
+		return fmt.Errorf("failed to check token history: %w", err)
+	}
+
+	if cooldown > 0 && lastSent.Valid {
+		if wait := cooldown - time.Since(lastSent.Time); wait > 0 {
+			return &ResendThrottledError{RetryAfter: wait}
+		}
+	}
+	if count >= limit {
+		return &ResendThrottledError{RetryAfter: time.Until(oldest.Time.Add(time.Hour))}
+	}
+	return nil
+}
 
-	err := s.DB.QueryRowContext(ctx, query, req.VerificationToken, req.VerifyCode).Scan(&userId, &expiresAt)
+// CreateEmailVerification membuat token + kode verifikasi baru dan menonaktifkan token lama.
+// Yang disimpan hanya hash token, sama seperti token reset password.
+// VerifyCode pada response hanya untuk dikirim lewat email, jangan diteruskan ke client.
+func (s *AuthRepository) CreateEmailVerification(ctx context.Context, userId string) (*types.ResendVerificationResponse, error) {
+	token, err := utils.GenerateSecureToken(32)
 	if err != nil {
-		return nil, fmt.Errorf("verification failed: %w", err)
+		return nil, err
 	}
+	verifyCode := utils.GenerateVerificationCode()
+	expiresAt := time.Now().Add(verificationTokenTTL)
 
-	tx, err := s.DB.BeginTx(ctx, nil)
+	tx, err := s.DB.BeginTxx(ctx, nil)
 	if err != nil {
-		return nil, fmt.Errorf("transaction error: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	updateQuery := `
-        UPDATE users 
-        SET is_email_verified = true, updated_at = NOW() 
+	_, err = tx.ExecContext(ctx, `
+        UPDATE verification_tokens
+        SET is_used = true
+        WHERE user_id = $1 AND token_type = $2 AND is_used = false
+    `, userId, EmailVerificationTokenType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to invalidate verification tokens: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO verification_tokens (
+            token,
+            user_id,
+            verify_code,
+            token_type,
+            expires_at
+        ) VALUES ($1, $2, $3, $4, $5)
+    `, utils.HashToken(token), userId, verifyCode, EmailVerificationTokenType, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create verification token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return &types.ResendVerificationResponse{
+		Success:           true,
+		VerificationToken: token,
+		VerifyCode:        verifyCode,
+		ExpiresAt:         expiresAt.Unix(),
+	}, nil
+}
+
+func (s *AuthRepository) ResendVerification(ctx context.Context, req *types.ResendVerificationRequest) (*types.ResendVerificationResponse, error) {
+	verified, err := s.IsEmailVerified(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if verified {
+		return nil, ErrEmailAlreadyVerified
+	}
+
+	if err := s.checkTokenThrottle(ctx, "user_id", req.UserId, EmailVerificationTokenType, resendCooldown, maxResendPerHour); err != nil {
+		return nil, err
+	}
+
+	return s.CreateEmailVerification(ctx, req.UserId)
+}
+
+func (s *AuthRepository) IsEmailVerified(ctx context.Context, userId string) (bool, error) {
+	var verified bool
+	err := s.DB.QueryRowContext(ctx, `
+        SELECT COALESCE(is_email_verified, false)
+        FROM users
         WHERE user_id = $1
-    `
+    `, userId).Scan(&verified)
+	if err != nil {
+		return false, fmt.Errorf("failed to check email verification: %w", err)
+	}
 
-	markUsedQuery := `
-        UPDATE verification_tokens 
-        SET is_used = true 
+	return verified, nil
+}
+
+// VerifyEmail mencocokkan kode dengan token. Setiap kode salah dihitung per token dan
+// token dinonaktifkan setelah maxVerifyAttempts, supaya kode 6 digit tidak bisa ditebak.
+func (s *AuthRepository) VerifyEmail(ctx context.Context, req *types.VerifyEmailRequest) (*types.VerifyEmailResponse, error) {
+	tx, err := s.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("transaction error: %w", err)
+	}
+	defer tx.Rollback()
+
+	var (
+		tokenId    int64
+		userId     string
+		verifyCode string
+		attempts   int
+	)
+	err = tx.QueryRowContext(ctx, `
+        SELECT id, user_id, verify_code, failed_attempts
+        FROM verification_tokens
         WHERE token = $1
-    `
+        AND token_type = $2
+        AND is_used = false
+        AND expires_at > NOW()
+        FOR UPDATE
+    `, utils.HashToken(req.VerificationToken), EmailVerificationTokenType).Scan(&tokenId, &userId, &verifyCode, &attempts)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidVerificationCode
+		}
+		return nil, fmt.Errorf("verification failed: %w", err)
+	}
 
-	_, err = tx.ExecContext(ctx, updateQuery, userId)
+	if subtle.ConstantTimeCompare([]byte(verifyCode), []byte(req.VerifyCode)) != 1 {
+		_, err = tx.ExecContext(ctx, `
+            UPDATE verification_tokens
+            SET failed_attempts = failed_attempts + 1,
+                is_used = failed_attempts + 1 >= $2
+            WHERE id = $1
+        `, tokenId, maxVerifyAttempts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record verification attempt: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("commit error: %w", err)
+		}
+		return nil, ErrInvalidVerificationCode
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE users
+        SET is_email_verified = true, updated_at = NOW()
+        WHERE user_id = $1
+    `, userId)
 	if err != nil {
-		tx.Rollback()
 		return nil, fmt.Errorf("update user error: %w", err)
 	}
 
-	_, err = tx.ExecContext(ctx, markUsedQuery, req.VerificationToken)
+	_, err = tx.ExecContext(ctx, `UPDATE verification_tokens SET is_used = true WHERE id = $1`, tokenId)
 	if err != nil {
-		tx.Rollback()
 		return nil, fmt.Errorf("mark token error: %w", err)
 	}
 
@@ -126,47 +226,66 @@ func (s *AuthRepository) VerifyEmail(ctx context.Context, req *types.VerifyEmail
 		Message: "Email verified successfully",
 	}, nil
 }
-func (s *AuthRepository) RequestPasswordReset(ctx context.Context, req *types.RequestPasswordResetRequest) (*types.RequestPasswordResetResponse, error) {
-	var user types.User
-	checkUserQuery := `SELECT user_id,name,email,is_email_verified,is_active  FROM users WHERE email = $1`
-	err := s.DB.QueryRowContext(ctx, checkUserQuery, req.Email).Scan(&user.UserId, user.Name, user.Email, user.IsEmailVerified, user.IsActive)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return &types.RequestPasswordResetResponse{
-				Success: false,
-			}, nil
-		}
-		return nil, fmt.Errorf("failed to check user: %v", err)
+
+// CheckPasswordResetIPThrottle membatasi jumlah permintaan reset password dari satu IP,
+// dicek sebelum mencari akun supaya hasilnya tidak bergantung pada email terdaftar atau tidak
+func (s *AuthRepository) CheckPasswordResetIPThrottle(ctx context.Context) error {
+	ip := middleware.ClientInfoFromContext(ctx).IpAddress
+	if ip == "" {
+		return nil
 	}
+	return s.checkTokenThrottle(ctx, "requested_ip", ip, PasswordResetTokenType, 0, maxResetPerIPHour)
+}
 
-	token, err := middleware.GenerateToken(&types.UserInfo{
-		UserId:          user.UserId,
-		Name:            user.Name,
-		Email:           user.Email,
-		IsEmailVerified: user.IsEmailVerified,
-	}, 1)
+// CreatePasswordReset membuat token reset baru. Yang disimpan hanya hash-nya,
+// token mentah di response hanya untuk dikirim lewat email. Permintaan per user
+// dibatasi sama seperti kirim ulang verifikasi (ResendThrottledError).
+func (s *AuthRepository) CreatePasswordReset(ctx context.Context, userId string) (*types.RequestPasswordResetResponse, error) {
+	if err := s.checkTokenThrottle(ctx, "user_id", userId, PasswordResetTokenType, resendCooldown, maxResendPerHour); err != nil {
+		return nil, err
+	}
 
+	token, err := utils.GenerateSecureToken(32)
 	if err != nil {
 		return nil, err
 	}
+	expiresAt := time.Now().Add(passwordResetTokenTTL)
 
-	insertTokenQuery := `
-        INSERT INTO verification_tokens 
-        (token, user_id, token_type, expires_at) 
-        VALUES ($1, $2, 'PASSWORD', CURRENT_TIMESTAMP + INTERVAL '1 hour')`
+	tx, err := s.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	_, err = s.DB.ExecContext(ctx, insertTokenQuery, token, user.UserId)
+	_, err = tx.ExecContext(ctx, `
+        UPDATE verification_tokens
+        SET is_used = true
+        WHERE user_id = $1 AND token_type = $2 AND is_used = false
+    `, userId, PasswordResetTokenType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create reset token: %v", err)
+		return nil, fmt.Errorf("failed to invalidate reset tokens: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO verification_tokens (token, user_id, token_type, expires_at, requested_ip)
+        VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+    `, utils.HashToken(token), userId, PasswordResetTokenType, expiresAt, middleware.ClientInfoFromContext(ctx).IpAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reset token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	return &types.RequestPasswordResetResponse{
 		Success:    true,
 		ResetToken: token,
-		ExpiresAt:  time.Now().Add(1 * time.Hour).Unix(),
+		ExpiresAt:  expiresAt.Unix(),
 	}, nil
 }
 
+// ResetPassword mengganti password, menandai token terpakai dan mencabut semua sesi user
 func (s *AuthRepository) ResetPassword(ctx context.Context, req *types.ResetPasswordRequest) (*types.ResetPasswordResponse, error) {
 	tx, err := s.DB.BeginTx(ctx, nil)
 	if err != nil {
@@ -174,22 +293,21 @@ func (s *AuthRepository) ResetPassword(ctx context.Context, req *types.ResetPass
 	}
 	defer tx.Rollback()
 
+	tokenHash := utils.HashToken(req.ResetToken)
 	verifyTokenQuery := `
         SELECT user_id 
         FROM verification_tokens 
         WHERE token = $1 
-        AND token_type = 'password_reset'
+        AND token_type = $2
         AND expires_at > CURRENT_TIMESTAMP 
-        AND is_used = false`
+        AND is_used = false
+        FOR UPDATE`
 
 	var userID string
-	err = tx.QueryRowContext(ctx, verifyTokenQuery, req.ResetToken).Scan(&userID)
+	err = tx.QueryRowContext(ctx, verifyTokenQuery, tokenHash, PasswordResetTokenType).Scan(&userID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return &types.ResetPasswordResponse{
-				Success: false,
-				Message: "Invalid or expired reset token",
-			}, nil
+			return nil, ErrInvalidResetToken
 		}
 		return nil, fmt.Errorf("failed to verify reset token: %v", err)
 	}
@@ -217,18 +335,45 @@ func (s *AuthRepository) ResetPassword(ctx context.Context, req *types.ResetPass
         SET is_used = true 
         WHERE token = $1`
 
-	_, err = tx.ExecContext(ctx, markTokenUsedQuery, req.ResetToken)
+	_, err = tx.ExecContext(ctx, markTokenUsedQuery, tokenHash)
 	if err != nil {
 		return nil, fmt.Errorf("failed to mark token as used: %v", err)
 	}
 
+	revokeSessionsQuery := `
+        UPDATE sessions
+        SET is_active = false,
+            updated_at = CURRENT_TIMESTAMP
+        WHERE user_id = $1 AND is_active = true
+        RETURNING session_id`
+
+	rows, err := tx.QueryContext(ctx, revokeSessionsQuery, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %v", err)
+	}
+	var revoked []string
+	for rows.Next() {
+		var sessionId string
+		if err = rows.Scan(&sessionId); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan revoked session: %v", err)
+		}
+		revoked = append(revoked, sessionId)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %v", err)
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %v", err)
 	}
 
 	return &types.ResetPasswordResponse{
-		Success:   true,
-		Message:   "Password successfully reset",
-		UpdatedAt: updatedAt,
+		Success:         true,
+		Message:         "Password successfully reset",
+		UpdatedAt:       updatedAt,
+		UserId:          userID,
+		RevokedSessions: revoked,
 	}, nil
 }
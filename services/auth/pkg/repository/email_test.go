@@ -0,0 +1,54 @@
+package authrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/chatting-app/services/shared/types"
+	"github.com/wafi04/chatting-app/services/shared/utils"
+)
+
+func newMockRepository(t *testing.T) (*AuthRepository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return NewUserRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestVerifyEmailWrongCodeCountsAttempt(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, user_id, verify_code, failed_attempts\s+FROM verification_tokens`).
+		WithArgs(utils.HashToken("raw-token"), EmailVerificationTokenType).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "verify_code", "failed_attempts"}).AddRow(7, "u1", "123456", 4))
+	mock.ExpectExec(`UPDATE verification_tokens\s+SET failed_attempts = failed_attempts \+ 1`).
+		WithArgs(int64(7), maxVerifyAttempts).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err := repo.VerifyEmail(context.Background(), &types.VerifyEmailRequest{VerificationToken: "raw-token", VerifyCode: "000000"})
+	assert.ErrorIs(t, err, ErrInvalidVerificationCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerifyEmailCorrectCode(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id, user_id, verify_code, failed_attempts\s+FROM verification_tokens`).
+		WithArgs(utils.HashToken("raw-token"), EmailVerificationTokenType).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "verify_code", "failed_attempts"}).AddRow(7, "u1", "123456", 2))
+	mock.ExpectExec(`UPDATE users\s+SET is_email_verified = true`).WithArgs("u1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE verification_tokens SET is_used = true WHERE id = \$1`).WithArgs(int64(7)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	resp, err := repo.VerifyEmail(context.Background(), &types.VerifyEmailRequest{VerificationToken: "raw-token", VerifyCode: "123456"})
+	require.NoError(t, err)
+	assert.Equal(t, "u1", resp.UserId)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
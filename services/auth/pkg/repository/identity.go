@@ -0,0 +1,185 @@
+package authrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/wafi04/chatting-app/services/shared/types"
+	"github.com/wafi04/chatting-app/services/shared/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const oidcStateTTL = 10 * time.Minute
+
+var (
+	ErrInvalidOIDCState = errors.New("invalid or expired oidc state")
+	// Akun lokal dengan email belum terverifikasi tidak ditautkan otomatis,
+	// mencegah pre-hijacking lewat registrasi dengan email korban
+	ErrUnverifiedLocalAccount  = errors.New("an account with this email exists but its email is not verified")
+	ErrUnverifiedExternalEmail = errors.New("identity provider did not verify the email address")
+)
+
+type OIDCState struct {
+	Provider     string
+	Nonce        string
+	CodeVerifier string
+}
+
+// ExternalIdentity adalah identitas dari provider OIDC
+type ExternalIdentity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+func (r *AuthRepository) CreateOIDCState(ctx context.Context, state string, data *OIDCState) error {
+	_, err := r.DB.ExecContext(ctx, `
+        INSERT INTO oidc_states (state_hash, provider, nonce, code_verifier, expires_at)
+        VALUES ($1, $2, $3, $4, $5)
+    `, utils.HashToken(state), data.Provider, data.Nonce, data.CodeVerifier, time.Now().Add(oidcStateTTL))
+	if err != nil {
+		return fmt.Errorf("failed to create oidc state: %w", err)
+	}
+	return nil
+}
+
+// ConsumeOIDCState mengambil dan menghapus state sehingga callback tidak bisa diputar ulang
+func (r *AuthRepository) ConsumeOIDCState(ctx context.Context, state, provider string) (*OIDCState, error) {
+	data := &OIDCState{}
+	err := r.DB.QueryRowContext(ctx, `
+        DELETE FROM oidc_states
+        WHERE state_hash = $1 AND provider = $2 AND expires_at > NOW()
+        RETURNING provider, nonce, code_verifier
+    `, utils.HashToken(state), provider).Scan(&data.Provider, &data.Nonce, &data.CodeVerifier)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidOIDCState
+		}
+		return nil, fmt.Errorf("failed to consume oidc state: %w", err)
+	}
+	return data, nil
+}
+
+// ResolveExternalIdentity mencari user untuk identitas eksternal: lewat tautan yang sudah ada,
+// lalu lewat email terverifikasi, dan terakhir membuat user baru.
+func (r *AuthRepository) ResolveExternalIdentity(ctx context.Context, identity *ExternalIdentity) (*types.UserInfo, error) {
+	user, err := r.userByIdentity(ctx, identity.Provider, identity.Subject)
+	if err != nil || user != nil {
+		return user, err
+	}
+
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, ErrUnverifiedExternalEmail
+	}
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	user = &types.UserInfo{}
+	var isActive bool
+	err = tx.QueryRowContext(ctx, `
+        SELECT u.user_id, u.name, u.email, COALESCE(u.is_email_verified, false), u.is_active OR `+reactivatableUser+`, u.role
+        FROM users u
+        WHERE LOWER(u.email) = LOWER($1)
+        FOR UPDATE
+    `, identity.Email).Scan(&user.UserId, &user.Name, &user.Email, &user.IsEmailVerified, &isActive, &user.Role)
+	switch {
+	case err == sql.ErrNoRows:
+		user, err = createExternalUser(ctx, tx, identity)
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, fmt.Errorf("failed to find user by email: %w", err)
+	case !isActive:
+		return nil, ErrInvalidCredentials
+	case !user.IsEmailVerified:
+		return nil, ErrUnverifiedLocalAccount
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO user_identities (provider, subject, user_id, email)
+        VALUES ($1, $2, $3, $4)
+    `, identity.Provider, identity.Subject, user.UserId, identity.Email)
+	if err != nil {
+		return nil, fmt.Errorf("failed to link identity: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return user, nil
+}
+
+func (r *AuthRepository) userByIdentity(ctx context.Context, provider, subject string) (*types.UserInfo, error) {
+	user := &types.UserInfo{}
+	var isActive bool
+	err := r.DB.QueryRowContext(ctx, `
+        SELECT u.user_id, u.name, u.email, COALESCE(u.is_email_verified, false), u.is_active OR `+reactivatableUser+`, u.role
+        FROM user_identities ui
+        JOIN users u ON u.user_id = ui.user_id
+        WHERE ui.provider = $1 AND ui.subject = $2
+    `, provider, subject).Scan(&user.UserId, &user.Name, &user.Email, &user.IsEmailVerified, &isActive, &user.Role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find identity: %w", err)
+	}
+	if !isActive {
+		return nil, ErrInvalidCredentials
+	}
+
+	return user, nil
+}
+
+// createExternalUser membuat user dengan password acak yang tidak diketahui siapa pun;
+// user bisa memasang password lewat forgot-password
+func createExternalUser(ctx context.Context, tx *sqlx.Tx, identity *ExternalIdentity) (*types.UserInfo, error) {
+	randomPassword, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+	hashPw, err := bcrypt.GenerateFromPassword([]byte(randomPassword), 10)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	name := identity.Name
+	if name == "" {
+		name = strings.SplitN(identity.Email, "@", 2)[0]
+	}
+
+	user := &types.UserInfo{
+		UserId:          uuid.New().String(),
+		Name:            name,
+		Email:           identity.Email,
+		IsEmailVerified: true,
+		Picture:         identity.Picture,
+		Role:            types.RoleUser,
+	}
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO users (
+            user_id, name, email, password_hash, picture,
+            is_active, is_email_verified, created_at, updated_at
+        ) VALUES ($1, $2, $3, $4, NULLIF($5, ''), true, true, NOW(), NOW())
+    `, user.UserId, user.Name, user.Email, hashPw, user.Picture)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	return user, nil
+}
@@ -0,0 +1,159 @@
+package authrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	lockKeyAccount = "account"
+	lockKeyIP      = "ip"
+
+	// Jumlah gagal sebelum lockout mulai berlaku
+	accountFailureThreshold = 5
+	ipFailureThreshold      = 20
+	lockoutBase             = 30 * time.Second
+	lockoutMax              = 1 * time.Hour
+	// Counter direset jika tidak ada kegagalan selama window ini
+	failureWindow = 24 * time.Hour
+)
+
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// LoginLockedError dikembalikan jika akun atau IP sedang dikunci karena terlalu banyak gagal login
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// dummyPasswordHash dipakai untuk identifier yang tidak ditemukan, supaya waktu respons
+// sama dengan user yang ada dan tidak bisa dipakai untuk enumerasi akun
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password-for-timing"), 10)
+
+// LockoutDuration menghitung lama lockout: lockoutBase * 2^(failures-threshold), maksimal lockoutMax
+func LockoutDuration(failures, threshold int) time.Duration {
+	if failures < threshold {
+		return 0
+	}
+	exp := failures - threshold
+	if exp > 16 {
+		return lockoutMax
+	}
+	d := time.Duration(float64(lockoutBase) * math.Pow(2, float64(exp)))
+	if d > lockoutMax {
+		return lockoutMax
+	}
+	return d
+}
+
+type lockKey struct {
+	keyType   string
+	key       string
+	threshold int
+}
+
+func loginLockKeys(accountKey, ip string) []lockKey {
+	keys := []lockKey{{keyType: lockKeyAccount, key: accountKey, threshold: accountFailureThreshold}}
+	if ip != "" {
+		keys = append(keys, lockKey{keyType: lockKeyIP, key: ip, threshold: ipFailureThreshold})
+	}
+	return keys
+}
+
+// accountLockKey memakai user_id jika user ada, selain itu identifier yang dinormalisasi,
+// sehingga identifier tak dikenal dikunci dengan cara yang sama
+func accountLockKey(userID, identifier string) string {
+	if userID != "" {
+		return userID
+	}
+	return "unknown:" + strings.ToLower(strings.TrimSpace(identifier))
+}
+
+func (r *AuthRepository) checkLoginLock(ctx context.Context, keys []lockKey) error {
+	var retryAfter time.Duration
+	for _, k := range keys {
+		var lockedUntil sql.NullTime
+		err := r.DB.QueryRowContext(ctx, `
+            SELECT locked_until FROM login_attempts WHERE key_type = $1 AND key = $2
+        `, k.keyType, k.key).Scan(&lockedUntil)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to check login attempts: %w", err)
+		}
+		if lockedUntil.Valid {
+			if wait := time.Until(lockedUntil.Time); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LoginLockedError{RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// recordFailedLogin menaikkan counter gagal, menghitung lockout dan mencatat audit
+func (r *AuthRepository) recordFailedLogin(ctx context.Context, keys []lockKey, identifier, userID, ip, userAgent, reason string) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, k := range keys {
+		var failures int
+		err := tx.QueryRowContext(ctx, `
+            INSERT INTO login_attempts (key_type, key, failed_count, last_failed_at)
+            VALUES ($1, $2, 1, NOW())
+            ON CONFLICT (key_type, key) DO UPDATE SET
+                failed_count = CASE
+                    WHEN login_attempts.last_failed_at < NOW() - make_interval(secs => $3) THEN 1
+                    ELSE login_attempts.failed_count + 1
+                END,
+                last_failed_at = NOW()
+            RETURNING failed_count
+        `, k.keyType, k.key, failureWindow.Seconds()).Scan(&failures)
+		if err != nil {
+			return fmt.Errorf("failed to record login attempt: %w", err)
+		}
+
+		if lock := LockoutDuration(failures, k.threshold); lock > 0 {
+			_, err = tx.ExecContext(ctx, `
+                UPDATE login_attempts SET locked_until = $1 WHERE key_type = $2 AND key = $3
+            `, time.Now().Add(lock), k.keyType, k.key)
+			if err != nil {
+				return fmt.Errorf("failed to lock login: %w", err)
+			}
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO login_audit (identifier, user_id, ip_address, user_agent, reason)
+        VALUES ($1, NULLIF($2, ''), $3, $4, $5)
+    `, identifier, userID, ip, userAgent, reason)
+	if err != nil {
+		return fmt.Errorf("failed to write login audit: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *AuthRepository) resetLoginFailures(ctx context.Context, accountKey string) error {
+	_, err := r.DB.ExecContext(ctx, `
+        DELETE FROM login_attempts WHERE key_type = $1 AND key = $2
+    `, lockKeyAccount, accountKey)
+	if err != nil {
+		return fmt.Errorf("failed to reset login attempts: %w", err)
+	}
+	return nil
+}
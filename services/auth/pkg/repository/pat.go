@@ -0,0 +1,187 @@
+package authrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/wafi04/chatting-app/services/shared/middleware"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+	"github.com/wafi04/chatting-app/services/shared/types"
+	"github.com/wafi04/chatting-app/services/shared/utils"
+)
+
+const (
+	MaxAccessTokensPerUser = 20
+	// Penulisan last_used_at dibatasi supaya tidak ada UPDATE di setiap request
+	patLastUsedResolution = time.Minute
+)
+
+var (
+	ErrAccessTokenNotFound = errors.New("access token not found")
+	ErrTooManyAccessTokens = fmt.Errorf("a user can have at most %d active access tokens", MaxAccessTokensPerUser)
+)
+
+// CreateAccessToken membuat personal access token; hanya hash-nya yang disimpan
+func (r *AuthRepository) CreateAccessToken(ctx context.Context, req *types.CreateAccessTokenRequest, expiresAt time.Time) (*types.CreateAccessTokenResponse, error) {
+	secret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+	token := middleware.PATPrefix + secret
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock baris user supaya batas jumlah token tidak bisa dilewati dengan request paralel
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM users WHERE user_id = $1 FOR UPDATE`, req.UserId); err != nil {
+		return nil, fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	var active int
+	err = tx.QueryRowContext(ctx, `
+        SELECT COUNT(*) FROM personal_access_tokens
+        WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+    `, req.UserId).Scan(&active)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count access tokens: %w", err)
+	}
+	if active >= MaxAccessTokensPerUser {
+		return nil, ErrTooManyAccessTokens
+	}
+
+	resp := &types.CreateAccessTokenResponse{
+		AccessToken: types.AccessToken{
+			Id:        uuid.New().String(),
+			Name:      req.Name,
+			Prefix:    token[:len(middleware.PATPrefix)+6],
+			Scopes:    req.Scopes,
+			CreatedAt: time.Now().Unix(),
+			ExpiresAt: expiresAt.Unix(),
+		},
+		Token: token,
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO personal_access_tokens (id, user_id, name, token_hash, token_prefix, scopes, expires_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7)
+    `, resp.Id, req.UserId, req.Name, utils.HashToken(token), resp.Prefix, pq.Array(req.Scopes), expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create access token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return resp, nil
+}
+
+// ListAccessTokens mengembalikan token yang belum dicabut dan belum kedaluwarsa
+func (r *AuthRepository) ListAccessTokens(ctx context.Context, userId string) ([]*types.AccessToken, error) {
+	rows, err := r.DB.QueryContext(ctx, `
+        SELECT
+            id,
+            name,
+            token_prefix,
+            scopes,
+            EXTRACT(EPOCH FROM created_at)::bigint,
+            EXTRACT(EPOCH FROM expires_at)::bigint,
+            COALESCE(EXTRACT(EPOCH FROM last_used_at)::bigint, 0)
+        FROM personal_access_tokens
+        WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+        ORDER BY created_at DESC
+    `, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list access tokens: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := []*types.AccessToken{}
+	for rows.Next() {
+		token := &types.AccessToken{}
+		var scopes pq.StringArray
+		if err := rows.Scan(
+			&token.Id,
+			&token.Name,
+			&token.Prefix,
+			&scopes,
+			&token.CreatedAt,
+			&token.ExpiresAt,
+			&token.LastUsedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan access token: %w", err)
+		}
+		token.Scopes = scopes
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating access tokens: %w", err)
+	}
+
+	return tokens, nil
+}
+
+func (r *AuthRepository) RevokeAccessToken(ctx context.Context, userId, tokenId string) error {
+	res, err := r.DB.ExecContext(ctx, `
+        UPDATE personal_access_tokens
+        SET revoked_at = NOW()
+        WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+    `, tokenId, userId)
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAccessTokenNotFound
+	}
+	return nil
+}
+
+// AuthenticateAccessToken dipakai sebagai middleware.PATLookup
+func (r *AuthRepository) AuthenticateAccessToken(ctx context.Context, token string) (*middleware.PATPrincipal, error) {
+	principal := &middleware.PATPrincipal{User: &types.UserInfo{}}
+	var (
+		scopes   pq.StringArray
+		lastUsed sql.NullTime
+	)
+	err := r.DB.QueryRowContext(ctx, `
+        SELECT t.id, t.scopes, t.last_used_at, u.user_id, u.name, u.email, COALESCE(u.is_email_verified, false), u.role
+        FROM personal_access_tokens t
+        JOIN users u ON u.user_id = t.user_id
+        WHERE t.token_hash = $1
+        AND t.revoked_at IS NULL
+        AND t.expires_at > NOW()
+        AND u.is_active = true
+    `, utils.HashToken(token)).Scan(
+		&principal.TokenID,
+		&scopes,
+		&lastUsed,
+		&principal.User.UserId,
+		&principal.User.Name,
+		&principal.User.Email,
+		&principal.User.IsEmailVerified,
+		&principal.User.Role,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to authenticate access token: %w", err)
+	}
+	principal.Scopes = scopes
+
+	if !lastUsed.Valid || time.Since(lastUsed.Time) > patLastUsedResolution {
+		_, err := r.DB.ExecContext(ctx, `UPDATE personal_access_tokens SET last_used_at = NOW() WHERE id = $1`, principal.TokenID)
+		if err != nil {
+			r.logger.Log(logger.WarnLevel, "Failed to update access token usage: %v", err)
+		}
+	}
+
+	return principal, nil
+}
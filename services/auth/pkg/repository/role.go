@@ -0,0 +1,167 @@
+package authrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+var (
+	ErrInvalidRole = errors.New("invalid role")
+	ErrLastAdmin   = errors.New("cannot remove the last admin")
+)
+
+const (
+	defaultUserPageSize = 20
+	maxUserPageSize     = 100
+)
+
+func (r *AuthRepository) GetUserRole(ctx context.Context, userId string) (string, error) {
+	var role string
+	err := r.DB.QueryRowContext(ctx, `SELECT role FROM users WHERE user_id = $1 AND is_active = true`, userId).Scan(&role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrUserNotFound
+		}
+		return "", fmt.Errorf("failed to get user role: %w", err)
+	}
+
+	return role, nil
+}
+
+// SetUserRole mengubah role user; admin terakhir tidak bisa diturunkan
+func (r *AuthRepository) SetUserRole(ctx context.Context, userId, role string) error {
+	if !types.IsValidRole(role) {
+		return ErrInvalidRole
+	}
+
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Kunci semua admin agar dua demosi paralel tidak menghabiskan admin
+	var admins []string
+	if err := tx.SelectContext(ctx, &admins, `SELECT user_id FROM users WHERE role = $1 AND is_active = true FOR UPDATE`, types.RoleAdmin); err != nil {
+		return fmt.Errorf("failed to lock admins: %w", err)
+	}
+
+	var current string
+	err = tx.QueryRowContext(ctx, `SELECT role FROM users WHERE user_id = $1 FOR UPDATE`, userId).Scan(&current)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user role: %w", err)
+	}
+
+	if current == types.RoleAdmin && role != types.RoleAdmin && len(admins) <= 1 {
+		return ErrLastAdmin
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE users SET role = $2, updated_at = CURRENT_TIMESTAMP WHERE user_id = $1
+    `, userId, role)
+	if err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *AuthRepository) ListUsers(ctx context.Context, req *types.ListUsersRequest) (*types.ListUsersResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultUserPageSize
+	}
+	if limit > maxUserPageSize {
+		limit = maxUserPageSize
+	}
+	offset := req.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	var (
+		conditions []string
+		args       []interface{}
+	)
+	if q := strings.TrimSpace(req.Query); q != "" {
+		args = append(args, "%"+q+"%")
+		conditions = append(conditions, fmt.Sprintf("(name ILIKE $%d OR email ILIKE $%d)", len(args), len(args)))
+	}
+	if req.Role != "" {
+		if !types.IsValidRole(req.Role) {
+			return nil, ErrInvalidRole
+		}
+		args = append(args, req.Role)
+		conditions = append(conditions, fmt.Sprintf("role = $%d", len(args)))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	if err := r.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM users `+where, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	args = append(args, limit, offset)
+	query := fmt.Sprintf(`
+        SELECT
+            user_id,
+            name,
+            email,
+            COALESCE(picture, ''),
+            COALESCE(is_email_verified, false),
+            role,
+            is_active,
+            EXTRACT(EPOCH FROM created_at)::bigint,
+            EXTRACT(EPOCH FROM updated_at)::bigint,
+            EXTRACT(EPOCH FROM COALESCE(last_login_at, created_at))::bigint
+        FROM users
+        %s
+        ORDER BY created_at DESC
+        LIMIT $%d OFFSET $%d
+    `, where, len(args)-1, len(args))
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*types.AdminUser{}
+	for rows.Next() {
+		user := &types.AdminUser{}
+		if err := rows.Scan(
+			&user.UserId,
+			&user.Name,
+			&user.Email,
+			&user.Picture,
+			&user.IsEmailVerified,
+			&user.Role,
+			&user.IsActive,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.LastLoginAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+
+	return &types.ListUsersResponse{
+		Users: users,
+		Total: total,
+	}, nil
+}
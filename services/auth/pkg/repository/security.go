@@ -0,0 +1,132 @@
+package authrepository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+const (
+	defaultSecurityEventLimit = 20
+	maxSecurityEventLimit     = 100
+)
+
+func (r *AuthRepository) RecordSecurityEvent(ctx context.Context, event *types.SecurityEvent) error {
+	metadata := []byte("{}")
+	if len(event.Metadata) > 0 {
+		var err error
+		if metadata, err = json.Marshal(event.Metadata); err != nil {
+			return fmt.Errorf("failed to encode security event metadata: %w", err)
+		}
+	}
+
+	_, err := r.DB.ExecContext(ctx, `
+        INSERT INTO security_events (user_id, event_type, ip_address, user_agent, device, browser, os, metadata)
+        VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), $8)
+    `, event.UserId, event.Type, event.IpAddress, event.UserAgent, event.Device, event.Browser, event.OS, metadata)
+	if err != nil {
+		return fmt.Errorf("failed to record security event: %w", err)
+	}
+	return nil
+}
+
+// ListSecurityEvents mengembalikan event terbaru lebih dulu, dipaginasi dengan cursor id
+func (r *AuthRepository) ListSecurityEvents(ctx context.Context, req *types.ListSecurityEventsRequest) (*types.ListSecurityEventsResponse, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSecurityEventLimit
+	}
+	if limit > maxSecurityEventLimit {
+		limit = maxSecurityEventLimit
+	}
+
+	rows, err := r.DB.QueryContext(ctx, `
+        SELECT
+            id,
+            event_type,
+            COALESCE(ip_address, ''),
+            COALESCE(user_agent, ''),
+            COALESCE(device, ''),
+            COALESCE(browser, ''),
+            COALESCE(os, ''),
+            metadata,
+            EXTRACT(EPOCH FROM created_at)::bigint
+        FROM security_events
+        WHERE user_id = $1 AND ($2::bigint = 0 OR id < $2)
+        ORDER BY id DESC
+        LIMIT $3
+    `, req.UserId, req.Before, limit+1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list security events: %w", err)
+	}
+	defer rows.Close()
+
+	resp := &types.ListSecurityEventsResponse{Events: []*types.SecurityEvent{}}
+	for rows.Next() {
+		event := &types.SecurityEvent{UserId: req.UserId}
+		var metadata []byte
+		if err := rows.Scan(
+			&event.Id,
+			&event.Type,
+			&event.IpAddress,
+			&event.UserAgent,
+			&event.Device,
+			&event.Browser,
+			&event.OS,
+			&metadata,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan security event: %w", err)
+		}
+		if err := json.Unmarshal(metadata, &event.Metadata); err != nil {
+			return nil, fmt.Errorf("failed to decode security event metadata: %w", err)
+		}
+		resp.Events = append(resp.Events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating security events: %w", err)
+	}
+
+	if len(resp.Events) > limit {
+		resp.Events = resp.Events[:limit]
+		resp.NextBefore = resp.Events[limit-1].Id
+	}
+	return resp, nil
+}
+
+// TouchKnownDevice mencatat perangkat yang dipakai login. isNew true jika fingerprint
+// belum pernah terlihat; firstDevice true jika user belum punya perangkat sama sekali
+// (login pertama tidak perlu dianggap mencurigakan).
+func (r *AuthRepository) TouchKnownDevice(ctx context.Context, userId, fingerprint, ipAddress string) (isNew bool, firstDevice bool, err error) {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var known int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM known_devices WHERE user_id = $1`, userId).Scan(&known)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to count known devices: %w", err)
+	}
+
+	// xmax = 0 berarti baris baru di-INSERT, bukan kena ON CONFLICT UPDATE
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO known_devices (user_id, fingerprint, last_ip_address)
+        VALUES ($1, $2, NULLIF($3, ''))
+        ON CONFLICT (user_id, fingerprint) DO UPDATE
+        SET last_seen_at = CURRENT_TIMESTAMP,
+            last_ip_address = EXCLUDED.last_ip_address
+        RETURNING (xmax = 0)
+    `, userId, fingerprint, ipAddress).Scan(&isNew)
+	if err != nil {
+		return false, false, fmt.Errorf("failed to record known device: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return isNew, known == 0, nil
+}
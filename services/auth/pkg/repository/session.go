@@ -3,35 +3,118 @@ package authrepository
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/wafi04/chatting-app/services/shared/middleware"
 	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
 	"github.com/wafi04/chatting-app/services/shared/types"
+	"github.com/wafi04/chatting-app/services/shared/utils"
 )
 
+var ErrSessionNotFound = errors.New("session not found")
+
 func (sr *AuthRepository) RevokeSession(ctx context.Context, req *types.RevokeSessionRequest) (*types.RevokeSessionResponse, error) {
 	sr.logger.Log(logger.InfoLevel, "Recieved  Session Request ")
 
 	query := `
-	DELETE FROM sessions
-    WHERE session_id = $1 AND user_id = $2
+	UPDATE sessions
+	SET is_active = false, updated_at = CURRENT_TIMESTAMP
+    WHERE session_id = $1 AND user_id = $2 AND is_active = true
 	`
-	_, err := sr.DB.ExecContext(ctx, query, req.SessionId, req.UserId)
+	result, err := sr.DB.ExecContext(ctx, query, req.SessionId, req.UserId)
+	if err != nil {
+		sr.logger.Log(logger.ErrorLevel, "Failed to revoke session: %v", err)
+		return nil, fmt.Errorf("failed to revoke session: %w", err)
+	}
 
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		sr.logger.Log(logger.ErrorLevel, "Failed to Delete Session : %v", err)
-		return nil, nil
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, ErrSessionNotFound
 	}
 
 	return &types.RevokeSessionResponse{
 		Success: true}, nil
 }
+
+// RevokeOtherSessions mencabut semua sesi aktif user kecuali currentSessionId,
+// mengembalikan id sesi yang dicabut
+func (sr *AuthRepository) RevokeOtherSessions(ctx context.Context, userId, currentSessionId string) ([]string, error) {
+	query := `
+	UPDATE sessions
+	SET is_active = false, updated_at = CURRENT_TIMESTAMP
+	WHERE user_id = $1 AND session_id <> $2 AND is_active = true
+	RETURNING session_id
+	`
+
+	var revoked []string
+	if err := sr.DB.SelectContext(ctx, &revoked, query, userId, currentSessionId); err != nil {
+		return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+
+	return revoked, nil
+}
+
+func (sr *AuthRepository) IsSessionActive(ctx context.Context, sessionId string) (bool, error) {
+	query := `
+	SELECT s.is_active AND s.expires_at > NOW() AND u.is_active
+	FROM sessions s
+	JOIN users u ON u.user_id = s.user_id
+	WHERE s.session_id = $1
+	`
+
+	var active bool
+	err := sr.DB.QueryRowContext(ctx, query, sessionId).Scan(&active)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check session: %w", err)
+	}
+
+	return active, nil
+}
+
+const (
+	AccessTokenTTLHours = 24
+	// Refresh token berlaku 7 hari sejak rotasi terakhir
+	RefreshTokenTTL = 168 * time.Hour
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrSessionExpired      = errors.New("session expired or revoked")
+)
+
+// NewRefreshToken membuat refresh token opaque. Yang disimpan di database hanya hash-nya.
+func NewRefreshToken() (string, error) {
+	return utils.GenerateSecureToken(32)
+}
+
+// CreateSession menyimpan sesi baru beserta refresh token pertamanya (keluarga rotasi).
+// session.AccessToken dan session.RefreshToken berisi token mentah, yang disimpan hash-nya.
 func (sr *AuthRepository) CreateSession(ctx context.Context, session *types.Session) error {
-	// Pisahkan query insert dan update
-	insertQuery := `
+	if session.SessionId == "" {
+		session.SessionId = uuid.New().String()
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(RefreshTokenTTL)
+
+	tx, err := sr.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
        INSERT INTO sessions (
            session_id, 
            user_id, 
@@ -44,33 +127,11 @@ func (sr *AuthRepository) CreateSession(ctx context.Context, session *types.Sess
            last_activity_at, 
            created_at
        ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-   `
-
-	updateQuery := `
-       UPDATE sessions 
-       SET 
-           access_token = $1, 
-           refresh_token = $2, 
-           ip_address = $3, 
-           last_activity_at = $4
-       WHERE user_id = $5 AND device_info = $6
-   `
-
-	if session.SessionId == "" {
-		session.SessionId = uuid.New().String()
-	}
-
-	now := time.Now()
-	expiresAt := now.Add(24 * time.Hour)
-
-	// Pertama, coba insert
-	_, err := sr.DB.ExecContext(
-		ctx,
-		insertQuery,
+   `,
 		session.SessionId,
 		session.UserId,
-		session.AccessToken,
-		session.RefreshToken,
+		utils.HashToken(session.AccessToken),
+		utils.HashToken(session.RefreshToken),
 		session.IpAddress,
 		session.DeviceInfo,
 		true,
@@ -78,72 +139,207 @@ func (sr *AuthRepository) CreateSession(ctx context.Context, session *types.Sess
 		now,
 		now,
 	)
-
-	// Jika insert gagal (misal duplicate), lakukan update
-	if err != nil {
-		_, err = sr.DB.ExecContext(
-			ctx,
-			updateQuery,
-			session.AccessToken,
-			session.RefreshToken,
-			session.IpAddress,
-			now,
-			session.UserId,
-			session.DeviceInfo,
-		)
-	}
-
 	if err != nil {
 		sr.logger.WithError(err).WithFields(map[string]interface{}{
 			"user_id":    session.UserId,
 			"session_id": session.SessionId,
-		}).Error("Failed to create/update session")
+		}).Error("Failed to create session")
 		return fmt.Errorf("failed to create session: %w", err)
 	}
 
+	if err := insertRefreshToken(ctx, tx, session.SessionId, session.RefreshToken, expiresAt); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	session.ExpiresAt = expiresAt.Unix()
 	return nil
 }
+
+// ReissueSession dipakai saat login ulang di device yang sama: token lama dibuang
+// (bukan ditandai rotated) sehingga tidak memicu deteksi reuse.
+func (sr *AuthRepository) ReissueSession(ctx context.Context, session *types.Session) error {
+	now := time.Now()
+	expiresAt := now.Add(RefreshTokenTTL)
+
+	tx, err := sr.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM session_refresh_tokens WHERE session_id = $1`, session.SessionId)
+	if err != nil {
+		return fmt.Errorf("failed to clear refresh tokens: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE sessions
+        SET access_token = $1,
+            refresh_token = $2,
+            ip_address = $3,
+            expires_at = $4,
+            last_activity_at = $5,
+            updated_at = $5
+        WHERE session_id = $6
+    `,
+		utils.HashToken(session.AccessToken),
+		utils.HashToken(session.RefreshToken),
+		session.IpAddress,
+		expiresAt,
+		now,
+		session.SessionId,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update session: %w", err)
+	}
+
+	if err := insertRefreshToken(ctx, tx, session.SessionId, session.RefreshToken, expiresAt); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	session.ExpiresAt = expiresAt.Unix()
+	return nil
+}
+
+func insertRefreshToken(ctx context.Context, tx *sqlx.Tx, sessionId, token string, expiresAt time.Time) error {
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO session_refresh_tokens (token_hash, session_id, expires_at)
+        VALUES ($1, $2, $3)
+    `, utils.HashToken(token), sessionId, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to store refresh token: %w", err)
+	}
+	return nil
+}
+
+// RefreshToken merotasi refresh token. Token lama ditandai rotated; jika token yang
+// sudah dirotasi dipakai lagi, seluruh sesi (keluarga token) dicabut.
 func (sr *AuthRepository) RefreshToken(ctx context.Context, req *types.RefreshTokenRequest) (*types.RefreshTokenResponse, error) {
 	sr.logger.Log(logger.InfoLevel, "Refresh Token Incoming")
 
+	tx, err := sr.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	query := `
-        SELECT u.user_id, u.email, u.role, u.is_email_verified
-        FROM sessions s
-        JOIN users u ON s.user_id = u.user_id
-        WHERE s.session_id = $1
+        SELECT
+            rt.session_id,
+            rt.rotated_at IS NOT NULL,
+            s.is_active AND s.expires_at > NOW() AND rt.expires_at > NOW() AND u.is_active,
+            u.user_id,
+            u.name,
+            u.email,
+            COALESCE(u.is_email_verified, false),
+            u.role
+        FROM session_refresh_tokens rt
+        JOIN sessions s ON s.session_id = rt.session_id
+        JOIN users u ON u.user_id = s.user_id
+        WHERE rt.token_hash = $1
+        FOR UPDATE OF rt, s
     `
-	var user types.UserInfo
-	err := sr.DB.QueryRowContext(ctx, query, req.SessionId).Scan(
+	var (
+		sessionId string
+		rotated   bool
+		valid     bool
+		user      types.UserInfo
+	)
+	err = tx.QueryRowContext(ctx, query, utils.HashToken(req.RefreshToken)).Scan(
+		&sessionId,
+		&rotated,
+		&valid,
 		&user.UserId,
+		&user.Name,
 		&user.Email,
 		&user.IsEmailVerified,
+		&user.Role,
 	)
 	if err != nil {
-		sr.logger.Log(logger.ErrorLevel, "Failed to retrieve user: %v", err)
-		return nil, err
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, fmt.Errorf("failed to look up refresh token: %w", err)
 	}
 
-	updateQuery := `
-        UPDATE sessions SET access_token = $1 WHERE session_id = $2
-    `
-	_, err = sr.DB.ExecContext(ctx, updateQuery, req.RefreshToken, req.SessionId)
+	if req.SessionId != "" && req.SessionId != sessionId {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if rotated {
+		_, err = tx.ExecContext(ctx, `
+            UPDATE sessions
+            SET is_active = false, updated_at = NOW()
+            WHERE session_id = $1
+        `, sessionId)
+		if err != nil {
+			return nil, fmt.Errorf("failed to revoke session: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		sr.logger.Log(logger.WarnLevel, "Refresh token reuse detected, session %s revoked", sessionId)
+		return nil, ErrRefreshTokenReused
+	}
+
+	if !valid {
+		return nil, ErrSessionExpired
+	}
+
+	accessToken, err := middleware.GenerateToken(&user, sessionId, AccessTokenTTLHours)
 	if err != nil {
-		sr.logger.Log(logger.ErrorLevel, "Failed to Refresh Token: %v", err)
 		return nil, err
 	}
-	access_token, err := middleware.GenerateToken(&user, 24)
+	refreshToken, err := NewRefreshToken()
 	if err != nil {
 		return nil, err
 	}
-	refresh_token, err := middleware.GenerateToken(&user, 168)
+
+	now := time.Now()
+	expiresAt := now.Add(RefreshTokenTTL)
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE session_refresh_tokens SET rotated_at = $1 WHERE token_hash = $2
+    `, now, utils.HashToken(req.RefreshToken))
 	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	if err := insertRefreshToken(ctx, tx, sessionId, refreshToken, expiresAt); err != nil {
 		return nil, err
 	}
 
+	_, err = tx.ExecContext(ctx, `
+        UPDATE sessions
+        SET access_token = $1,
+            refresh_token = $2,
+            expires_at = $3,
+            last_activity_at = $4,
+            updated_at = $4
+        WHERE session_id = $5
+    `, utils.HashToken(accessToken), utils.HashToken(refreshToken), expiresAt, now, sessionId)
+	if err != nil {
+		sr.logger.Log(logger.ErrorLevel, "Failed to Refresh Token: %v", err)
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return &types.RefreshTokenResponse{
-		AccessToken:  access_token,
-		RefreshToken: refresh_token,
-		ExpiresAt:    time.Now().Add(24 * time.Hour).Unix(),
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		SessionId:    sessionId,
+		ExpiresAt:    now.Add(AccessTokenTTLHours * time.Hour).Unix(),
 	}, nil
 }
 
@@ -156,7 +352,8 @@ func (sr *AuthRepository) ListSessions(ctx context.Context, req *types.ListSessi
             EXTRACT(EPOCH FROM created_at)::bigint AS created_at,
             EXTRACT(EPOCH FROM last_activity_at)::bigint AS last_activity_at
         FROM sessions
-        WHERE user_id = $1
+        WHERE user_id = $1 AND is_active = true AND expires_at > NOW()
+        ORDER BY last_activity_at DESC
     `
 
 	rows, err := sr.DB.QueryContext(ctx, query, req.UserId)
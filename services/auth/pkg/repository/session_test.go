@@ -0,0 +1,116 @@
+package authrepository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/chatting-app/services/shared/middleware"
+	"github.com/wafi04/chatting-app/services/shared/types"
+	"github.com/wafi04/chatting-app/services/shared/utils"
+)
+
+var refreshColumns = []string{"session_id", "rotated", "valid", "user_id", "name", "email", "is_email_verified", "role"}
+
+func setTestKeySet(t *testing.T) {
+	keySet, err := middleware.NewKeySet("test", middleware.NewHMACKey("test", []byte("test-secret")))
+	require.NoError(t, err)
+	middleware.SetKeySet(keySet)
+}
+
+func TestRefreshTokenRotates(t *testing.T) {
+	setTestKeySet(t)
+	repo, mock := newMockRepository(t)
+	oldHash := utils.HashToken("old-refresh")
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM session_refresh_tokens rt`).
+		WithArgs(oldHash).
+		WillReturnRows(sqlmock.NewRows(refreshColumns).AddRow("s1", false, true, "u1", "User", "u1@example.com", true, "user"))
+	mock.ExpectExec(`UPDATE session_refresh_tokens SET rotated_at = \$1 WHERE token_hash = \$2`).
+		WithArgs(sqlmock.AnyArg(), oldHash).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO session_refresh_tokens`).
+		WithArgs(sqlmock.AnyArg(), "s1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE sessions\s+SET access_token = \$1`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "s1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	resp, err := repo.RefreshToken(context.Background(), &types.RefreshTokenRequest{RefreshToken: "old-refresh"})
+	require.NoError(t, err)
+	assert.Equal(t, "s1", resp.SessionId)
+	assert.NotEmpty(t, resp.AccessToken)
+	assert.NotEqual(t, "old-refresh", resp.RefreshToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshTokenReplayRevokesSession(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM session_refresh_tokens rt`).
+		WithArgs(utils.HashToken("rotated-refresh")).
+		WillReturnRows(sqlmock.NewRows(refreshColumns).AddRow("s1", true, true, "u1", "User", "u1@example.com", true, "user"))
+	mock.ExpectExec(`UPDATE sessions\s+SET is_active = false`).
+		WithArgs("s1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err := repo.RefreshToken(context.Background(), &types.RefreshTokenRequest{RefreshToken: "rotated-refresh"})
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshTokenExpired(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM session_refresh_tokens rt`).
+		WithArgs(utils.HashToken("expired-refresh")).
+		WillReturnRows(sqlmock.NewRows(refreshColumns).AddRow("s1", false, false, "u1", "User", "u1@example.com", true, "user"))
+	// Token kedaluwarsa tidak merotasi apa pun, transaksi di-rollback
+	mock.ExpectRollback()
+
+	_, err := repo.RefreshToken(context.Background(), &types.RefreshTokenRequest{RefreshToken: "expired-refresh"})
+	assert.ErrorIs(t, err, ErrSessionExpired)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRefreshTokenUnknown(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM session_refresh_tokens rt`).
+		WillReturnRows(sqlmock.NewRows(refreshColumns))
+	mock.ExpectRollback()
+
+	_, err := repo.RefreshToken(context.Background(), &types.RefreshTokenRequest{RefreshToken: "unknown"})
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReissueSessionReplacesRefreshTokens(t *testing.T) {
+	repo, mock := newMockRepository(t)
+
+	mock.ExpectBegin()
+	// Token lama dihapus, bukan ditandai rotated, supaya login ulang tidak dianggap reuse
+	mock.ExpectExec(`DELETE FROM session_refresh_tokens WHERE session_id = \$1`).
+		WithArgs("s1").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`UPDATE sessions\s+SET access_token = \$1`).
+		WithArgs(utils.HashToken("access"), utils.HashToken("refresh"), "10.0.0.1", sqlmock.AnyArg(), sqlmock.AnyArg(), "s1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO session_refresh_tokens`).
+		WithArgs(utils.HashToken("refresh"), "s1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	session := &types.Session{SessionId: "s1", AccessToken: "access", RefreshToken: "refresh", IpAddress: "10.0.0.1"}
+	require.NoError(t, repo.ReissueSession(context.Background(), session))
+	assert.NotZero(t, session.ExpiresAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,339 @@
+package authrepository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/wafi04/chatting-app/services/shared/middleware"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+	"github.com/wafi04/chatting-app/services/shared/types"
+	"github.com/wafi04/chatting-app/services/shared/utils"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	LoginChallengeTokenType = "LOGIN_2FA"
+	loginChallengeTTL       = 5 * time.Minute
+	RecoveryCodeCount       = 10
+)
+
+var (
+	ErrTwoFactorNotEnrolled = errors.New("two-factor authentication is not enrolled")
+	ErrTwoFactorEnabled     = errors.New("two-factor authentication is already enabled")
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	ErrInvalidChallenge     = errors.New("invalid or expired login challenge")
+)
+
+type TOTPRecord struct {
+	Secret       string
+	Enabled      bool
+	LastUsedStep int64
+}
+
+func (r *AuthRepository) GetTOTP(ctx context.Context, userId string) (*TOTPRecord, error) {
+	var record TOTPRecord
+	err := r.DB.QueryRowContext(ctx, `
+        SELECT secret, enabled, last_used_step
+        FROM user_totp
+        WHERE user_id = $1
+    `, userId).Scan(&record.Secret, &record.Enabled, &record.LastUsedStep)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get totp: %w", err)
+	}
+
+	return &record, nil
+}
+
+func (r *AuthRepository) IsTwoFactorEnabled(ctx context.Context, userId string) (bool, error) {
+	record, err := r.GetTOTP(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+	return record != nil && record.Enabled, nil
+}
+
+// SaveTOTPSecret menyimpan secret baru yang belum aktif sampai dikonfirmasi
+func (r *AuthRepository) SaveTOTPSecret(ctx context.Context, userId, secret string) error {
+	result, err := r.DB.ExecContext(ctx, `
+        INSERT INTO user_totp (user_id, secret, enabled)
+        VALUES ($1, $2, false)
+        ON CONFLICT (user_id) DO UPDATE SET
+            secret = EXCLUDED.secret,
+            last_used_step = 0,
+            updated_at = NOW()
+        WHERE user_totp.enabled = false
+    `, userId, secret)
+	if err != nil {
+		return fmt.Errorf("failed to save totp secret: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTwoFactorEnabled
+	}
+
+	return nil
+}
+
+// UseTOTPStep mencatat step yang dipakai; step yang sama atau lebih lama ditolak (anti replay)
+func (r *AuthRepository) UseTOTPStep(ctx context.Context, userId string, step int64) (bool, error) {
+	result, err := r.DB.ExecContext(ctx, `
+        UPDATE user_totp
+        SET last_used_step = $2, updated_at = NOW()
+        WHERE user_id = $1 AND last_used_step < $2
+    `, userId, step)
+	if err != nil {
+		return false, fmt.Errorf("failed to update totp step: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected == 1, nil
+}
+
+// EnableTOTP mengaktifkan 2FA dan menyimpan hash recovery code baru
+func (r *AuthRepository) EnableTOTP(ctx context.Context, userId string, recoveryCodes []string) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE user_totp SET enabled = true, confirmed_at = NOW(), updated_at = NOW()
+        WHERE user_id = $1
+    `, userId)
+	if err != nil {
+		return fmt.Errorf("failed to enable totp: %w", err)
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userId, recoveryCodes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *AuthRepository) DisableTOTP(ctx context.Context, userId string) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userId); err != nil {
+		return fmt.Errorf("failed to disable totp: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userId); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *AuthRepository) ReplaceRecoveryCodes(ctx context.Context, userId string, recoveryCodes []string) error {
+	tx, err := r.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(ctx, tx, userId, recoveryCodes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx *sqlx.Tx, userId string, recoveryCodes []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, userId); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	hashes := make([]string, len(recoveryCodes))
+	for i, code := range recoveryCodes {
+		hashes[i] = utils.HashToken(utils.NormalizeRecoveryCode(code))
+	}
+
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO user_recovery_codes (user_id, code_hash)
+        SELECT $1, UNNEST($2::text[])
+    `, userId, pq.Array(hashes))
+	if err != nil {
+		return fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+
+	return nil
+}
+
+// UseRecoveryCode menandai recovery code terpakai; false jika kode tidak valid atau sudah dipakai
+func (r *AuthRepository) UseRecoveryCode(ctx context.Context, userId, code string) (bool, error) {
+	result, err := r.DB.ExecContext(ctx, `
+        UPDATE user_recovery_codes
+        SET used_at = NOW()
+        WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+    `, userId, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rowsAffected == 1, nil
+}
+
+func (r *AuthRepository) CountRecoveryCodes(ctx context.Context, userId string) (int, error) {
+	var count int
+	err := r.DB.QueryRowContext(ctx, `
+        SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = $1 AND used_at IS NULL
+    `, userId).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return count, nil
+}
+
+// CheckPassword dipakai untuk re-autentikasi sebelum aksi sensitif
+func (r *AuthRepository) CheckPassword(ctx context.Context, userId, password string) error {
+	var hash string
+	err := r.DB.QueryRowContext(ctx, `SELECT password_hash FROM users WHERE user_id = $1`, userId).Scan(&hash)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrInvalidCredentials
+		}
+		return fmt.Errorf("failed to get password: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return ErrInvalidCredentials
+	}
+	return nil
+}
+
+// CreateLoginChallenge dipanggil setelah password benar untuk user dengan 2FA aktif.
+// Sesi baru dibuat setelah challenge diverifikasi.
+func (r *AuthRepository) CreateLoginChallenge(ctx context.Context, userId string) (string, time.Time, error) {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := time.Now().Add(loginChallengeTTL)
+
+	_, err = r.DB.ExecContext(ctx, `
+        INSERT INTO verification_tokens (token, user_id, token_type, expires_at)
+        VALUES ($1, $2, $3, $4)
+    `, utils.HashToken(token), userId, LoginChallengeTokenType, expiresAt)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to create login challenge: %w", err)
+	}
+
+	return token, expiresAt, nil
+}
+
+// VerifyLoginChallenge memverifikasi kode TOTP atau recovery code untuk challenge login.
+// Kode salah dihitung ke lockout akun/IP yang sama dengan login password.
+func (r *AuthRepository) VerifyLoginChallenge(ctx context.Context, req *types.TwoFactorLoginRequest) (*types.UserInfo, error) {
+	challengeHash := utils.HashToken(req.ChallengeToken)
+
+	var user types.UserInfo
+	err := r.DB.QueryRowContext(ctx, `
+        SELECT u.user_id, u.name, u.email, COALESCE(u.is_email_verified, false), u.role
+        FROM verification_tokens vt
+        JOIN users u ON u.user_id = vt.user_id
+        WHERE vt.token = $1
+        AND vt.token_type = $2
+        AND vt.is_used = false
+        AND vt.expires_at > NOW()
+        AND (u.is_active = true OR `+reactivatableUser+`)
+    `, challengeHash, LoginChallengeTokenType).Scan(&user.UserId, &user.Name, &user.Email, &user.IsEmailVerified, &user.Role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInvalidChallenge
+		}
+		return nil, fmt.Errorf("failed to get login challenge: %w", err)
+	}
+
+	keys := loginLockKeys(accountLockKey(user.UserId, ""), req.IpAddress)
+	if err := r.checkLoginLock(ctx, keys); err != nil {
+		return nil, err
+	}
+
+	ok, err := r.verifySecondFactor(ctx, user.UserId, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		if err := r.recordFailedLogin(ctx, keys, user.Email, user.UserId, req.IpAddress, req.DeviceInfo, "bad_2fa"); err != nil {
+			r.logger.Log(logger.ErrorLevel, "Failed to record failed 2fa: %v", err)
+		}
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	// Challenge hanya boleh dipakai sekali
+	result, err := r.DB.ExecContext(ctx, `
+        UPDATE verification_tokens SET is_used = true WHERE token = $1 AND is_used = false
+    `, challengeHash)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume login challenge: %w", err)
+	}
+	if rowsAffected, _ := result.RowsAffected(); rowsAffected == 0 {
+		return nil, ErrInvalidChallenge
+	}
+
+	return &user, nil
+}
+
+// verifySecondFactor menerima kode TOTP 6 digit atau recovery code
+func (r *AuthRepository) verifySecondFactor(ctx context.Context, userId, code string) (bool, error) {
+	record, err := r.GetTOTP(ctx, userId)
+	if err != nil {
+		return false, err
+	}
+	if record == nil || !record.Enabled {
+		return false, ErrTwoFactorNotEnrolled
+	}
+
+	if step, ok := utils.ValidateTOTP(record.Secret, code, time.Now()); ok {
+		return r.UseTOTPStep(ctx, userId, step)
+	}
+
+	return r.UseRecoveryCode(ctx, userId, code)
+}
+
+// VerifySecondFactor dipakai untuk aksi sensitif pada akun yang sudah login (disable, regenerate).
+// Kode salah dihitung ke lockout yang sama dengan VerifyLoginChallenge, supaya sesi yang
+// dicuri tidak bisa menebak kode TOTP.
+func (r *AuthRepository) VerifySecondFactor(ctx context.Context, userId, code string) error {
+	client := middleware.ClientInfoFromContext(ctx)
+	keys := loginLockKeys(accountLockKey(userId, ""), client.IpAddress)
+	if err := r.checkLoginLock(ctx, keys); err != nil {
+		return err
+	}
+
+	ok, err := r.verifySecondFactor(ctx, userId, code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		if err := r.recordFailedLogin(ctx, keys, userId, userId, client.IpAddress, client.UserAgent, "bad_2fa"); err != nil {
+			r.logger.Log(logger.ErrorLevel, "Failed to record failed 2fa: %v", err)
+		}
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package authservice
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+var ErrChangeOwnRole = errors.New("cannot change your own role")
+
+func (s *AuthService) ListUsers(ctx context.Context, req *types.ListUsersRequest) (*types.ListUsersResponse, error) {
+	return s.authRepo.ListUsers(ctx, req)
+}
+
+// UpdateUserRole dipakai admin untuk mengubah role user lain
+func (s *AuthService) UpdateUserRole(ctx context.Context, actorId, userId, role string) error {
+	if actorId == userId {
+		return ErrChangeOwnRole
+	}
+	return s.authRepo.SetUserRole(ctx, userId, role)
+}
+
+// RevokeUserSessions mencabut semua sesi user, mengembalikan id sesi yang dicabut
+func (s *AuthService) RevokeUserSessions(ctx context.Context, userId string) (*types.RevokeOtherSessionsResponse, error) {
+	revoked, err := s.authRepo.RevokeOtherSessions(ctx, userId, "")
+	if err != nil {
+		return nil, err
+	}
+	// IP/User-Agent request milik admin, jadi tidak dicatat di log user
+	s.recordEventFrom(ctx, userId, types.SecurityEventSessionsRevoked, "", "", map[string]string{
+		"count": strconv.Itoa(len(revoked)),
+		"by":    "admin",
+	})
+
+	return &types.RevokeOtherSessionsResponse{
+		Success: true,
+		Revoked: revoked,
+	}, nil
+}
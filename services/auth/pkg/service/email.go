@@ -0,0 +1,74 @@
+package authservice
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"time"
+
+	"github.com/wafi04/chatting-app/services/shared/pkg/mailer"
+	"github.com/wafi04/chatting-app/services/shared/types"
+	"github.com/wafi04/chatting-app/services/shared/utils"
+)
+
+func verificationEmail(to, name string, verification *types.ResendVerificationResponse) *mailer.Message {
+	validFor := time.Until(time.Unix(verification.ExpiresAt, 0)).Round(time.Minute)
+
+	return &mailer.Message{
+		To:      to,
+		Subject: "Verify your email address",
+		TextBody: fmt.Sprintf(
+			"Hi %s,\n\nYour verification code is %s.\nThe code expires in %s.\n\nIf you did not create an account, you can ignore this email.\n",
+			name, verification.VerifyCode, validFor,
+		),
+		HTMLBody: fmt.Sprintf(
+			"<p>Hi %s,</p><p>Your verification code is <strong>%s</strong>.<br>The code expires in %s.</p><p>If you did not create an account, you can ignore this email.</p>",
+			html.EscapeString(name), verification.VerifyCode, validFor,
+		),
+	}
+}
+
+func passwordResetEmail(to, name, resetURL string, reset *types.RequestPasswordResetResponse) *mailer.Message {
+	validFor := time.Until(time.Unix(reset.ExpiresAt, 0)).Round(time.Minute)
+
+	instruction := fmt.Sprintf("Use this token to reset your password: %s", reset.ResetToken)
+	htmlInstruction := fmt.Sprintf("Use this token to reset your password: <code>%s</code>", reset.ResetToken)
+	if resetURL != "" {
+		link := resetURL + "?token=" + url.QueryEscape(reset.ResetToken)
+		instruction = fmt.Sprintf("Open this link to reset your password: %s", link)
+		htmlInstruction = fmt.Sprintf(`<a href="%s">Reset your password</a>`, html.EscapeString(link))
+	}
+
+	return &mailer.Message{
+		To:      to,
+		Subject: "Reset your password",
+		TextBody: fmt.Sprintf(
+			"Hi %s,\n\n%s\nThe link expires in %s and can only be used once.\n\nIf you did not request a password reset, you can ignore this email.\n",
+			name, instruction, validFor,
+		),
+		HTMLBody: fmt.Sprintf(
+			"<p>Hi %s,</p><p>%s<br>The link expires in %s and can only be used once.</p><p>If you did not request a password reset, you can ignore this email.</p>",
+			html.EscapeString(name), htmlInstruction, validFor,
+		),
+	}
+}
+
+func newDeviceEmail(to, name string, ua utils.UserAgent, ipAddress string, at time.Time) *mailer.Message {
+	when := at.UTC().Format("2 Jan 2006 15:04 MST")
+	if ipAddress == "" {
+		ipAddress = "unknown"
+	}
+
+	return &mailer.Message{
+		To:      to,
+		Subject: "New sign-in to your account",
+		TextBody: fmt.Sprintf(
+			"Hi %s,\n\nYour account was just signed in from a new device.\n\nDevice: %s (%s)\nIP address: %s\nTime: %s\n\nIf this was you, no action is needed. If not, change your password and sign out of all other sessions.\n",
+			name, ua.String(), ua.Device, ipAddress, when,
+		),
+		HTMLBody: fmt.Sprintf(
+			"<p>Hi %s,</p><p>Your account was just signed in from a new device.</p><p>Device: %s (%s)<br>IP address: %s<br>Time: %s</p><p>If this was you, no action is needed. If not, change your password and sign out of all other sessions.</p>",
+			html.EscapeString(name), html.EscapeString(ua.String()), html.EscapeString(ua.Device), html.EscapeString(ipAddress), when,
+		),
+	}
+}
@@ -0,0 +1,88 @@
+package authservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/wafi04/chatting-app/services/auth/pkg/oidc"
+	authrepository "github.com/wafi04/chatting-app/services/auth/pkg/repository"
+	"github.com/wafi04/chatting-app/services/shared/types"
+	"github.com/wafi04/chatting-app/services/shared/utils"
+)
+
+var ErrUnknownOIDCProvider = errors.New("unknown oidc provider")
+
+func (s *AuthService) OIDCLoginRedirect() string {
+	return s.oidcRedirect
+}
+
+// BeginOIDCLogin menyimpan state, nonce dan PKCE verifier lalu mengembalikan URL authorize provider
+func (s *AuthService) BeginOIDCLogin(ctx context.Context, providerName string) (authURL, state string, err error) {
+	provider, ok := s.oidcProviders[providerName]
+	if !ok {
+		return "", "", ErrUnknownOIDCProvider
+	}
+
+	state, err = utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := oidc.NewCodeVerifier()
+	if err != nil {
+		return "", "", err
+	}
+
+	err = s.authRepo.CreateOIDCState(ctx, state, &authrepository.OIDCState{
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err = provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		return "", "", err
+	}
+	return authURL, state, nil
+}
+
+// CompleteOIDCLogin memproses callback provider: validasi state, tukar code, tautkan
+// identitas ke user lalu membuat sesi (atau challenge 2FA)
+func (s *AuthService) CompleteOIDCLogin(ctx context.Context, providerName, code, state string, req *types.LoginRequest) (*types.LoginResponse, error) {
+	provider, ok := s.oidcProviders[providerName]
+	if !ok {
+		return nil, ErrUnknownOIDCProvider
+	}
+
+	saved, err := s.authRepo.ConsumeOIDCState(ctx, state, providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, err := provider.Exchange(ctx, code, saved.CodeVerifier, saved.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("failed to complete oidc login: %w", err)
+	}
+
+	user, err := s.authRepo.ResolveExternalIdentity(ctx, &authrepository.ExternalIdentity{
+		Provider:      providerName,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: claims.EmailVerified,
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req.Identifier = user.Email
+	return s.startSession(ctx, user, req, "oidc:"+providerName)
+}
@@ -0,0 +1,72 @@
+package authservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+const (
+	defaultAccessTokenDays = 30
+	maxAccessTokenDays     = 365
+)
+
+var ErrInvalidAccessTokenRequest = errors.New("invalid access token request")
+
+func (s *AuthService) CreateAccessToken(ctx context.Context, req *types.CreateAccessTokenRequest) (*types.CreateAccessTokenResponse, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 100 {
+		return nil, fmt.Errorf("%w: name is required and must be at most 100 characters", ErrInvalidAccessTokenRequest)
+	}
+
+	if len(req.Scopes) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAccessTokenRequest)
+	}
+	seen := make(map[string]bool, len(req.Scopes))
+	scopes := make([]string, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !types.IsValidScope(scope) {
+			return nil, fmt.Errorf("%w: unknown scope %q", ErrInvalidAccessTokenRequest, scope)
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	req.Scopes = scopes
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = defaultAccessTokenDays
+	}
+	if days < 0 || days > maxAccessTokenDays {
+		return nil, fmt.Errorf("%w: expires_in_days must be between 1 and %d", ErrInvalidAccessTokenRequest, maxAccessTokenDays)
+	}
+
+	resp, err := s.authRepo.CreateAccessToken(ctx, req, time.Now().AddDate(0, 0, days))
+	if err != nil {
+		return nil, err
+	}
+	s.recordEvent(ctx, req.UserId, types.SecurityEventAccessTokenCreated, map[string]string{
+		"token_id": resp.Id,
+		"name":     resp.Name,
+		"scopes":   strings.Join(resp.Scopes, " "),
+	})
+	return resp, nil
+}
+
+func (s *AuthService) ListAccessTokens(ctx context.Context, userId string) ([]*types.AccessToken, error) {
+	return s.authRepo.ListAccessTokens(ctx, userId)
+}
+
+func (s *AuthService) RevokeAccessToken(ctx context.Context, userId, tokenId string) error {
+	if err := s.authRepo.RevokeAccessToken(ctx, userId, tokenId); err != nil {
+		return err
+	}
+	s.recordEvent(ctx, userId, types.SecurityEventAccessTokenRevoked, map[string]string{"token_id": tokenId})
+	return nil
+}
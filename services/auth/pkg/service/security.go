@@ -0,0 +1,69 @@
+package authservice
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/wafi04/chatting-app/services/shared/middleware"
+	"github.com/wafi04/chatting-app/services/shared/types"
+	"github.com/wafi04/chatting-app/services/shared/utils"
+)
+
+func (s *AuthService) ListSecurityEvents(ctx context.Context, req *types.ListSecurityEventsRequest) (*types.ListSecurityEventsResponse, error) {
+	return s.authRepo.ListSecurityEvents(ctx, req)
+}
+
+// recordEvent mencatat event ke security log. Best-effort: kegagalan hanya di-log
+// supaya aksi user tidak ikut gagal.
+func (s *AuthService) recordEvent(ctx context.Context, userId, eventType string, metadata map[string]string) {
+	client := middleware.ClientInfoFromContext(ctx)
+	s.recordEventFrom(ctx, userId, eventType, client.IpAddress, client.UserAgent, metadata)
+}
+
+func (s *AuthService) recordEventFrom(ctx context.Context, userId, eventType, ipAddress, userAgent string, metadata map[string]string) {
+	ua := utils.ParseUserAgent(userAgent)
+	err := s.authRepo.RecordSecurityEvent(ctx, &types.SecurityEvent{
+		UserId:    userId,
+		Type:      eventType,
+		IpAddress: ipAddress,
+		UserAgent: userAgent,
+		Device:    ua.Device,
+		Browser:   ua.Browser,
+		OS:        ua.OSName(),
+		Metadata:  metadata,
+	})
+	if err != nil {
+		log.Printf("Failed to record %s event for %s: %v", eventType, userId, err)
+	}
+}
+
+// onLogin dipanggil setelah sesi berhasil dibuat: catat login dan kirim email jika
+// perangkatnya belum pernah dipakai user ini
+func (s *AuthService) onLogin(ctx context.Context, user *types.UserInfo, req *types.LoginRequest, method string) {
+	metadata := map[string]string{"method": method}
+	s.recordEventFrom(ctx, user.UserId, types.SecurityEventLogin, req.IpAddress, req.DeviceInfo, metadata)
+
+	ua := utils.ParseUserAgent(req.DeviceInfo)
+	isNew, firstDevice, err := s.authRepo.TouchKnownDevice(ctx, user.UserId, ua.Fingerprint(), req.IpAddress)
+	if err != nil {
+		log.Printf("Failed to check known device for %s: %v", user.UserId, err)
+		return
+	}
+	if !isNew || firstDevice {
+		return
+	}
+
+	s.recordEventFrom(ctx, user.UserId, types.SecurityEventNewDevice, req.IpAddress, req.DeviceInfo, metadata)
+	if user.Email == "" {
+		return
+	}
+	at := time.Now()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := s.mailer.Send(ctx, newDeviceEmail(user.Email, user.Name, ua, req.IpAddress, at)); err != nil {
+			log.Printf("Failed to send new device email to %s: %v", user.UserId, err)
+		}
+	}()
+}
@@ -2,21 +2,43 @@ package authservice
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/wafi04/chatting-app/config/env"
+	"github.com/wafi04/chatting-app/services/auth/pkg/oidc"
 	authrepository "github.com/wafi04/chatting-app/services/auth/pkg/repository"
+	"github.com/wafi04/chatting-app/services/shared/pkg/mailer"
 	"github.com/wafi04/chatting-app/services/shared/types"
+	"github.com/wafi04/chatting-app/services/shared/utils"
 )
 
 type AuthService struct {
 	authRepo *authrepository.AuthRepository
+	mailer   mailer.Mailer
+	// URL halaman reset password di frontend, token ditambahkan sebagai query ?token=
+	resetURL      string
+	oidcProviders map[string]*oidc.Provider
+	// Halaman frontend tujuan setelah login OIDC; kosong = callback membalas JSON
+	oidcRedirect string
 }
 
-func NewAuthService(authRepo *authrepository.AuthRepository) *AuthService {
+func NewAuthService(authRepo *authrepository.AuthRepository, mail mailer.Mailer, providers []*oidc.Provider) *AuthService {
+	oidcProviders := make(map[string]*oidc.Provider, len(providers))
+	for _, p := range providers {
+		oidcProviders[p.Name()] = p
+	}
+
 	return &AuthService{
-		authRepo: authRepo,
+		authRepo:      authRepo,
+		mailer:        mail,
+		resetURL:      env.LoadEnv("PASSWORD_RESET_URL"),
+		oidcProviders: oidcProviders,
+		oidcRedirect:  env.LoadEnv("OIDC_LOGIN_REDIRECT"),
 	}
 }
 
@@ -28,48 +50,98 @@ func (s *AuthService) CreateUser(ctx context.Context, req *types.CreateUserReque
 		return nil, err
 	}
 
+	// Registrasi tetap berhasil walau email gagal terkirim, user bisa minta kirim ulang
+	var verificationToken string
+	verification, err := s.authRepo.CreateEmailVerification(ctx, user.UserId)
+	if err != nil {
+		log.Printf("Failed to create email verification for %s: %v", user.UserId, err)
+	} else {
+		verificationToken = verification.VerificationToken
+		if err := s.mailer.Send(ctx, verificationEmail(user.Email, user.Name, verification)); err != nil {
+			log.Printf("Failed to send verification email to %s: %v", user.UserId, err)
+		}
+	}
+
 	return &types.CreateUserResponse{
-		UserId:    user.UserId,
-		Name:      user.Name,
-		Email:     user.Email,
-		CreatedAt: time.Now().Unix(),
+		UserId:            user.UserId,
+		Name:              user.Name,
+		Email:             user.Email,
+		CreatedAt:         time.Now().Unix(),
+		VerificationToken: verificationToken,
 	}, nil
 }
 
 func (s *AuthService) Login(ctx context.Context, req *types.LoginRequest) (*types.LoginResponse, error) {
-	log.Printf("Received Login request for user: %v", req)
+	log.Printf("Received Login request for user: %s", req.Identifier)
 
-	user, err := s.authRepo.Login(ctx, req)
+	user, err := s.authRepo.VerifyCredentials(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	return user, nil
+	return s.startSession(ctx, user, req, "password")
+}
+
+// startSession membuat sesi, atau challenge 2FA jika user mengaktifkan 2FA.
+// method dicatat di security log (password, oidc:<provider>).
+func (s *AuthService) startSession(ctx context.Context, user *types.UserInfo, req *types.LoginRequest, method string) (*types.LoginResponse, error) {
+	enabled, err := s.authRepo.IsTwoFactorEnabled(ctx, user.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		token, expiresAt, err := s.authRepo.CreateLoginChallenge(ctx, user.UserId)
+		if err != nil {
+			return nil, err
+		}
+		return &types.LoginResponse{
+			UserId:             user.UserId,
+			TwoFactorRequired:  true,
+			ChallengeToken:     token,
+			ChallengeExpiresAt: expiresAt.Unix(),
+		}, nil
+	}
+
+	resp, err := s.authRepo.CreateLoginSession(ctx, user, req)
+	if err != nil {
+		return nil, err
+	}
+	s.onLogin(ctx, user, req, method)
+	return resp, nil
 }
 func (s *AuthService) VerifyEmail(ctx context.Context, req *types.VerifyEmailRequest) (*types.VerifyEmailResponse, error) {
-	log.Printf("Received verify email request for user: %v", req)
+	// Token dan kode verifikasi tidak boleh masuk log
+	log.Printf("Received verify email request")
 
-	user, err := s.authRepo.VerifyEmail(ctx, req)
+	verified, err := s.authRepo.VerifyEmail(ctx, req)
 	if err != nil {
-		log.Fatalf("Failed  to verifiy email  :%v ", err)
+		log.Printf("Failed to verify email: %v", err)
 		return nil, err
 	}
 
-	return &types.VerifyEmailResponse{
-		Success: true,
-		UserId:  user.UserId,
-	}, nil
+	return verified, nil
 }
 func (s *AuthService) ResendVerification(ctx context.Context, req *types.ResendVerificationRequest) (*types.ResendVerificationResponse, error) {
-	log.Printf("Received verify email request for user: %v", req)
+	log.Printf("Received resend verification request for user: %s", req.UserId)
 
-	user, err := s.authRepo.ResendVerification(ctx, req)
+	user, err := s.authRepo.GetUser(ctx, &types.GetUserRequest{UserId: req.UserId})
 	if err != nil {
-		log.Fatalf("Failed  to verifiy email  :%v ", err)
 		return nil, err
 	}
 
-	return user, nil
+	verification, err := s.authRepo.ResendVerification(ctx, req)
+	if err != nil {
+		log.Printf("Failed to resend verification: %v", err)
+		return nil, err
+	}
+
+	if err := s.mailer.Send(ctx, verificationEmail(user.Email, user.Name, verification)); err != nil {
+		return nil, fmt.Errorf("failed to send verification email: %w", err)
+	}
+
+	// Kode hanya boleh sampai ke user lewat email
+	verification.VerifyCode = ""
+	return verification, nil
 }
 func (s *AuthService) GetUser(ctx context.Context, req *types.GetUserRequest) (*types.UserInfo, error) {
 
@@ -95,15 +167,27 @@ func (s *AuthService) RevokeSession(ctx context.Context, req *types.RevokeSessio
 	if err != nil {
 		return nil, err
 	}
+	s.recordEvent(ctx, req.UserId, types.SecurityEventSessionRevoked, map[string]string{"session_id": req.SessionId})
 	return user, nil
 }
 
-func (s *AuthService) RefreshToken(ctx context.Context, req *types.RefreshTokenRequest) (*types.RefreshTokenResponse, error) {
-	log.Printf("Received verify email request for user: %v", req)
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, userId, currentSessionId string) (*types.RevokeOtherSessionsResponse, error) {
+	revoked, err := s.authRepo.RevokeOtherSessions(ctx, userId, currentSessionId)
+	if err != nil {
+		return nil, err
+	}
+	s.recordEvent(ctx, userId, types.SecurityEventSessionsRevoked, map[string]string{"count": strconv.Itoa(len(revoked))})
+
+	return &types.RevokeOtherSessionsResponse{
+		Success: true,
+		Revoked: revoked,
+	}, nil
+}
 
+func (s *AuthService) RefreshToken(ctx context.Context, req *types.RefreshTokenRequest) (*types.RefreshTokenResponse, error) {
 	refresh, err := s.authRepo.RefreshToken(ctx, req)
 	if err != nil {
-		log.Fatalf("Failed  to refresh token  :%v ", err)
+		log.Printf("Failed to refresh token: %v", err)
 		return nil, err
 	}
 
@@ -111,13 +195,15 @@ func (s *AuthService) RefreshToken(ctx context.Context, req *types.RefreshTokenR
 }
 
 func (s *AuthService) ListSessions(ctx context.Context, req *types.ListSessionsRequest) (*types.ListSessionsResponse, error) {
-	log.Printf("Received verify email request for user: %v", req)
-
 	ListSessions, err := s.authRepo.ListSessions(ctx, req)
 	if err != nil {
-		log.Fatalf("Failed  to ListSessions token  :%v ", err)
+		log.Printf("Failed to list sessions: %v", err)
 		return nil, err
 	}
+	for _, session := range ListSessions.Sessions {
+		ua := utils.ParseUserAgent(session.DeviceInfo)
+		session.Device, session.Browser, session.OS = ua.Device, ua.Browser, ua.OSName()
+	}
 
 	return ListSessions, nil
 }
@@ -127,8 +213,71 @@ func (s *AuthService) GetSession(ctx context.Context, req *types.GetSessionReque
 
 	GetSession, err := s.authRepo.GetSession(ctx, req)
 	if err != nil {
-		log.Fatalf("Failed  to GetSession token  :%v ", err)
+		log.Printf("Failed to get session: %v", err)
 		return nil, err
 	}
 	return GetSession, nil
 }
+
+// RequestPasswordReset selalu mengembalikan response yang sama, baik email terdaftar
+// maupun tidak, dan email dikirim di background agar waktu respons juga tidak membocorkannya.
+func (s *AuthService) RequestPasswordReset(ctx context.Context, req *types.RequestPasswordResetRequest) (*types.RequestPasswordResetResponse, error) {
+	resp := &types.RequestPasswordResetResponse{Success: true}
+
+	if err := s.authRepo.CheckPasswordResetIPThrottle(ctx); err != nil {
+		return nil, err
+	}
+
+	user, err := s.authRepo.GetUserByEmail(ctx, strings.TrimSpace(req.Email))
+	if err != nil {
+		if errors.Is(err, authrepository.ErrUserNotFound) {
+			return resp, nil
+		}
+		return nil, err
+	}
+
+	reset, err := s.authRepo.CreatePasswordReset(ctx, user.UserId)
+	if err != nil {
+		// Throttle per akun tidak dilaporkan ke client supaya tidak membocorkan email terdaftar
+		var throttled *authrepository.ResendThrottledError
+		if errors.As(err, &throttled) {
+			log.Printf("Password reset for %s throttled: %v", user.UserId, err)
+			return resp, nil
+		}
+		return nil, err
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := s.mailer.Send(ctx, passwordResetEmail(user.Email, user.Name, s.resetURL, reset)); err != nil {
+			log.Printf("Failed to send password reset email to %s: %v", user.UserId, err)
+		}
+	}()
+
+	return resp, nil
+}
+
+func (s *AuthService) ResetPassword(ctx context.Context, req *types.ResetPasswordRequest) (*types.ResetPasswordResponse, error) {
+	if err := ValidatePassword(req.NewPassword); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.authRepo.ResetPassword(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.recordEvent(ctx, resp.UserId, types.SecurityEventPasswordChanged, map[string]string{"method": "reset_token"})
+	return resp, nil
+}
+
+const minPasswordLength = 8
+
+var ErrWeakPassword = fmt.Errorf("password must be at least %d characters", minPasswordLength)
+
+func ValidatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return ErrWeakPassword
+	}
+	return nil
+}
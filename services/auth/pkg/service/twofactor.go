@@ -0,0 +1,156 @@
+package authservice
+
+import (
+	"context"
+	"time"
+
+	"github.com/wafi04/chatting-app/config/env"
+	authrepository "github.com/wafi04/chatting-app/services/auth/pkg/repository"
+	"github.com/wafi04/chatting-app/services/shared/types"
+	"github.com/wafi04/chatting-app/services/shared/utils"
+)
+
+func totpIssuer() string {
+	if issuer := env.LoadEnv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return "Chatting App"
+}
+
+func (s *AuthService) TwoFactorStatus(ctx context.Context, userId string) (*types.TwoFactorStatus, error) {
+	enabled, err := s.authRepo.IsTwoFactorEnabled(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &types.TwoFactorStatus{Enabled: enabled}
+	if enabled {
+		status.RemainingRecoveryCodes, err = s.authRepo.CountRecoveryCodes(ctx, userId)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return status, nil
+}
+
+// EnrollTwoFactor membuat secret baru; 2FA baru aktif setelah ConfirmTwoFactor
+func (s *AuthService) EnrollTwoFactor(ctx context.Context, userId string) (*types.TwoFactorEnrollResponse, error) {
+	user, err := s.authRepo.GetUser(ctx, &types.GetUserRequest{UserId: userId})
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authRepo.SaveTOTPSecret(ctx, userId, secret); err != nil {
+		return nil, err
+	}
+
+	return &types.TwoFactorEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(totpIssuer(), user.Email, secret),
+	}, nil
+}
+
+func (s *AuthService) ConfirmTwoFactor(ctx context.Context, req *types.TwoFactorConfirmRequest) (*types.RecoveryCodesResponse, error) {
+	record, err := s.authRepo.GetTOTP(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, authrepository.ErrTwoFactorNotEnrolled
+	}
+	if record.Enabled {
+		return nil, authrepository.ErrTwoFactorEnabled
+	}
+
+	step, ok := utils.ValidateTOTP(record.Secret, req.Code, time.Now())
+	if !ok {
+		return nil, authrepository.ErrInvalidTwoFactorCode
+	}
+	if _, err := s.authRepo.UseTOTPStep(ctx, req.UserId, step); err != nil {
+		return nil, err
+	}
+
+	codes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authRepo.EnableTOTP(ctx, req.UserId, codes); err != nil {
+		return nil, err
+	}
+	s.recordEvent(ctx, req.UserId, types.SecurityEventTwoFactorEnabled, nil)
+
+	return &types.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// DisableTwoFactor butuh password dan kode 2FA (TOTP atau recovery code)
+func (s *AuthService) DisableTwoFactor(ctx context.Context, req *types.TwoFactorDisableRequest) error {
+	if err := s.authRepo.CheckPassword(ctx, req.UserId, req.Password); err != nil {
+		return err
+	}
+	if err := s.authRepo.VerifySecondFactor(ctx, req.UserId, req.Code); err != nil {
+		return err
+	}
+
+	if err := s.authRepo.DisableTOTP(ctx, req.UserId); err != nil {
+		return err
+	}
+	s.recordEvent(ctx, req.UserId, types.SecurityEventTwoFactorDisabled, nil)
+	return nil
+}
+
+// RegenerateRecoveryCodes butuh password dan kode 2FA; recovery code lama tidak berlaku lagi
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, req *types.TwoFactorRegenerateRequest) (*types.RecoveryCodesResponse, error) {
+	if err := s.authRepo.CheckPassword(ctx, req.UserId, req.Password); err != nil {
+		return nil, err
+	}
+	if err := s.authRepo.VerifySecondFactor(ctx, req.UserId, req.Code); err != nil {
+		return nil, err
+	}
+
+	codes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authRepo.ReplaceRecoveryCodes(ctx, req.UserId, codes); err != nil {
+		return nil, err
+	}
+	s.recordEvent(ctx, req.UserId, types.SecurityEventRecoveryCodesRegenerate, nil)
+
+	return &types.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// CompleteTwoFactorLogin memverifikasi challenge dari Login lalu membuat sesi
+func (s *AuthService) CompleteTwoFactorLogin(ctx context.Context, req *types.TwoFactorLoginRequest) (*types.LoginResponse, error) {
+	user, err := s.authRepo.VerifyLoginChallenge(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	loginReq := &types.LoginRequest{
+		Identifier: user.Email,
+		DeviceInfo: req.DeviceInfo,
+		IpAddress:  req.IpAddress,
+	}
+	resp, err := s.authRepo.CreateLoginSession(ctx, user, loginReq)
+	if err != nil {
+		return nil, err
+	}
+	s.onLogin(ctx, user, loginReq, "2fa")
+	return resp, nil
+}
+
+func generateRecoveryCodes() ([]string, error) {
+	codes := make([]string, authrepository.RecoveryCodeCount)
+	for i := range codes {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes[i] = code
+	}
+	return codes, nil
+}
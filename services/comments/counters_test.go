@@ -0,0 +1,113 @@
+package comments_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authrepository "github.com/wafi04/chatting-app/services/auth/pkg/repository"
+	"github.com/wafi04/chatting-app/services/comments"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+func newMockCommentRepository(t *testing.T) (*comments.CommentRepository, sqlmock.Sqlmock) {
+	mockDB, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { mockDB.Close() })
+
+	db := sqlx.NewDb(mockDB, "sqlmock")
+	return comments.NewCommentRepository(db, authrepository.NewUserRepository(db)), mock
+}
+
+func TestCreateReplyIncrementsCounters(t *testing.T) {
+	repo, mock := newMockCommentRepository(t)
+	now := time.Now()
+	parentID := "C1"
+
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM posts WHERE id = \$1\)`).
+		WithArgs("P1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM comments WHERE id = \$1\), depth`).
+		WithArgs(parentID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists", "depth"}).AddRow(true, 0))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO comments`).
+		WithArgs(sqlmock.AnyArg(), "u1", "P1", "hello", 1, sqlmock.AnyArg(), parentID).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "post_id", "content", "depth", "created_at", "parent_comment_id"}).
+			AddRow("C2", "u1", "P1", "hello", 1, now, parentID))
+	mock.ExpectExec(`UPDATE posts SET comment_count = comment_count \+ 1 WHERE id = \$1`).
+		WithArgs("P1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE comments SET reply_count = reply_count \+ 1 WHERE id = \$1`).
+		WithArgs(parentID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`FROM users\s+WHERE user_id = \$1`).
+		WithArgs("u1").
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "name", "email", "picture", "is_active", "is_email_verified", "created_at", "updated_at", "last_login_at", "role"}).
+			AddRow("u1", "User", "u1@example.com", nil, true, true, now, now, now, "user"))
+
+	comment, err := repo.CreateComment(context.Background(), &types.CreateComment{
+		PostID:   "P1",
+		UserID:   "u1",
+		Content:  "hello",
+		ParentID: &parentID,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "C2", comment.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateCommentRollsBackWhenCounterFails(t *testing.T) {
+	repo, mock := newMockCommentRepository(t)
+
+	mock.ExpectQuery(`SELECT EXISTS\(SELECT 1 FROM posts WHERE id = \$1\)`).
+		WithArgs("P1").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO comments`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "post_id", "content", "depth", "created_at", "parent_comment_id"}).
+			AddRow("C2", "u1", "P1", "hello", 0, time.Now(), nil))
+	mock.ExpectExec(`UPDATE posts SET comment_count = comment_count \+ 1`).
+		WithArgs("P1").
+		WillReturnError(errors.New("connection reset"))
+	// Comment tidak boleh tersimpan tanpa counter-nya
+	mock.ExpectRollback()
+
+	_, err := repo.CreateComment(context.Background(), &types.CreateComment{
+		PostID:  "P1",
+		UserID:  "u1",
+		Content: "hello",
+	})
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteCommentTreeDecrementsCounters(t *testing.T) {
+	repo, mock := newMockCommentRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT post_id, parent_comment_id, user_id FROM comments WHERE id = \$1`).
+		WithArgs("C2").
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "parent_comment_id", "user_id"}).AddRow("P1", "C1", "u1"))
+	mock.ExpectExec(`WITH RECURSIVE comment_tree`).
+		WithArgs("C2").
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`UPDATE posts SET comment_count = GREATEST\(comment_count - \$1, 0\) WHERE id = \$2`).
+		WithArgs(int64(3), "P1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE comments SET reply_count = GREATEST\(reply_count - 1, 0\) WHERE id = \$1`).
+		WithArgs("C1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	resp, err := repo.DeleteComment(context.Background(), &types.DeleteComment{CommentID: "C2", DeleteChildren: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), resp.Count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,6 +1,7 @@
 package comments
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
@@ -40,7 +41,7 @@ func (h *CommentHandler) HandleCreateComment(c *gin.Context) {
 		return
 	}
 
-	data, err := h.srv.CreateComment(c, &types.CreateComment{
+	data, err := h.srv.CreateComment(c.Request.Context(), &types.CreateComment{
 		UserID:   user.UserId,
 		PostID:   req.PostID,
 		Content:  req.Content,
@@ -89,7 +90,22 @@ func (h *CommentHandler) HandleGetComments(c *gin.Context) {
 	response.SendSuccessResponse(c, http.StatusOK, "Get Comment Successfullt", data)
 }
 
+// HandleDeleteOwnComment menghapus comment milik user yang sedang login
+func (h *CommentHandler) HandleDeleteOwnComment(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+	h.deleteComment(c, user.UserId)
+}
+
+// HandleDeleteCategory menghapus comment siapa pun; hanya dipasang di route moderasi
 func (h *CommentHandler) HandleDeleteCategory(c *gin.Context) {
+	h.deleteComment(c, "")
+}
+
+func (h *CommentHandler) deleteComment(c *gin.Context, ownerID string) {
 	id := c.Param("id")
 
 	// Alternative ways to get parameters:
@@ -105,11 +121,14 @@ func (h *CommentHandler) HandleDeleteCategory(c *gin.Context) {
 	updateReq := &types.DeleteComment{
 		CommentID:      id,
 		DeleteChildren: true,
+		OwnerID:        ownerID,
 	}
 
 	category, err := h.srv.DeleteComment(c, updateReq)
 	if err != nil {
 		switch {
+		case errors.Is(err, ErrNotCommentOwner):
+			response.SendErrorResponse(c, http.StatusForbidden, "You can only delete your own comments")
 		case strings.Contains(err.Error(), "not found"):
 			response.SendErrorResponse(c, http.StatusNotFound, "Category Not Found")
 		case strings.Contains(err.Error(), "invalid"):
@@ -0,0 +1,39 @@
+package comments_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/chatting-app/services/comments"
+	"github.com/wafi04/chatting-app/services/shared/middleware"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+func TestDeleteCommentByNonOwnerIsForbidden(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo, mock := newMockCommentRepository(t)
+	handler := comments.NewCommntHandler(comments.NewCommntService(repo, nil))
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(string(middleware.UserContextKey), &types.UserInfo{UserId: "u2", Role: types.RoleUser})
+	})
+	comments.RegisterRoutes(r.Group("/comment"), handler, func(c *gin.Context) {})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT post_id, parent_comment_id, user_id FROM comments WHERE id = \$1`).
+		WithArgs("C1").
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "parent_comment_id", "user_id"}).AddRow("P1", nil, "u1"))
+	// Tidak ada DELETE sama sekali untuk comment milik orang lain
+	mock.ExpectRollback()
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/comment/C1", nil))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
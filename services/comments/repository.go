@@ -3,6 +3,7 @@ package comments
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -65,6 +66,12 @@ func (r *CommentRepository) CreateComment(ctx context.Context, req *types.Create
 		depth = 0
 	}
 
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
 	// Insert the new comment into the database
 	query := `
         INSERT INTO comments (id, user_id, post_id, content, depth, created_at, parent_comment_id) 
@@ -72,7 +79,7 @@ func (r *CommentRepository) CreateComment(ctx context.Context, req *types.Create
         RETURNING id, user_id, post_id, content, depth, created_at, parent_comment_id
     `
 	comment := &types.Comment{}
-	err = r.db.QueryRowContext(
+	err = tx.QueryRowContext(
 		ctx,
 		query,
 		utils.GenerateRandomId("coment"),
@@ -95,6 +102,20 @@ func (r *CommentRepository) CreateComment(ctx context.Context, req *types.Create
 		return nil, err
 	}
 
+	// Counter di-update dalam transaksi yang sama dengan insert comment
+	if _, err = tx.ExecContext(ctx, "UPDATE posts SET comment_count = comment_count + 1 WHERE id = $1", req.PostID); err != nil {
+		return nil, fmt.Errorf("failed to update post comment count: %w", err)
+	}
+	if parentID != nil {
+		if _, err = tx.ExecContext(ctx, "UPDATE comments SET reply_count = reply_count + 1 WHERE id = $1", parentID); err != nil {
+			return nil, fmt.Errorf("failed to update reply count: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	// Fetch user info
 	user, err := r.authrepo.GetUser(ctx, &types.GetUserRequest{
 		UserId: comment.UserID,
@@ -128,10 +149,13 @@ func (r *CommentRepository) GetCommentTree(ctx context.Context, postID string) (
                 c.parent_comment_id, 
                 c.depth, 
                 c.created_at,
+                c.like_count,
+                c.reply_count,
                 ARRAY[c.id]::VARCHAR[] AS path,
                 0 AS level
             FROM comments c
             WHERE c.parent_comment_id IS NULL AND c.post_id = $1
+            AND EXISTS (SELECT 1 FROM posts p JOIN users pu ON pu.user_id = p.user_id WHERE p.id = c.post_id AND pu.is_active = true)
             UNION ALL
             SELECT 
                 c.id, 
@@ -141,22 +165,28 @@ func (r *CommentRepository) GetCommentTree(ctx context.Context, postID string) (
                 c.parent_comment_id, 
                 c.depth, 
                 c.created_at,
+                c.like_count,
+                c.reply_count,
                 ct.path || c.id::VARCHAR,
                 ct.level + 1
             FROM comments c
             INNER JOIN comment_tree ct ON ct.id = c.parent_comment_id
         )
+        -- Komentar dari akun nonaktif disamarkan agar balasan di bawahnya tetap tampil
         SELECT 
-            id, 
-            user_id, 
-            post_id, 
-            content, 
-            depth, 
-            created_at, 
-            parent_comment_id,
-            path
-        FROM comment_tree
-        ORDER BY path
+            ct.id, 
+            CASE WHEN u.is_active THEN ct.user_id ELSE '' END, 
+            ct.post_id, 
+            CASE WHEN u.is_active THEN ct.content ELSE '[unavailable]' END, 
+            ct.depth, 
+            ct.created_at, 
+            ct.parent_comment_id,
+            ct.like_count,
+            ct.reply_count,
+            ct.path
+        FROM comment_tree ct
+        LEFT JOIN users u ON u.user_id = ct.user_id
+        ORDER BY ct.path
     `
 	rows, err := r.db.QueryContext(ctx, query, postID)
 	if err != nil {
@@ -181,6 +211,8 @@ func (r *CommentRepository) GetCommentTree(ctx context.Context, postID string) (
 			&comm.Depth,
 			&createdAt,
 			&parentID,
+			&comm.LikeCount,
+			&comm.ReplyCount,
 			&path,
 		)
 		if err != nil {
@@ -235,15 +267,19 @@ func (s *CommentRepository) DeleteComment(ctx context.Context, req *types.Delete
 	defer tx.Rollback()
 
 	// Validate comment existence
-	var exists bool
+	var postID, ownerID string
+	var parentID sql.NullString
 	if err = tx.QueryRowContext(ctx,
-		"SELECT EXISTS(SELECT 1 FROM comments WHERE id = $1)",
+		"SELECT post_id, parent_comment_id, user_id FROM comments WHERE id = $1",
 		req.CommentID,
-	).Scan(&exists); err != nil {
+	).Scan(&postID, &parentID, &ownerID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, &CommentNotFoundError{CommentID: req.CommentID}
+		}
 		return nil, fmt.Errorf("failed to check comment existence: %w", err)
 	}
-	if !exists {
-		return nil, &CommentNotFoundError{CommentID: req.CommentID}
+	if req.OwnerID != "" && req.OwnerID != ownerID {
+		return nil, ErrNotCommentOwner
 	}
 
 	var deletedCount int64
@@ -258,6 +294,21 @@ func (s *CommentRepository) DeleteComment(ctx context.Context, req *types.Delete
 		return nil, err
 	}
 
+	if _, err = tx.ExecContext(ctx,
+		"UPDATE posts SET comment_count = GREATEST(comment_count - $1, 0) WHERE id = $2",
+		deletedCount, postID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to update post comment count: %w", err)
+	}
+	if parentID.Valid {
+		if _, err = tx.ExecContext(ctx,
+			"UPDATE comments SET reply_count = GREATEST(reply_count - 1, 0) WHERE id = $1",
+			parentID.String,
+		); err != nil {
+			return nil, fmt.Errorf("failed to update reply count: %w", err)
+		}
+	}
+
 	if err = tx.Commit(); err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
@@ -268,6 +319,9 @@ func (s *CommentRepository) DeleteComment(ctx context.Context, req *types.Delete
 	}, nil
 }
 
+// ErrNotCommentOwner dikembalikan saat user biasa menghapus comment milik orang lain
+var ErrNotCommentOwner = errors.New("comment does not belong to user")
+
 type CommentNotFoundError struct {
 	CommentID string
 }
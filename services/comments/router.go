@@ -4,8 +4,13 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoutes(r *gin.RouterGroup, h *CommentHandler) {
-	r.POST("", h.HandleCreateComment)
+func RegisterRoutes(r *gin.RouterGroup, h *CommentHandler, requireVerified gin.HandlerFunc) {
+	r.POST("", requireVerified, h.HandleCreateComment)
 	r.GET("/:id", h.HandleGetComments)
+	r.DELETE("/:id", h.HandleDeleteOwnComment)
+}
+
+// RegisterModerationRoutes dipasang di group yang dilindungi RequireRole(moderator, admin)
+func RegisterModerationRoutes(r *gin.RouterGroup, h *CommentHandler) {
 	r.DELETE("/:id", h.HandleDeleteCategory)
 }
@@ -3,21 +3,63 @@ package comments
 import (
 	"context"
 
+	"github.com/wafi04/chatting-app/services/notifications"
 	"github.com/wafi04/chatting-app/services/shared/types"
+	"github.com/wafi04/chatting-app/services/shared/utils"
 )
 
 type CommentService struct {
-	repo *CommentRepository
+	repo     *CommentRepository
+	notifier notifications.Notifier
 }
 
-func NewCommntService(repo *CommentRepository) *CommentService {
+func NewCommntService(repo *CommentRepository, notifier notifications.Notifier) *CommentService {
 	return &CommentService{
-		repo: repo,
+		repo:     repo,
+		notifier: notifier,
 	}
 }
 
 func (s *CommentService) CreateComment(ctx context.Context, req *types.CreateComment) (*types.Comment, error) {
-	return s.repo.CreateComment(ctx, req)
+	comment, err := s.repo.CreateComment(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyComment(ctx, comment)
+	return comment, nil
+}
+
+// notifyComment: balasan dinotifikasi ke pemilik comment induk, comment biasa ke pemilik post
+func (s *CommentService) notifyComment(ctx context.Context, comment *types.Comment) {
+	if s.notifier == nil {
+		return
+	}
+
+	event := &types.NotificationEvent{
+		Type:     types.NotificationComment,
+		ActorID:  comment.UserID,
+		TargetID: comment.PostID,
+		SourceID: comment.ID,
+		ThreadID: comment.PostID,
+		Preview:  comment.Content,
+	}
+	if comment.ParentID != nil && *comment.ParentID != "" {
+		event.Type = types.NotificationReply
+		event.TargetID = *comment.ParentID
+	}
+	s.notifier.Notify(ctx, event)
+
+	if mentions := utils.ExtractMentions(comment.Content); len(mentions) > 0 {
+		s.notifier.Notify(ctx, &types.NotificationEvent{
+			Type:     types.NotificationMention,
+			ActorID:  comment.UserID,
+			TargetID: comment.PostID,
+			SourceID: comment.ID,
+			ThreadID: comment.PostID,
+			Preview:  comment.Content,
+			Mentions: mentions,
+		})
+	}
 }
 func (s *CommentService) GetComments(ctx context.Context, req *types.ListCommentsRequest) (*types.ListCommentsResponse, error) {
 	categoryMap, rootCategories, err := s.repo.GetCommentTree(ctx, req.PostID)
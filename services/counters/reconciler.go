@@ -0,0 +1,107 @@
+package counters
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wafi04/chatting-app/services/likes"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+)
+
+// Jumlah post atau comment yang dicek per batch reconciliation
+const reconcileBatchSize = 500
+
+// Reconciler secara berkala menghitung ulang counter dari data sumber dan memperbaiki selisihnya
+type Reconciler struct {
+	repo     *CounterRepository
+	likerepo likes.Repository
+	log      *logger.Logger
+}
+
+func NewReconciler(repo *CounterRepository, likerepo likes.Repository) *Reconciler {
+	return &Reconciler{
+		repo:     repo,
+		likerepo: likerepo,
+		log:      logger.NewLogger(),
+	}
+}
+
+// Start menjalankan reconciliation setiap interval sampai ctx dibatalkan
+func (r *Reconciler) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := r.ReconcileOnce(ctx); err != nil {
+					r.log.Log(logger.ErrorLevel, "Counter reconciliation failed: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (r *Reconciler) ReconcileOnce(ctx context.Context) error {
+	fixedPosts, err := r.reconcileLikes(ctx, "posts", r.likerepo.GetPostLikeCounts)
+	if err != nil {
+		return err
+	}
+
+	fixedComments, err := r.reconcileLikes(ctx, "comments", r.likerepo.GetCommentLikeCounts)
+	if err != nil {
+		return err
+	}
+
+	fixedReplies, err := r.repo.ReconcileComments(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile comments: %w", err)
+	}
+
+	if fixedPosts+fixedComments+fixedReplies > 0 {
+		r.log.Log(logger.WarnLevel, "Counter drift corrected: posts=%d comments=%d comment_counts=%d",
+			fixedPosts, fixedComments, fixedReplies)
+	}
+
+	return nil
+}
+
+// reconcileLikes memeriksa like_count per batch target: counter dibaca dulu, lalu jumlah
+// like dihitung hanya untuk id di batch itu, sehingga tidak perlu memuat semua target sekaligus
+func (r *Reconciler) reconcileLikes(ctx context.Context, table string, count func(context.Context, []string) (map[string]int64, error)) (int64, error) {
+	var fixed int64
+	afterId := ""
+	for {
+		page, err := r.repo.LikeCountPage(ctx, table, afterId, reconcileBatchSize)
+		if err != nil {
+			return fixed, err
+		}
+		if len(page) == 0 {
+			return fixed, nil
+		}
+
+		ids := make([]string, len(page))
+		for i, row := range page {
+			ids[i] = row.ID
+		}
+		counts, err := count(ctx, ids)
+		if err != nil {
+			return fixed, err
+		}
+
+		n, err := r.repo.ReconcileLikes(ctx, table, page, counts)
+		if err != nil {
+			return fixed, err
+		}
+		fixed += n
+
+		if len(page) < reconcileBatchSize {
+			return fixed, nil
+		}
+		afterId = page[len(page)-1].ID
+	}
+}
@@ -0,0 +1,164 @@
+package counters
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// CounterRepository menyimpan counter like dan comment yang didenormalisasi di posts dan comments
+type CounterRepository struct {
+	db *sqlx.DB
+}
+
+func NewCounterRepository(db *sqlx.DB) *CounterRepository {
+	return &CounterRepository{
+		db: db,
+	}
+}
+
+func (r *CounterRepository) IncrementPostLikes(ctx context.Context, postId string, delta int64) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE posts SET like_count = GREATEST(like_count + $1, 0) WHERE id = $2",
+		delta, postId,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update post like count: %w", err)
+	}
+	return nil
+}
+
+func (r *CounterRepository) IncrementCommentLikes(ctx context.Context, commentId string, delta int64) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE comments SET like_count = GREATEST(like_count + $1, 0) WHERE id = $2",
+		delta, commentId,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update comment like count: %w", err)
+	}
+	return nil
+}
+
+func (r *CounterRepository) PostLikeCount(ctx context.Context, postId string) (int64, error) {
+	return r.likeCount(ctx, "posts", postId)
+}
+
+func (r *CounterRepository) CommentLikeCount(ctx context.Context, commentId string) (int64, error) {
+	return r.likeCount(ctx, "comments", commentId)
+}
+
+// likeCount membaca like_count satu baris; target yang tidak ada dianggap punya 0 like
+func (r *CounterRepository) likeCount(ctx context.Context, table, id string) (int64, error) {
+	var count int64
+	err := r.db.QueryRowContext(ctx, fmt.Sprintf("SELECT like_count FROM %s WHERE id = $1", table), id).Scan(&count)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s like count: %w", table, err)
+	}
+	return count, nil
+}
+
+// LikeCount adalah nilai like_count satu baris pada saat dibaca
+type LikeCount struct {
+	ID    string `db:"id"`
+	Count int64  `db:"like_count"`
+}
+
+// LikeCountPage membaca like_count paling banyak limit baris dengan id setelah afterId
+func (r *CounterRepository) LikeCountPage(ctx context.Context, table, afterId string, limit int) ([]LikeCount, error) {
+	if table != "posts" && table != "comments" {
+		return nil, fmt.Errorf("invalid counter table: %s", table)
+	}
+
+	var page []LikeCount
+	query := fmt.Sprintf("SELECT id, like_count FROM %s WHERE id > $1 ORDER BY id LIMIT $2", table)
+	if err := r.db.SelectContext(ctx, &page, query, afterId, limit); err != nil {
+		return nil, fmt.Errorf("failed to read %s like count: %w", table, err)
+	}
+	return page, nil
+}
+
+// ReconcileLikes menyamakan like_count baris di observed dengan jumlah like sebenarnya di
+// counts (id yang tidak ada di counts dianggap 0 like). Baris hanya diubah jika like_count
+// masih sama dengan nilai yang dibaca, jadi increment yang terjadi di antaranya tidak
+// tertimpa dan baris itu dicek lagi di putaran berikutnya. Mengembalikan jumlah baris yang dikoreksi.
+func (r *CounterRepository) ReconcileLikes(ctx context.Context, table string, observed []LikeCount, counts map[string]int64) (int64, error) {
+	if table != "posts" && table != "comments" {
+		return 0, fmt.Errorf("invalid counter table: %s", table)
+	}
+
+	var (
+		ids      []string
+		expected []int64
+		actual   []int64
+	)
+	for _, row := range observed {
+		if row.Count == counts[row.ID] {
+			continue
+		}
+		ids = append(ids, row.ID)
+		expected = append(expected, row.Count)
+		actual = append(actual, counts[row.ID])
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	query := fmt.Sprintf(`
+    UPDATE %s t
+    SET like_count = src.cnt
+    FROM unnest($1::text[], $2::bigint[], $3::bigint[]) AS src(id, seen, cnt)
+    WHERE t.id = src.id AND t.like_count = src.seen
+    `, table)
+
+	result, err := r.db.ExecContext(ctx, query, pq.Array(ids), pq.Array(expected), pq.Array(actual))
+	if err != nil {
+		return 0, fmt.Errorf("failed to reconcile %s like count: %w", table, err)
+	}
+
+	return result.RowsAffected()
+}
+
+// ReconcileComments menghitung ulang comment_count di posts dan reply_count di comments
+func (r *CounterRepository) ReconcileComments(ctx context.Context) (int64, error) {
+	postQuery := `
+    UPDATE posts t
+    SET comment_count = COALESCE(src.cnt, 0)
+    FROM posts cur
+    LEFT JOIN (SELECT post_id, COUNT(*) AS cnt FROM comments GROUP BY post_id) src ON src.post_id = cur.id
+    WHERE t.id = cur.id AND t.comment_count <> COALESCE(src.cnt, 0)
+    `
+
+	replyQuery := `
+    UPDATE comments t
+    SET reply_count = COALESCE(src.cnt, 0)
+    FROM comments cur
+    LEFT JOIN (
+        SELECT parent_comment_id, COUNT(*) AS cnt 
+        FROM comments 
+        WHERE parent_comment_id IS NOT NULL 
+        GROUP BY parent_comment_id
+    ) src ON src.parent_comment_id = cur.id
+    WHERE t.id = cur.id AND t.reply_count <> COALESCE(src.cnt, 0)
+    `
+
+	var total int64
+	for _, query := range []string{postQuery, replyQuery} {
+		result, err := r.db.ExecContext(ctx, query)
+		if err != nil {
+			return total, fmt.Errorf("failed to reconcile comment count: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return total, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		total += affected
+	}
+
+	return total, nil
+}
@@ -0,0 +1,70 @@
+package digest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/wafi04/chatting-app/config/env"
+)
+
+type Config struct {
+	// Secret untuk menandatangani token unsubscribe; kosong berarti digest tidak aktif
+	Secret string
+	// UnsubscribeURL alamat publik endpoint unsubscribe; token ditambahkan sebagai ?token=
+	UnsubscribeURL string
+	// AppURL opsional, dipakai untuk tombol "Open app" di email
+	AppURL string
+	// Interval jarak minimal antar digest untuk satu user
+	Interval time.Duration
+	// InactiveAfter lama user tidak membuka aplikasi sebelum mendapat digest
+	InactiveAfter time.Duration
+	// WorkerInterval jeda antar putaran pemeriksaan user
+	WorkerInterval time.Duration
+}
+
+func (c *Config) Enabled() bool {
+	return c.Secret != ""
+}
+
+// LoadConfig membaca DIGEST_SECRET, DIGEST_UNSUBSCRIBE_URL, DIGEST_APP_URL, DIGEST_INTERVAL,
+// DIGEST_INACTIVE_AFTER dan DIGEST_WORKER_INTERVAL
+func LoadConfig() (*Config, error) {
+	config := &Config{
+		Secret:         env.LoadEnv("DIGEST_SECRET"),
+		UnsubscribeURL: env.LoadEnv("DIGEST_UNSUBSCRIBE_URL"),
+		AppURL:         env.LoadEnv("DIGEST_APP_URL"),
+		Interval:       7 * 24 * time.Hour,
+		InactiveAfter:  3 * 24 * time.Hour,
+		WorkerInterval: time.Hour,
+	}
+	if config.Enabled() {
+		if len(config.Secret) < 32 {
+			return nil, fmt.Errorf("DIGEST_SECRET must be at least 32 characters")
+		}
+		if config.UnsubscribeURL == "" {
+			return nil, fmt.Errorf("DIGEST_UNSUBSCRIBE_URL is required when DIGEST_SECRET is set")
+		}
+	}
+
+	durations := []struct {
+		key    string
+		target *time.Duration
+	}{
+		{"DIGEST_INTERVAL", &config.Interval},
+		{"DIGEST_INACTIVE_AFTER", &config.InactiveAfter},
+		{"DIGEST_WORKER_INTERVAL", &config.WorkerInterval},
+	}
+	for _, d := range durations {
+		raw := env.LoadEnv(d.key)
+		if raw == "" {
+			continue
+		}
+		value, err := time.ParseDuration(raw)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("invalid %s: %q", d.key, raw)
+		}
+		*d.target = value
+	}
+
+	return config, nil
+}
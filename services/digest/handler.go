@@ -0,0 +1,65 @@
+package digest
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+	"github.com/wafi04/chatting-app/services/shared/pkg/response"
+)
+
+// DigestHandler melayani link unsubscribe dari email; tidak memerlukan login
+type DigestHandler struct {
+	repo   *DigestRepository
+	tokens *UnsubscribeToken
+	log    *logger.Logger
+}
+
+func NewDigestHandler(repo *DigestRepository, config *Config) *DigestHandler {
+	return &DigestHandler{
+		repo:   repo,
+		tokens: NewUnsubscribeToken(config.Secret),
+		log:    logger.NewLogger(),
+	}
+}
+
+// HandleUnsubscribePage menampilkan konfirmasi. GET tidak mengubah apa pun karena
+// link di email sering dibuka otomatis oleh pemindai keamanan.
+func (h *DigestHandler) HandleUnsubscribePage(c *gin.Context) {
+	token := c.Query("token")
+	if _, err := h.tokens.Verify(token); err != nil {
+		h.page(c, http.StatusBadRequest, &unsubscribePage{Invalid: true})
+		return
+	}
+	h.page(c, http.StatusOK, &unsubscribePage{Token: token})
+}
+
+// HandleUnsubscribe dipanggil dari form konfirmasi atau one-click unsubscribe (RFC 8058)
+func (h *DigestHandler) HandleUnsubscribe(c *gin.Context) {
+	token := c.PostForm("token")
+	if token == "" {
+		token = c.Query("token")
+	}
+	userId, err := h.tokens.Verify(token)
+	if err != nil {
+		h.page(c, http.StatusBadRequest, &unsubscribePage{Invalid: true})
+		return
+	}
+
+	if err := h.repo.Unsubscribe(c.Request.Context(), userId); err != nil {
+		h.log.Log(logger.ErrorLevel, "Failed to unsubscribe from digest: %v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to unsubscribe")
+		return
+	}
+	h.page(c, http.StatusOK, &unsubscribePage{Done: true})
+}
+
+func (h *DigestHandler) page(c *gin.Context, status int, page *unsubscribePage) {
+	body, err := renderPage(page)
+	if err != nil {
+		h.log.Log(logger.ErrorLevel, "%v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to render page")
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", body)
+}
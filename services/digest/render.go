@@ -0,0 +1,125 @@
+package digest
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"net/url"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/wafi04/chatting-app/services/shared/pkg/mailer"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+var (
+	textTemplate = texttemplate.Must(texttemplate.ParseFS(templateFS, "templates/digest.txt"))
+	htmlTemplate = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/digest.html"))
+	pageTemplate = htmltemplate.Must(htmltemplate.ParseFS(templateFS, "templates/unsubscribe.html"))
+)
+
+// Panjang maksimal caption post yang ditampilkan di email
+const maxCaptionLength = 140
+
+// digestData adalah isi satu email digest
+type digestData struct {
+	Name          string
+	Since         string
+	Followers     []*follower
+	MoreFollowers int
+	Notifications []*types.Notification
+	UnreadCount   int
+	Posts         []*popularPost
+	AppURL        string
+	// UnsubscribeURL sudah termasuk token
+	UnsubscribeURL string
+}
+
+func (d *digestData) empty() bool {
+	return len(d.Followers) == 0 && len(d.Notifications) == 0 && len(d.Posts) == 0
+}
+
+func (d *digestData) subject() string {
+	var parts []string
+	if followers := len(d.Followers) + d.MoreFollowers; followers > 0 {
+		parts = append(parts, plural(followers, "new follower", "new followers"))
+	}
+	if d.UnreadCount > 0 {
+		parts = append(parts, plural(d.UnreadCount, "unread notification", "unread notifications"))
+	}
+	if len(parts) == 0 {
+		return "Popular posts from people you follow"
+	}
+	return "You have " + strings.Join(parts, " and ")
+}
+
+func plural(n int, one, many string) string {
+	if n == 1 {
+		return "1 " + one
+	}
+	return fmt.Sprintf("%d %s", n, many)
+}
+
+// unsubscribeLink menambahkan token ke DIGEST_UNSUBSCRIBE_URL
+func unsubscribeLink(base, token string) string {
+	separator := "?"
+	if strings.Contains(base, "?") {
+		separator = "&"
+	}
+	return base + separator + "token=" + url.QueryEscape(token)
+}
+
+func truncate(text string, max int) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	if len(runes) <= max {
+		return string(runes)
+	}
+	return string(runes[:max-1]) + "…"
+}
+
+func formatSince(since time.Time) string {
+	return since.UTC().Format("2 Jan 2006")
+}
+
+// render membuat email digest versi teks dan HTML
+func render(to string, data *digestData) (*mailer.Message, error) {
+	var text, html bytes.Buffer
+	if err := textTemplate.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("failed to render digest text: %w", err)
+	}
+	if err := htmlTemplate.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("failed to render digest html: %w", err)
+	}
+
+	return &mailer.Message{
+		To:       to,
+		Subject:  data.subject(),
+		TextBody: text.String(),
+		HTMLBody: html.String(),
+		Headers: map[string]string{
+			// One-click unsubscribe (RFC 8058): mail client mengirim POST ke URL ini
+			"List-Unsubscribe":      "<" + data.UnsubscribeURL + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}, nil
+}
+
+// unsubscribePage adalah data halaman konfirmasi unsubscribe
+type unsubscribePage struct {
+	Token   string
+	Done    bool
+	Invalid bool
+}
+
+func renderPage(page *unsubscribePage) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pageTemplate.Execute(&buf, page); err != nil {
+		return nil, fmt.Errorf("failed to render unsubscribe page: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,60 @@
+package digest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+func TestRender(t *testing.T) {
+	data := &digestData{
+		Name:          "Budi",
+		Since:         "1 Oct 2026",
+		Followers:     []*follower{{Name: "Sari", Username: "sari"}, {Name: "<Eve>"}},
+		MoreFollowers: 3,
+		Notifications: []*types.Notification{
+			{Type: types.NotificationPostLike, Text: "Andi and 2 others liked your post"},
+		},
+		UnreadCount:    4,
+		Posts:          []*popularPost{{Author: "Dina", Caption: "Sunset", LikeCount: 12, CommentCount: 2}},
+		UnsubscribeURL: unsubscribeLink("https://api.example.com/api/v1/digest/unsubscribe", "abc.def"),
+	}
+
+	msg, err := render("budi@example.com", data)
+	require.NoError(t, err)
+
+	assert.Equal(t, "budi@example.com", msg.To)
+	assert.Equal(t, "You have 5 new followers and 4 unread notifications", msg.Subject)
+	assert.Equal(t, "<https://api.example.com/api/v1/digest/unsubscribe?token=abc.def>", msg.Headers["List-Unsubscribe"])
+	assert.Equal(t, "List-Unsubscribe=One-Click", msg.Headers["List-Unsubscribe-Post"])
+
+	assert.Contains(t, msg.TextBody, "- Sari (@sari)")
+	assert.Contains(t, msg.TextBody, "...and 3 more")
+	assert.Contains(t, msg.TextBody, "- Andi and 2 others liked your post")
+	assert.Contains(t, msg.TextBody, "You have 4 unread notifications in total.")
+	assert.Contains(t, msg.TextBody, `- Dina: "Sunset" (12 likes, 2 comments)`)
+	assert.Contains(t, msg.TextBody, "token=abc.def")
+
+	// Nama dari user di-escape di versi HTML
+	assert.Contains(t, msg.HTMLBody, "&lt;Eve&gt;")
+	assert.NotContains(t, msg.HTMLBody, "<Eve>")
+	assert.Contains(t, msg.HTMLBody, `href="https://api.example.com/api/v1/digest/unsubscribe?token=abc.def"`)
+}
+
+func TestDigestEmpty(t *testing.T) {
+	data := &digestData{UnreadCount: 3}
+	assert.True(t, data.empty())
+
+	data.Posts = []*popularPost{{Author: "Dina"}}
+	assert.False(t, data.empty())
+	assert.Equal(t, "You have 3 unread notifications", data.subject())
+	data.UnreadCount = 0
+	assert.Equal(t, "Popular posts from people you follow", data.subject())
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "a b", truncate("a\n  b", 10))
+	assert.Equal(t, "abcd…", truncate("abcdefgh", 5))
+}
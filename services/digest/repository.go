@@ -0,0 +1,204 @@
+package digest
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type DigestRepository struct {
+	db *sqlx.DB
+}
+
+func NewDigestRepository(db *sqlx.DB) *DigestRepository {
+	return &DigestRepository{
+		db: db,
+	}
+}
+
+// recipient adalah user yang sudah waktunya diperiksa untuk digest
+type recipient struct {
+	UserId string
+	Name   string
+	Email  string
+}
+
+type follower struct {
+	Name     string
+	Username string
+}
+
+type popularPost struct {
+	Id           string
+	Author       string
+	Caption      string
+	LikeCount    int
+	CommentCount int
+}
+
+// Due mengembalikan user aktif dengan email terverifikasi yang tidak membuka aplikasi
+// sejak inactiveAfter dan belum diperiksa dalam interval terakhir
+func (r *DigestRepository) Due(ctx context.Context, interval, inactiveAfter time.Duration, limit int) ([]*recipient, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT u.user_id, u.name, u.email
+        FROM users u
+        LEFT JOIN notification_settings s ON s.user_id = u.user_id
+        LEFT JOIN email_digests d ON d.user_id = u.user_id
+        WHERE u.is_active = true
+          AND u.is_email_verified = true
+          AND u.deletion_scheduled_at IS NULL
+          AND COALESCE(s.digest_enabled, true)
+          AND (d.last_run_at IS NULL OR d.last_run_at < NOW() - make_interval(secs => $1))
+          AND GREATEST(
+                u.last_login_at,
+                (SELECT MAX(last_activity_at) FROM sessions WHERE user_id = u.user_id),
+                u.created_at
+              ) < NOW() - make_interval(secs => $2)
+        ORDER BY d.last_run_at NULLS FIRST, u.user_id
+        LIMIT $3
+    `, interval.Seconds(), inactiveAfter.Seconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get digest recipients: %w", err)
+	}
+	defer rows.Close()
+
+	var recipients []*recipient
+	for rows.Next() {
+		var rec recipient
+		if err := rows.Scan(&rec.UserId, &rec.Name, &rec.Email); err != nil {
+			return nil, fmt.Errorf("failed to scan digest recipient: %w", err)
+		}
+		recipients = append(recipients, &rec)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating digest recipients: %w", err)
+	}
+	return recipients, nil
+}
+
+// Claim menandai user sedang diproses supaya instance lain tidak mengirim digest yang sama.
+// since adalah batas awal isi digest (run sebelumnya); ok false jika sudah diklaim instance lain.
+func (r *DigestRepository) Claim(ctx context.Context, userId string, interval time.Duration, now time.Time) (since time.Time, ok bool, err error) {
+	var previous sql.NullTime
+	err = r.db.QueryRowContext(ctx, `
+        WITH previous AS (
+            SELECT last_run_at FROM email_digests WHERE user_id = $1
+        )
+        INSERT INTO email_digests (user_id, last_run_at)
+        VALUES ($1, $2)
+        ON CONFLICT (user_id) DO UPDATE SET last_run_at = EXCLUDED.last_run_at
+        WHERE email_digests.last_run_at < $2 - make_interval(secs => $3)
+        RETURNING (SELECT last_run_at FROM previous)
+    `, userId, now, interval.Seconds()).Scan(&previous)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, fmt.Errorf("failed to claim digest: %w", err)
+	}
+
+	if !previous.Valid {
+		// Digest pertama merangkum satu interval ke belakang
+		return now.Add(-interval), true, nil
+	}
+	return previous.Time, true, nil
+}
+
+func (r *DigestRepository) MarkSent(ctx context.Context, userId string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE email_digests SET last_sent_at = NOW() WHERE user_id = $1`, userId)
+	if err != nil {
+		return fmt.Errorf("failed to mark digest as sent: %w", err)
+	}
+	return nil
+}
+
+// NewFollowers mengembalikan jumlah follower baru sejak since dan beberapa yang terbaru
+func (r *DigestRepository) NewFollowers(ctx context.Context, userId string, since time.Time, limit int) (int, []*follower, error) {
+	var total int
+	err := r.db.QueryRowContext(ctx, `
+        SELECT COUNT(*)
+        FROM followers f
+        JOIN users u ON u.user_id = f.follower_id AND u.is_active = true
+        WHERE f.following_id = $1 AND f.is_blocked = false AND f.created_at > $2
+    `, userId, since).Scan(&total)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to count new followers: %w", err)
+	}
+	if total == 0 {
+		return 0, nil, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT u.name, COALESCE(p.username, '')
+        FROM followers f
+        JOIN users u ON u.user_id = f.follower_id AND u.is_active = true
+        LEFT JOIN user_profile p ON p.user_id = u.user_id
+        WHERE f.following_id = $1 AND f.is_blocked = false AND f.created_at > $2
+        ORDER BY f.created_at DESC
+        LIMIT $3
+    `, userId, since, limit)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get new followers: %w", err)
+	}
+	defer rows.Close()
+
+	var followers []*follower
+	for rows.Next() {
+		var f follower
+		if err := rows.Scan(&f.Name, &f.Username); err != nil {
+			return 0, nil, fmt.Errorf("failed to scan follower: %w", err)
+		}
+		followers = append(followers, &f)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, nil, fmt.Errorf("error iterating followers: %w", err)
+	}
+	return total, followers, nil
+}
+
+// PopularPosts mengembalikan post terpopuler sejak since dari akun yang diikuti user
+func (r *DigestRepository) PopularPosts(ctx context.Context, userId string, since time.Time, limit int) ([]*popularPost, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT p.id, u.name, COALESCE(p.caption, ''), p.like_count, p.comment_count
+        FROM followers f
+        JOIN posts p ON p.user_id = f.following_id
+        JOIN users u ON u.user_id = p.user_id AND u.is_active = true
+        WHERE f.follower_id = $1 AND f.is_blocked = false AND p.created_at > $2
+          AND p.like_count + p.comment_count > 0
+        ORDER BY p.like_count + 2 * p.comment_count DESC, p.created_at DESC
+        LIMIT $3
+    `, userId, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get popular posts: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []*popularPost
+	for rows.Next() {
+		var post popularPost
+		if err := rows.Scan(&post.Id, &post.Author, &post.Caption, &post.LikeCount, &post.CommentCount); err != nil {
+			return nil, fmt.Errorf("failed to scan popular post: %w", err)
+		}
+		posts = append(posts, &post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating popular posts: %w", err)
+	}
+	return posts, nil
+}
+
+// Unsubscribe mematikan digest tanpa mengubah pengaturan notifikasi lainnya
+func (r *DigestRepository) Unsubscribe(ctx context.Context, userId string) error {
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO notification_settings (user_id, digest_enabled)
+        SELECT user_id, false FROM users WHERE user_id = $1
+        ON CONFLICT (user_id) DO UPDATE SET digest_enabled = false, updated_at = NOW()
+    `, userId)
+	if err != nil {
+		return fmt.Errorf("failed to unsubscribe from digest: %w", err)
+	}
+	return nil
+}
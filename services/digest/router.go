@@ -0,0 +1,12 @@
+package digest
+
+import "github.com/gin-gonic/gin"
+
+// UnsubscribePath adalah path lengkap endpoint unsubscribe, dipakai gateway untuk
+// mengecualikannya dari proteksi CSRF cookie
+const UnsubscribePath = "/api/v1/digest/unsubscribe"
+
+func RegisterRoutes(r *gin.RouterGroup, h *DigestHandler) {
+	r.GET("/unsubscribe", h.HandleUnsubscribePage)
+	r.POST("/unsubscribe", h.HandleUnsubscribe)
+}
@@ -0,0 +1,50 @@
+package digest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var ErrInvalidToken = errors.New("invalid unsubscribe token")
+
+// UnsubscribeToken menandatangani user id sehingga link unsubscribe bisa dipakai tanpa login.
+// Token tidak kedaluwarsa: link di email lama harus tetap berfungsi.
+type UnsubscribeToken struct {
+	secret []byte
+}
+
+func NewUnsubscribeToken(secret string) *UnsubscribeToken {
+	return &UnsubscribeToken{secret: []byte(secret)}
+}
+
+func (t *UnsubscribeToken) Sign(userId string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(userId)) + "." + base64.RawURLEncoding.EncodeToString(t.mac(userId))
+}
+
+// Verify mengembalikan user id pemilik token
+func (t *UnsubscribeToken) Verify(token string) (string, error) {
+	encodedId, encodedMac, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", ErrInvalidToken
+	}
+	userId, err := base64.RawURLEncoding.DecodeString(encodedId)
+	if err != nil || len(userId) == 0 {
+		return "", ErrInvalidToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encodedMac)
+	if err != nil || !hmac.Equal(mac, t.mac(string(userId))) {
+		return "", ErrInvalidToken
+	}
+	return string(userId), nil
+}
+
+func (t *UnsubscribeToken) mac(userId string) []byte {
+	h := hmac.New(sha256.New, t.secret)
+	// Prefix tujuan supaya tanda tangan ini tidak bisa dipakai ulang untuk keperluan lain
+	h.Write([]byte("digest-unsubscribe\x00"))
+	h.Write([]byte(userId))
+	return h.Sum(nil)
+}
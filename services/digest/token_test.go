@@ -0,0 +1,32 @@
+package digest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnsubscribeToken(t *testing.T) {
+	tokens := NewUnsubscribeToken(strings.Repeat("s", 32))
+
+	token := tokens.Sign("user-1")
+	userId, err := tokens.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", userId)
+
+	// Token untuk user lain tidak bisa dibuat dengan mengganti bagian id
+	other := tokens.Sign("user-2")
+	forged := strings.SplitN(other, ".", 2)[0] + "." + strings.SplitN(token, ".", 2)[1]
+	_, err = tokens.Verify(forged)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = NewUnsubscribeToken(strings.Repeat("x", 32)).Verify(token)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	for _, invalid := range []string{"", "abc", ".", "dXNlcg.", "!!.!!"} {
+		_, err := tokens.Verify(invalid)
+		assert.ErrorIs(t, err, ErrInvalidToken, invalid)
+	}
+}
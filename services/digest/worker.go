@@ -0,0 +1,153 @@
+package digest
+
+import (
+	"context"
+	"time"
+
+	"github.com/wafi04/chatting-app/services/notifications"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+	"github.com/wafi04/chatting-app/services/shared/pkg/mailer"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+const (
+	batchSize            = 50
+	maxDigestFollowers   = 5
+	maxDigestItems       = 5
+	maxDigestPopularPost = 3
+)
+
+// Worker mengirim digest email berkala ke user yang lama tidak membuka aplikasi
+type Worker struct {
+	repo          *DigestRepository
+	notifications *notifications.NotificationService
+	mail          mailer.Mailer
+	tokens        *UnsubscribeToken
+	config        *Config
+	log           *logger.Logger
+}
+
+func NewWorker(repo *DigestRepository, notificationService *notifications.NotificationService, mail mailer.Mailer, config *Config) *Worker {
+	return &Worker{
+		repo:          repo,
+		notifications: notificationService,
+		mail:          mail,
+		tokens:        NewUnsubscribeToken(config.Secret),
+		config:        config,
+		log:           logger.NewLogger(),
+	}
+}
+
+// Start menjalankan worker setiap interval sampai ctx dibatalkan
+func (w *Worker) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			w.RunOnce(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (w *Worker) RunOnce(ctx context.Context) {
+	for {
+		recipients, err := w.repo.Due(ctx, w.config.Interval, w.config.InactiveAfter, batchSize)
+		if err != nil {
+			w.log.Log(logger.ErrorLevel, "%v", err)
+			return
+		}
+
+		claimed := 0
+		for _, rec := range recipients {
+			ok, err := w.process(ctx, rec)
+			if err != nil {
+				// User sudah diklaim; dicoba lagi di interval berikutnya
+				w.log.Log(logger.ErrorLevel, "Failed to send digest to %s: %v", rec.UserId, err)
+			}
+			if ok {
+				claimed++
+			}
+		}
+		if len(recipients) < batchSize || claimed == 0 {
+			return
+		}
+	}
+}
+
+// process mengklaim user lalu mengirim digest jika ada yang baru sejak digest sebelumnya
+func (w *Worker) process(ctx context.Context, rec *recipient) (bool, error) {
+	now := time.Now()
+	since, ok, err := w.repo.Claim(ctx, rec.UserId, w.config.Interval, now)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	data, err := w.collect(ctx, rec, since)
+	if err != nil {
+		return true, err
+	}
+	if data.empty() {
+		return true, nil
+	}
+
+	msg, err := render(rec.Email, data)
+	if err != nil {
+		return true, err
+	}
+	if err := w.mail.Send(ctx, msg); err != nil {
+		return true, err
+	}
+	return true, w.repo.MarkSent(ctx, rec.UserId)
+}
+
+func (w *Worker) collect(ctx context.Context, rec *recipient, since time.Time) (*digestData, error) {
+	data := &digestData{
+		Name:           rec.Name,
+		Since:          formatSince(since),
+		AppURL:         w.config.AppURL,
+		UnsubscribeURL: unsubscribeLink(w.config.UnsubscribeURL, w.tokens.Sign(rec.UserId)),
+	}
+
+	total, followers, err := w.repo.NewFollowers(ctx, rec.UserId, since, maxDigestFollowers)
+	if err != nil {
+		return nil, err
+	}
+	data.Followers, data.MoreFollowers = followers, total-len(followers)
+
+	items, err := w.notifications.UnreadForEmail(ctx, rec.UserId, since, maxDigestItems+1)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range items {
+		// Follower baru sudah punya bagian sendiri
+		if n.Type == types.NotificationNewFollower || len(data.Notifications) == maxDigestItems {
+			continue
+		}
+		n.Preview = truncate(n.Preview, maxCaptionLength)
+		data.Notifications = append(data.Notifications, n)
+	}
+	if len(data.Notifications) > 0 {
+		unread, err := w.notifications.UnreadCount(ctx, rec.UserId)
+		if err != nil {
+			return nil, err
+		}
+		data.UnreadCount = unread.Count
+	}
+
+	posts, err := w.repo.PopularPosts(ctx, rec.UserId, since, maxDigestPopularPost)
+	if err != nil {
+		return nil, err
+	}
+	for _, post := range posts {
+		post.Caption = truncate(post.Caption, maxCaptionLength)
+	}
+	data.Posts = posts
+
+	return data, nil
+}
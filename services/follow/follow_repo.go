@@ -122,3 +122,37 @@ func (r *FollowRepository) Unfollow(ctx context.Context, followerID, followingID
 
 	return nil
 }
+
+// GetFollowingIDs mengembalikan id semua akun yang di-follow userID (tanpa yang diblokir)
+func (r *FollowRepository) GetFollowingIDs(ctx context.Context, userID string) ([]string, error) {
+	query := `
+    SELECT following_id
+    FROM followers
+    WHERE follower_id = $1 AND is_blocked = false
+    `
+
+	ids := []string{}
+	if err := r.DB.SelectContext(ctx, &ids, query, userID); err != nil {
+		r.log.Log(logger.ErrorLevel, "Failed to get following ids: %v", err)
+		return nil, fmt.Errorf("failed to get following ids: %w", err)
+	}
+
+	return ids, nil
+}
+
+// GetBlockedByIDs mengembalikan id semua akun yang memblokir userID
+func (r *FollowRepository) GetBlockedByIDs(ctx context.Context, userID string) ([]string, error) {
+	query := `
+    SELECT following_id
+    FROM followers
+    WHERE follower_id = $1 AND is_blocked = true
+    `
+
+	ids := []string{}
+	if err := r.DB.SelectContext(ctx, &ids, query, userID); err != nil {
+		r.log.Log(logger.ErrorLevel, "Failed to get blocked by ids: %v", err)
+		return nil, fmt.Errorf("failed to get blocked by ids: %w", err)
+	}
+
+	return ids, nil
+}
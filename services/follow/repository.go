@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/wafi04/chatting-app/services/notifications"
 	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
 	"github.com/wafi04/chatting-app/services/shared/types"
 	"github.com/wafi04/chatting-app/services/shared/utils"
@@ -15,13 +16,21 @@ import (
 type FollowRepository struct {
 	DB       *sqlx.DB
 	userRepo *user.UserRepository
+	notifier notifications.Notifier
 	log      logger.Logger
 }
 
-func NewFollowRepository(db *sqlx.DB, userrepo *user.UserRepository) *FollowRepository {
+func NewFollowRepository(db *sqlx.DB, userrepo *user.UserRepository, notifier notifications.Notifier) *FollowRepository {
 	return &FollowRepository{
 		DB:       db,
 		userRepo: userrepo,
+		notifier: notifier,
+	}
+}
+
+func (r *FollowRepository) notify(ctx context.Context, event *types.NotificationEvent) {
+	if r.notifier != nil {
+		r.notifier.Notify(ctx, event)
 	}
 }
 
@@ -57,6 +66,12 @@ func (r *FollowRepository) AddFollower(ctx context.Context, req *types.CreateFol
 		if err != nil {
 			return nil, err
 		}
+		r.notify(ctx, &types.NotificationEvent{
+			Type:        types.NotificationFollowRequest,
+			ActorID:     req.FollowerID,
+			RecipientID: req.FollowingID,
+			TargetID:    data,
+		})
 		return &types.RespondFollowRequest{
 			RequestID: data,
 		}, nil
@@ -78,6 +93,11 @@ func (r *FollowRepository) AddFollower(ctx context.Context, req *types.CreateFol
 		if err != nil {
 			return nil, err
 		}
+		r.notify(ctx, &types.NotificationEvent{
+			Type:        types.NotificationNewFollower,
+			ActorID:     req.FollowerID,
+			RecipientID: req.FollowingID,
+		})
 		return &types.RespondFollowRequest{
 			RequestID: followerID,
 			Status:    "ACCEPTED",
@@ -143,5 +143,10 @@ func (r *FollowRepository) AcceptFollowRequest(ctx context.Context, requestID st
 		return fmt.Errorf("failed to delete follow request: %w", err)
 	}
 
+	r.notify(ctx, &types.NotificationEvent{
+		Type:        types.NotificationFollowAccepted,
+		ActorID:     followRequest.FollowingID,
+		RecipientID: followRequest.FollowerID,
+	})
 	return nil
 }
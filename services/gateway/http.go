@@ -1,58 +1,240 @@
 package gateway
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/gin-gonic/gin"
 	"github.com/wafi04/chatting-app/config/database"
+	"github.com/wafi04/chatting-app/config/env"
+	"github.com/wafi04/chatting-app/services/account"
 	authhandler "github.com/wafi04/chatting-app/services/auth/pkg/handler"
+	"github.com/wafi04/chatting-app/services/auth/pkg/oidc"
 	authrepository "github.com/wafi04/chatting-app/services/auth/pkg/repository"
 	authservice "github.com/wafi04/chatting-app/services/auth/pkg/service"
 	"github.com/wafi04/chatting-app/services/comments"
+	"github.com/wafi04/chatting-app/services/counters"
+	"github.com/wafi04/chatting-app/services/digest"
+	"github.com/wafi04/chatting-app/services/follow"
 	"github.com/wafi04/chatting-app/services/likes"
+	"github.com/wafi04/chatting-app/services/notifications"
 	posthandler "github.com/wafi04/chatting-app/services/post/handler"
 	cloudrepo "github.com/wafi04/chatting-app/services/post/repository/cloud"
 	postrepo "github.com/wafi04/chatting-app/services/post/repository/post"
 	postservice "github.com/wafi04/chatting-app/services/post/service"
+	"github.com/wafi04/chatting-app/services/push"
+	"github.com/wafi04/chatting-app/services/reactions"
+	"github.com/wafi04/chatting-app/services/search"
 	"github.com/wafi04/chatting-app/services/shared/middleware"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+	"github.com/wafi04/chatting-app/services/shared/pkg/mailer"
+	"github.com/wafi04/chatting-app/services/shared/types"
+	"github.com/wafi04/chatting-app/services/user"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func SetUpRoutes(db *database.Database, mongoClient *mongo.Client, cld *cloudinary.Cloudinary) *gin.Engine {
+// SetUpRoutes menyusun semua dependency dan route. mongoClient boleh nil jika
+// LIKES_BACKEND=postgres; fitur yang masih butuh Mongo (reactions) akan dinonaktifkan.
+func SetUpRoutes(db *database.Database, mongoClient *mongo.Client, cld *cloudinary.Cloudinary) (*gin.Engine, error) {
+	log := logger.NewLogger()
+	keySet, err := middleware.LoadKeySetFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	middleware.SetKeySet(keySet)
+	cookieConfig, err := middleware.LoadCookieConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	middleware.SetCookieConfig(cookieConfig)
+
 	r := gin.Default()
 	middleware.ResponseTime(r)
 	CheckCoon(r)
+	RegisterJWKS(r, keySet)
 	middleware.SetUpCors(r)
+	// Unsubscribe digest dikirim dari form HTML tanpa header CSRF; otorisasinya lewat token HMAC
+	r.Use(middleware.CSRFProtection(digest.UnsubscribePath))
+	r.Use(middleware.CaptureClientInfo())
 	// Auth dependencies
 	authRepo := authrepository.NewUserRepository(db.DB)
-	authService := authservice.NewAuthService(authRepo)
-	authHandler := authhandler.NewGateway(authService)
+	mail, err := mailer.NewFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	oidcConfigs, err := oidc.LoadProvidersFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	var oidcProviders []*oidc.Provider
+	for _, config := range oidcConfigs {
+		oidcProviders = append(oidcProviders, oidc.NewProvider(config, nil))
+	}
+	authService := authservice.NewAuthService(authRepo, mail, oidcProviders)
+	sessionCache := middleware.NewSessionCache(authRepo.IsSessionActive, 30*time.Second, 10000)
+	authHandler := authhandler.NewGateway(authService, sessionCache)
+
+	notificationConfig, err := notifications.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	notificationRepo := notifications.NewNotificationRepository(db.DB)
+	notificationBroker := notifications.NewBroker(notificationConfig.MaxStreamsPerUser)
+	pushConfig, err := push.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	var pushProviders []push.PushProvider
+	if pushConfig.VAPID != nil {
+		webPush, err := push.NewWebPushProvider(*pushConfig.VAPID, nil)
+		if err != nil {
+			return nil, err
+		}
+		pushProviders = append(pushProviders, webPush)
+	} else {
+		log.Log(logger.InfoLevel, "VAPID keys not configured, web push disabled")
+	}
+	pushService := push.NewPushService(push.NewPushRepository(db.DB), pushConfig, pushProviders...)
+	pushHandler := push.NewPushHandler(pushService)
+	notificationService := notifications.NewNotificationService(notificationRepo, notificationBroker, pushService)
+	notificationHandler := notifications.NewNotificationHandler(notificationService, sessionCache, notificationConfig)
 
 	commentRepo := comments.NewCommentRepository(db.DB, authRepo)
 
-	commetService := comments.NewCommntService(commentRepo)
+	commetService := comments.NewCommntService(commentRepo, notificationService)
 	commentHandler := comments.NewCommntHandler(commetService)
 	// Post dependencies
 	postRepo := postrepo.NewPostRepository(db.DB, commentRepo, authRepo)
 	cloudRepo := cloudrepo.NewCloudinaryService(cld)
-	postService := postservice.NewPostService(cloudRepo, postRepo)
+	postService := postservice.NewPostService(cloudRepo, postRepo, notificationService)
 	postHandler := posthandler.NewGateway(postService, authService)
 
-	likerepo := likes.NewLikeRepository(mongoClient)
-	likeHandler := likes.NewLikeHandler(likerepo)
+	searchRepo := search.NewSearchRepository(db.DB, authRepo, postRepo)
+	searchService := search.NewSearchService(searchRepo)
+	searchHandler := search.NewSearchHandler(searchService)
+
+	counterRepo := counters.NewCounterRepository(db.DB)
+	likesBackend, err := likes.LoadBackend()
+	if err != nil {
+		return nil, err
+	}
+	var likerepo likes.Repository
+	switch likesBackend {
+	case likes.BackendPostgres:
+		likerepo = likes.NewPostgresLikeRepository(db.DB)
+	default:
+		if mongoClient == nil {
+			return nil, fmt.Errorf("likes backend %q requires MONGO_URL", likesBackend)
+		}
+		likerepo = likes.NewLikeRepository(mongoClient, counterRepo)
+	}
+	log.Log(logger.InfoLevel, "Using %s likes backend", likesBackend)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := likerepo.EnsureIndexes(ctx); err != nil {
+		log.Log(logger.ErrorLevel, "Failed to ensure like indexes: %v", err)
+	}
+	userRepo := user.NewUserRepository(db.DB)
+	followRepo := follow.NewFollowRepository(db.DB, userRepo, notificationService)
+	likeService := likes.NewLikeService(likerepo, followRepo, reactions.NewTargetRepository(db.DB), authRepo, notificationService)
+	likeHandler := likes.NewLikeHandler(likerepo, likeService)
+
+	var reactionHandler *reactions.ReactionHandler
+	var reactionRepo reactions.Repository
+	if mongoClient != nil {
+		reactionRepo = reactions.NewReactionRepository(mongoClient)
+		if err := reactionRepo.EnsureIndexes(ctx); err != nil {
+			log.Log(logger.ErrorLevel, "Failed to ensure reaction indexes: %v", err)
+		}
+		reactionService := reactions.NewReactionService(reactionRepo, authRepo, followRepo, reactions.NewTargetRepository(db.DB), reactions.LoadAllowedReactions())
+		reactionHandler = reactions.NewReactionHandler(reactionService)
+	} else {
+		log.Log(logger.WarnLevel, "MONGO_URL is not set, reactions are disabled")
+	}
+
+	reconcileInterval, err := time.ParseDuration(env.LoadEnv("COUNTER_RECONCILE_INTERVAL"))
+	if err != nil || reconcileInterval <= 0 {
+		reconcileInterval = time.Hour
+	}
+	counters.NewReconciler(counterRepo, likerepo).Start(context.Background(), reconcileInterval)
+
+	accountConfig, err := account.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	accountRepo := account.NewAccountRepository(db.DB)
+	accountWorker := account.NewWorker(db.DB, accountRepo, likerepo, reactionRepo, cloudRepo, accountConfig)
+	accountWorker.Start(context.Background(), accountConfig.WorkerInterval)
+	accountService := account.NewAccountService(accountRepo, authRepo, accountWorker, accountConfig)
+	accountHandler := account.NewAccountHandler(accountService, sessionCache)
+
+	digestConfig, err := digest.LoadConfig()
+	if err != nil {
+		return nil, err
+	}
+	var digestHandler *digest.DigestHandler
+	if digestConfig.Enabled() {
+		digestRepo := digest.NewDigestRepository(db.DB)
+		digest.NewWorker(digestRepo, notificationService, mail, digestConfig).Start(context.Background(), digestConfig.WorkerInterval)
+		digestHandler = digest.NewDigestHandler(digestRepo, digestConfig)
+	} else {
+		log.Log(logger.InfoLevel, "DIGEST_SECRET is not set, email digest is disabled")
+	}
 
 	// Routes
 	api := r.Group("/api/v1")
 	authenticated := api.Group("")
-	authenticated.Use(middleware.AuthMiddleware())
+	authenticated.Use(middleware.AuthMiddleware(sessionCache))
+	requireVerified := middleware.RequireVerifiedEmail(authRepo.IsEmailVerified)
+
+	// Group yang boleh diakses personal access token sesuai scope-nya
+	middleware.SetPATLookup(authRepo.AuthenticateAccessToken)
+	withScopes := func(path string, write string) *gin.RouterGroup {
+		return api.Group(path, middleware.AuthMiddlewareWithScopes(sessionCache, middleware.ScopePolicy{
+			Read:  types.ScopeRead,
+			Write: write,
+		}))
+	}
 
+	if digestHandler != nil {
+		digest.RegisterRoutes(api.Group("/digest"), digestHandler)
+	}
 	auth := api.Group("/auth")
 	authhandler.RegisterRoutes(auth, authHandler)
-	post := authenticated.Group("/post")
-	posthandler.RegisterRoutes(post, postHandler)
+	post := withScopes("/post", types.ScopePostsWrite)
+	posthandler.RegisterRoutes(post, postHandler, requireVerified)
+	tags := withScopes("/tags", "")
+	posthandler.RegisterTagRoutes(tags, postHandler)
 
-	comment := authenticated.Group("/comment")
-	comments.RegisterRoutes(comment, commentHandler)
-	like := authenticated.Group("/likes")
+	comment := withScopes("/comment", types.ScopeCommentsWrite)
+	comments.RegisterRoutes(comment, commentHandler, requireVerified)
+	like := withScopes("/likes", types.ScopeLikesWrite)
 	likes.RegisterRoutes(like, likeHandler)
-	return r
+	if reactionHandler != nil {
+		reaction := withScopes("/reactions", types.ScopeLikesWrite)
+		reactions.RegisterRoutes(reaction, reactionHandler)
+	}
+	searchGroup := withScopes("/search", "")
+	search.RegisterRoutes(searchGroup, searchHandler)
+	accountGroup := authenticated.Group("/account")
+	account.RegisterRoutes(accountGroup, accountHandler)
+	notificationGroup := authenticated.Group("/notifications")
+	notifications.RegisterRoutes(notificationGroup, notificationHandler)
+	push.RegisterRoutes(authenticated.Group("/push"), pushHandler)
+
+	// Admin & moderasi: role dicek ulang ke database di setiap request
+	admin := authenticated.Group("/admin")
+	admin.Use(middleware.RequireRole(authRepo.GetUserRole, types.RoleAdmin))
+	authhandler.RegisterAdminRoutes(admin.Group("/users"), authHandler)
+
+	moderation := api.Group("/moderation", middleware.AuthMiddlewareWithScopes(sessionCache, middleware.ScopePolicy{
+		Read:  types.ScopeModeration,
+		Write: types.ScopeModeration,
+	}))
+	moderation.Use(middleware.RequireRole(authRepo.GetUserRole, types.RoleModerator, types.RoleAdmin))
+	posthandler.RegisterModerationRoutes(moderation.Group("/post"), postHandler)
+	comments.RegisterModerationRoutes(moderation.Group("/comment"), commentHandler)
+	return r, nil
 }
@@ -0,0 +1,16 @@
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/chatting-app/services/shared/middleware"
+)
+
+// RegisterJWKS mempublikasikan public key JWT agar service internal lain bisa memverifikasi token
+func RegisterJWKS(r *gin.Engine, ks *middleware.KeySet) {
+	r.GET("/.well-known/jwks.json", func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, ks.JWKS())
+	})
+}
@@ -0,0 +1,36 @@
+package likes
+
+import (
+	"fmt"
+
+	"github.com/wafi04/chatting-app/config/env"
+)
+
+const (
+	BackendMongo    = "mongo"
+	BackendPostgres = "postgres"
+
+	// MongoCollection nama koleksi like untuk backend Mongo
+	MongoCollection = "likes"
+)
+
+// LoadBackend membaca env LIKES_BACKEND, default ke Mongo supaya deployment lama tetap jalan
+func LoadBackend() (string, error) {
+	backend := env.LoadEnv("LIKES_BACKEND")
+	switch backend {
+	case "":
+		return BackendMongo, nil
+	case BackendMongo, BackendPostgres:
+		return backend, nil
+	default:
+		return "", fmt.Errorf("unknown likes backend: %s", backend)
+	}
+}
+
+// MongoDatabase membaca env MONGO_DATABASE, default "chatapp"
+func MongoDatabase() string {
+	if db := env.LoadEnv("MONGO_DATABASE"); db != "" {
+		return db
+	}
+	return "chatapp"
+}
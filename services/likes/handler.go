@@ -1,20 +1,25 @@
 package likes
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/wafi04/chatting-app/services/shared/middleware"
 	"github.com/wafi04/chatting-app/services/shared/pkg/response"
+	"github.com/wafi04/chatting-app/services/shared/types"
 )
 
 type LikeHandler struct {
 	likerepo Repository
+	likesrv  *LikeService
 }
 
-func NewLikeHandler(likerepo Repository) *LikeHandler {
+func NewLikeHandler(likerepo Repository, likesrv *LikeService) *LikeHandler {
 	return &LikeHandler{
 		likerepo: likerepo,
+		likesrv:  likesrv,
 	}
 }
 
@@ -32,8 +37,12 @@ func (lh *LikeHandler) HandleChangeLikeComment(c *gin.Context) {
 		return
 	}
 
-	data, err := lh.likerepo.ChangeLikeComment(c, user.UserId, commentID)
+	data, err := lh.likesrv.ChangeLikeComment(c.Request.Context(), user.UserId, commentID)
 	if err != nil {
+		if errors.Is(err, ErrTargetNotFound) {
+			response.SendErrorResponse(c, http.StatusNotFound, "Target not found")
+			return
+		}
 		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to change like", err.Error())
 		return
 	}
@@ -55,8 +64,12 @@ func (lh *LikeHandler) HandleChangeLikePost(c *gin.Context) {
 		return
 	}
 
-	err = lh.likerepo.ChangeLikePost(c, user.UserId, postID)
+	err = lh.likesrv.ChangeLikePost(c.Request.Context(), user.UserId, postID)
 	if err != nil {
+		if errors.Is(err, ErrTargetNotFound) {
+			response.SendErrorResponse(c, http.StatusNotFound, "Target not found")
+			return
+		}
 		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to change like", err.Error())
 		return
 	}
@@ -64,6 +77,104 @@ func (lh *LikeHandler) HandleChangeLikePost(c *gin.Context) {
 	response.SendSuccessResponse(c, http.StatusOK, "Like status changed successfully", nil)
 }
 
+// HandleLikeComment likes a comment, calling it again keeps the comment liked
+func (lh *LikeHandler) HandleLikeComment(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	commentID := c.Param("id")
+	if commentID == "" {
+		response.SendErrorResponse(c, http.StatusBadRequest, "Comment ID is required")
+		return
+	}
+
+	data, err := lh.likesrv.LikeComment(c.Request.Context(), user.UserId, commentID)
+	if err != nil {
+		if errors.Is(err, ErrTargetNotFound) {
+			response.SendErrorResponse(c, http.StatusNotFound, "Target not found")
+			return
+		}
+		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to like comment", err.Error())
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Comment liked successfully", data)
+}
+
+// HandleUnlikeComment removes a like from a comment, calling it again is a no-op
+func (lh *LikeHandler) HandleUnlikeComment(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	commentID := c.Param("id")
+	if commentID == "" {
+		response.SendErrorResponse(c, http.StatusBadRequest, "Comment ID is required")
+		return
+	}
+
+	if err := lh.likesrv.UnlikeComment(c.Request.Context(), user.UserId, commentID); err != nil {
+		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to unlike comment", err.Error())
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Comment unliked successfully", &IsLikes{Liked: false})
+}
+
+// HandleLikePost likes a post, calling it again keeps the post liked
+func (lh *LikeHandler) HandleLikePost(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	postID := c.Param("id")
+	if postID == "" {
+		response.SendErrorResponse(c, http.StatusBadRequest, "Post ID is required")
+		return
+	}
+
+	data, err := lh.likesrv.LikePost(c.Request.Context(), user.UserId, postID)
+	if err != nil {
+		if errors.Is(err, ErrTargetNotFound) {
+			response.SendErrorResponse(c, http.StatusNotFound, "Target not found")
+			return
+		}
+		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to like post", err.Error())
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Post liked successfully", data)
+}
+
+// HandleUnlikePost removes a like from a post, calling it again is a no-op
+func (lh *LikeHandler) HandleUnlikePost(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	postID := c.Param("id")
+	if postID == "" {
+		response.SendErrorResponse(c, http.StatusBadRequest, "Post ID is required")
+		return
+	}
+
+	if err := lh.likesrv.UnlikePost(c.Request.Context(), user.UserId, postID); err != nil {
+		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to unlike post", err.Error())
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Post unliked successfully", &IsLikes{Liked: false})
+}
+
 // HandleGetUserCommentLikes gets all comments liked by a user
 func (lh *LikeHandler) HandleGetUserCommentLikes(c *gin.Context) {
 	user, err := middleware.GetUserFromGinContext(c)
@@ -173,3 +284,54 @@ func (lh *LikeHandler) HandleGetCommentLikedUser(c *gin.Context) {
 
 	response.SendSuccessResponse(c, http.StatusOK, "Post likes count retrieved successfully", count)
 }
+
+// HandleGetPostLikers lists users who liked a post
+func (lh *LikeHandler) HandleGetPostLikers(c *gin.Context) {
+	lh.handleGetLikers(c, "post_id")
+}
+
+// HandleGetCommentLikers lists users who liked a comment
+func (lh *LikeHandler) HandleGetCommentLikers(c *gin.Context) {
+	lh.handleGetLikers(c, "comment_id")
+}
+
+func (lh *LikeHandler) handleGetLikers(c *gin.Context, field string) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	targetID := c.Param("id")
+	if targetID == "" {
+		response.SendErrorResponse(c, http.StatusBadRequest, "ID is required")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	data, err := lh.likesrv.ListLikers(c, &types.ListLikersRequest{
+		ViewerID: user.UserId,
+		Field:    field,
+		TargetID: targetID,
+		Page:     int64(page),
+		Limit:    int64(limit),
+	})
+	if err != nil {
+		if errors.Is(err, ErrTargetNotFound) {
+			response.SendErrorResponse(c, http.StatusNotFound, "Target not found")
+			return
+		}
+		response.SendErrorResponseWithDetails(c, http.StatusInternalServerError, "Failed to get likers", err.Error())
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Likers retrieved successfully", data)
+}
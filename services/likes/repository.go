@@ -5,104 +5,302 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
 	"github.com/wafi04/chatting-app/services/shared/types"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type LikeRepository struct {
 	mongoClient *mongo.Client
+	database    string
+	counter     Counter
+	log         *logger.Logger
+}
+
+// Counter menyimpan jumlah like yang didenormalisasi di tabel posts dan comments.
+// Like disimpan di Mongo sehingga counter tidak bisa ikut satu transaksi dengan
+// like-nya. Jika update counter gagal, perubahan like dibatalkan (kompensasi) dan
+// request gagal; selisih yang tetap tersisa (misalnya proses mati di antara keduanya
+// atau kompensasi ikut gagal) diperbaiki oleh job reconciliation.
+type Counter interface {
+	IncrementPostLikes(ctx context.Context, postId string, delta int64) error
+	IncrementCommentLikes(ctx context.Context, commentId string, delta int64) error
+	PostLikeCount(ctx context.Context, postId string) (int64, error)
+	CommentLikeCount(ctx context.Context, commentId string) (int64, error)
 }
 
 type Repository interface {
+	EnsureIndexes(ctx context.Context) error
 	ChangeLikeComment(ctx context.Context, userId string, commentId string) (*types.LikeComment, error)
 	ChangeLikePost(ctx context.Context, userId string, postId string) error
+	LikeComment(ctx context.Context, userId string, commentId string) (*types.LikeComment, error)
+	UnlikeComment(ctx context.Context, userId string, commentId string) error
+	LikePost(ctx context.Context, userId string, postId string) (*types.LikePost, error)
+	UnlikePost(ctx context.Context, userId string, postId string) error
 	GetCommentLikesCount(ctx context.Context, commentId string) (int64, error)
 	GetUserLiked(ctx context.Context, types, commentID, userID string) (*IsLikes, error)
 	GetPostLikesCount(ctx context.Context, postId string) (int64, error)
 	GetUserCommentLikes(ctx context.Context, userId string) ([]types.LikeComment, error)
 	GetUserPostLikes(ctx context.Context, userId string) ([]types.LikePost, error)
+	GetLikers(ctx context.Context, field, targetId string, filter *LikerFilter, skip, limit int64) ([]types.Like, int64, error)
+	GetPostLikeCounts(ctx context.Context, postIds []string) (map[string]int64, error)
+	GetCommentLikeCounts(ctx context.Context, commentIds []string) (map[string]int64, error)
+	DeleteUserLikes(ctx context.Context, userId string, postIds, commentIds []string) (int64, error)
 }
 
-func NewLikeRepository(mongoClient *mongo.Client) Repository {
+func NewLikeRepository(mongoClient *mongo.Client, counter Counter) Repository {
 	return &LikeRepository{
 		mongoClient: mongoClient,
+		database:    MongoDatabase(),
+		counter:     counter,
+		log:         logger.NewLogger(),
 	}
 }
 
-func (lr *LikeRepository) ChangeLikeComment(ctx context.Context, userId string, commentId string) (*types.LikeComment, error) {
-	likeCollection := lr.mongoClient.Database("chatapp").Collection("likes")
+func (lr *LikeRepository) collection() *mongo.Collection {
+	return lr.mongoClient.Database(lr.database).Collection(MongoCollection)
+}
 
-	filter := bson.M{
-		"user_id":    userId,
-		"comment_id": commentId,
+// EnsureIndexes membuat unique index (user_id, post_id) dan (user_id, comment_id)
+// supaya satu user hanya bisa punya satu like per target. Index gagal dibuat selama
+// masih ada like ganda dari data lama; jalankan cmd/dedupe-likes terlebih dahulu.
+func (lr *LikeRepository) EnsureIndexes(ctx context.Context) error {
+	likeCollection := lr.collection()
+
+	_, err := likeCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "post_id", Value: 1}},
+			Options: options.Index().
+				SetName("uniq_user_post").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"post_id": bson.M{"$exists": true}}),
+		},
+		{
+			Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "comment_id", Value: 1}},
+			Options: options.Index().
+				SetName("uniq_user_comment").
+				SetUnique(true).
+				SetPartialFilterExpression(bson.M{"comment_id": bson.M{"$exists": true}}),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create like indexes (run cmd/dedupe-likes if duplicates exist): %w", err)
 	}
 
-	// Check if like exists
-	exists := likeCollection.FindOne(ctx, filter)
-	if exists.Err() == nil {
-		// Like exists, so remove it
-		_, err := likeCollection.DeleteOne(ctx, filter)
+	return nil
+}
+
+// RemoveDuplicateLikes menyisakan like paling awal untuk setiap kombinasi user dan target
+// dan mengembalikan jumlah like yang dihapus (atau yang akan dihapus jika dryRun).
+// Hanya dipanggil dari cmd/dedupe-likes, bukan saat server start.
+func (lr *LikeRepository) RemoveDuplicateLikes(ctx context.Context, dryRun bool) (int64, error) {
+	likeCollection := lr.collection()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "created_at", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id": bson.M{
+				"user_id":    "$user_id",
+				"post_id":    "$post_id",
+				"comment_id": "$comment_id",
+			},
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	}
+
+	cursor, err := likeCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find duplicate likes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var duplicates []struct {
+		IDs []primitive.ObjectID `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &duplicates); err != nil {
+		return 0, fmt.Errorf("failed to decode duplicate likes: %w", err)
+	}
+
+	var removed int64
+	for _, dup := range duplicates {
+		if dryRun {
+			removed += int64(len(dup.IDs) - 1)
+			continue
+		}
+		res, err := likeCollection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": dup.IDs[1:]}})
 		if err != nil {
-			return nil, fmt.Errorf("failed to remove like: %w", err)
+			return removed, fmt.Errorf("failed to remove duplicate likes: %w", err)
 		}
-		return nil, nil
+		removed += res.DeletedCount
+	}
+
+	return removed, nil
+}
+
+// upsertLike membuat like jika belum ada dan mengembalikan dokumen yang tersimpan.
+// Operasi ini idempotent: memanggilnya berulang kali tetap menghasilkan satu like.
+// Nilai bool bernilai true jika like baru saja dibuat oleh panggilan ini.
+func (lr *LikeRepository) upsertLike(ctx context.Context, filter bson.M, result interface{}) (bool, error) {
+	likeCollection := lr.collection()
+
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"_id":        primitive.NewObjectID(),
+			"created_at": time.Now(),
+		},
+	}
+
+	res, err := likeCollection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	// Duplicate key berarti request lain memenangkan race upsert, like sudah ada
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		return false, fmt.Errorf("failed to create like: %w", err)
+	}
+	inserted := err == nil && res.UpsertedCount > 0
+
+	if inserted {
+		if err := lr.adjustCounter(ctx, filter, 1); err != nil {
+			// Like dibatalkan supaya tidak ada like yang tidak tercatat di counter
+			if _, delErr := likeCollection.DeleteOne(ctx, bson.M{"_id": res.UpsertedID}); delErr != nil {
+				lr.log.Log(logger.ErrorLevel, "Failed to roll back like after counter error: %v", delErr)
+			}
+			return false, err
+		}
+	}
+
+	if err := likeCollection.FindOne(ctx, filter).Decode(result); err != nil {
+		return inserted, fmt.Errorf("failed to get like: %w", err)
+	}
+
+	return inserted, nil
+}
+
+// adjustCounter meneruskan perubahan jumlah like ke Counter
+func (lr *LikeRepository) adjustCounter(ctx context.Context, filter bson.M, delta int64) error {
+	if lr.counter == nil {
+		return nil
 	}
 
-	// Like doesn't exist, create it
-	like := &types.LikeComment{
-		ID:        primitive.NewObjectID(),
-		UserID:    userId,
-		CommentID: &commentId,
-		CreatedAt: time.Now(),
+	if postId, ok := filter["post_id"].(string); ok {
+		return lr.counter.IncrementPostLikes(ctx, postId, delta)
+	}
+	if commentId, ok := filter["comment_id"].(string); ok {
+		return lr.counter.IncrementCommentLikes(ctx, commentId, delta)
 	}
+	return nil
+}
 
-	_, err := likeCollection.InsertOne(ctx, like)
+// deleteLike menghapus like jika ada dan mengembalikan true jika ada dokumen yang terhapus
+func (lr *LikeRepository) deleteLike(ctx context.Context, filter bson.M) (bool, error) {
+	likeCollection := lr.collection()
+
+	var deleted bson.M
+	err := likeCollection.FindOneAndDelete(ctx, filter).Decode(&deleted)
+	if err == mongo.ErrNoDocuments {
+		return false, nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create like: %w", err)
+		return false, fmt.Errorf("failed to remove like: %w", err)
 	}
 
-	return like, nil
+	if err := lr.adjustCounter(ctx, filter, -1); err != nil {
+		// Like dikembalikan supaya counter tetap sama dengan jumlah like
+		if _, insErr := likeCollection.InsertOne(ctx, deleted); insErr != nil && !mongo.IsDuplicateKeyError(insErr) {
+			lr.log.Log(logger.ErrorLevel, "Failed to restore like after counter error: %v", insErr)
+		}
+		return false, err
+	}
+	return true, nil
 }
 
-func (lr *LikeRepository) ChangeLikePost(ctx context.Context, userId string, postId string) error {
-	likeCollection := lr.mongoClient.Database("chatapp").Collection("likes")
+func (lr *LikeRepository) LikeComment(ctx context.Context, userId string, commentId string) (*types.LikeComment, error) {
+	var like types.LikeComment
+	filter := bson.M{
+		"user_id":    userId,
+		"comment_id": commentId,
+	}
 
+	if _, err := lr.upsertLike(ctx, filter, &like); err != nil {
+		return nil, err
+	}
+
+	return &like, nil
+}
+
+func (lr *LikeRepository) UnlikeComment(ctx context.Context, userId string, commentId string) error {
+	_, err := lr.deleteLike(ctx, bson.M{
+		"user_id":    userId,
+		"comment_id": commentId,
+	})
+	return err
+}
+
+func (lr *LikeRepository) LikePost(ctx context.Context, userId string, postId string) (*types.LikePost, error) {
+	var like types.LikePost
 	filter := bson.M{
 		"user_id": userId,
 		"post_id": postId,
 	}
 
-	// Check if like exists
-	exists := likeCollection.FindOne(ctx, filter)
-	if exists.Err() == nil {
-		// Like exists, so remove it
-		_, err := likeCollection.DeleteOne(ctx, filter)
-		if err != nil {
-			return fmt.Errorf("failed to remove like: %w", err)
-		}
-		return nil
+	if _, err := lr.upsertLike(ctx, filter, &like); err != nil {
+		return nil, err
 	}
 
-	// Like doesn't exist, create it
-	like := &types.LikePost{
-		ID:        primitive.NewObjectID(),
-		UserID:    userId,
-		PostID:    &postId,
-		CreatedAt: time.Now(),
+	return &like, nil
+}
+
+func (lr *LikeRepository) UnlikePost(ctx context.Context, userId string, postId string) error {
+	_, err := lr.deleteLike(ctx, bson.M{
+		"user_id": userId,
+		"post_id": postId,
+	})
+	return err
+}
+
+// ChangeLikeComment toggle like pada comment: hapus jika sudah ada, buat jika belum
+func (lr *LikeRepository) ChangeLikeComment(ctx context.Context, userId string, commentId string) (*types.LikeComment, error) {
+	deleted, err := lr.deleteLike(ctx, bson.M{
+		"user_id":    userId,
+		"comment_id": commentId,
+	})
+	if err != nil {
+		return nil, err
 	}
+	if deleted {
+		return nil, nil
+	}
+
+	return lr.LikeComment(ctx, userId, commentId)
+}
 
-	_, err := likeCollection.InsertOne(ctx, like)
+// ChangeLikePost toggle like pada post: hapus jika sudah ada, buat jika belum
+func (lr *LikeRepository) ChangeLikePost(ctx context.Context, userId string, postId string) error {
+	deleted, err := lr.deleteLike(ctx, bson.M{
+		"user_id": userId,
+		"post_id": postId,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to create like: %w", err)
+		return err
+	}
+	if deleted {
+		return nil
 	}
 
-	return nil
+	_, err = lr.LikePost(ctx, userId, postId)
+	return err
 }
 
+// GetCommentLikesCount membaca comments.like_count; CountDocuments hanya dipakai
+// jika repository dibuat tanpa Counter
 func (lr *LikeRepository) GetCommentLikesCount(ctx context.Context, commentId string) (int64, error) {
-	likeCollection := lr.mongoClient.Database("chatapp").Collection("likes")
+	if lr.counter != nil {
+		return lr.counter.CommentLikeCount(ctx, commentId)
+	}
+	likeCollection := lr.collection()
 
 	filter := bson.M{
 		"comment_id": commentId,
@@ -116,8 +314,13 @@ func (lr *LikeRepository) GetCommentLikesCount(ctx context.Context, commentId st
 	return count, nil
 }
 
+// GetPostLikesCount membaca posts.like_count; CountDocuments hanya dipakai
+// jika repository dibuat tanpa Counter
 func (lr *LikeRepository) GetPostLikesCount(ctx context.Context, postId string) (int64, error) {
-	likeCollection := lr.mongoClient.Database("chatapp").Collection("likes")
+	if lr.counter != nil {
+		return lr.counter.PostLikeCount(ctx, postId)
+	}
+	likeCollection := lr.collection()
 
 	filter := bson.M{
 		"post_id": postId,
@@ -132,7 +335,7 @@ func (lr *LikeRepository) GetPostLikesCount(ctx context.Context, postId string)
 }
 
 func (lr *LikeRepository) GetUserCommentLikes(ctx context.Context, userId string) ([]types.LikeComment, error) {
-	likeCollection := lr.mongoClient.Database("chatapp").Collection("likes")
+	likeCollection := lr.collection()
 
 	filter := bson.M{
 		"user_id":    userId,
@@ -153,7 +356,7 @@ func (lr *LikeRepository) GetUserCommentLikes(ctx context.Context, userId string
 	return likes, nil
 }
 func (lr *LikeRepository) GetUserLiked(ctx context.Context, types, commentID, userID string) (*IsLikes, error) {
-	likeCollection := lr.mongoClient.Database("chatapp").Collection("likes")
+	likeCollection := lr.collection()
 
 	filter := bson.M{
 		"user_id": userID,
@@ -176,7 +379,7 @@ type IsLikes struct {
 }
 
 func (lr *LikeRepository) GetUserPostLikes(ctx context.Context, userId string) ([]types.LikePost, error) {
-	likeCollection := lr.mongoClient.Database("chatapp").Collection("likes")
+	likeCollection := lr.collection()
 
 	filter := bson.M{
 		"user_id": userId,
@@ -196,3 +399,118 @@ func (lr *LikeRepository) GetUserPostLikes(ctx context.Context, userId string) (
 
 	return likes, nil
 }
+
+// GetPostLikeCounts menghitung ulang jumlah like post yang diminta langsung dari koleksi likes
+func (lr *LikeRepository) GetPostLikeCounts(ctx context.Context, postIds []string) (map[string]int64, error) {
+	return lr.countLikesBy(ctx, "post_id", postIds)
+}
+
+// GetCommentLikeCounts menghitung ulang jumlah like comment yang diminta langsung dari koleksi likes
+func (lr *LikeRepository) GetCommentLikeCounts(ctx context.Context, commentIds []string) (map[string]int64, error) {
+	return lr.countLikesBy(ctx, "comment_id", commentIds)
+}
+
+func (lr *LikeRepository) countLikesBy(ctx context.Context, field string, targetIds []string) (map[string]int64, error) {
+	likeCollection := lr.collection()
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{field: bson.M{"$in": targetIds}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}}},
+	}
+
+	cursor, err := likeCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count likes by %s: %w", field, err)
+	}
+	defer cursor.Close(ctx)
+
+	counts := make(map[string]int64)
+	for cursor.Next(ctx) {
+		var row struct {
+			ID    string `bson:"_id"`
+			Count int64  `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, fmt.Errorf("failed to decode like count: %w", err)
+		}
+		counts[row.ID] = row.Count
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error while iterating like counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+// LikerFilter membatasi user_id like yang diambil oleh GetLikers.
+// Include nil berarti semua user, Exclude selalu dikecualikan.
+type LikerFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// GetLikers mengembalikan like pada target (field = post_id atau comment_id), terbaru lebih dulu,
+// beserta jumlah total like yang cocok dengan filter
+func (lr *LikeRepository) GetLikers(ctx context.Context, field, targetId string, filter *LikerFilter, skip, limit int64) ([]types.Like, int64, error) {
+	likeCollection := lr.collection()
+
+	userFilter := bson.M{}
+	if filter != nil {
+		if filter.Include != nil {
+			userFilter["$in"] = filter.Include
+		}
+		if len(filter.Exclude) > 0 {
+			userFilter["$nin"] = filter.Exclude
+		}
+	}
+
+	query := bson.M{field: targetId}
+	if len(userFilter) > 0 {
+		query["user_id"] = userFilter
+	}
+
+	total, err := likeCollection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count likers: %w", err)
+	}
+	if limit <= 0 || skip >= total {
+		return []types.Like{}, total, nil
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(skip).
+		SetLimit(limit)
+
+	cursor, err := likeCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get likers: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	likes := []types.Like{}
+	if err = cursor.All(ctx, &likes); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode likers: %w", err)
+	}
+
+	return likes, total, nil
+}
+
+// DeleteUserLikes menghapus like milik user beserta like orang lain pada post dan comment
+// user tersebut. Counter tidak disesuaikan di sini, job reconciliation yang membetulkannya.
+func (lr *LikeRepository) DeleteUserLikes(ctx context.Context, userId string, postIds, commentIds []string) (int64, error) {
+	conditions := bson.A{bson.M{"user_id": userId}}
+	if len(postIds) > 0 {
+		conditions = append(conditions, bson.M{"post_id": bson.M{"$in": postIds}})
+	}
+	if len(commentIds) > 0 {
+		conditions = append(conditions, bson.M{"comment_id": bson.M{"$in": commentIds}})
+	}
+
+	res, err := lr.collection().DeleteMany(ctx, bson.M{"$or": conditions})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete user likes: %w", err)
+	}
+	return res.DeletedCount, nil
+}
@@ -0,0 +1,443 @@
+package likes
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/wafi04/chatting-app/services/shared/types"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PostgresLikeRepository menyimpan like di tabel likes. Karena satu database dengan
+// posts dan comments, counter like di-update dalam transaksi yang sama dengan like-nya.
+type PostgresLikeRepository struct {
+	db *sqlx.DB
+}
+
+func NewPostgresLikeRepository(db *sqlx.DB) Repository {
+	return &PostgresLikeRepository{
+		db: db,
+	}
+}
+
+// counterTables memetakan kolom target ke tabel yang menyimpan like_count-nya
+var counterTables = map[string]string{
+	"post_id":    "posts",
+	"comment_id": "comments",
+}
+
+func validateField(field string) error {
+	if _, ok := counterTables[field]; !ok {
+		return fmt.Errorf("invalid like target: %s", field)
+	}
+	return nil
+}
+
+func parseLikeID(id string) primitive.ObjectID {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID
+	}
+	return oid
+}
+
+func (pr *PostgresLikeRepository) EnsureIndexes(ctx context.Context) error {
+	queries := []string{
+		`CREATE UNIQUE INDEX IF NOT EXISTS uniq_likes_user_post ON likes (user_id, post_id) WHERE post_id IS NOT NULL`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS uniq_likes_user_comment ON likes (user_id, comment_id) WHERE comment_id IS NOT NULL`,
+	}
+
+	for _, query := range queries {
+		if _, err := pr.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("failed to create like indexes: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// withTx menjalankan fn dalam transaksi, commit jika fn sukses dan rollback jika gagal
+func (pr *PostgresLikeRepository) withTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := pr.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (pr *PostgresLikeRepository) adjustCounter(ctx context.Context, tx *sqlx.Tx, field, targetId string, delta int64) error {
+	query := fmt.Sprintf("UPDATE %s SET like_count = GREATEST(like_count + $1, 0) WHERE id = $2", counterTables[field])
+	if _, err := tx.ExecContext(ctx, query, delta, targetId); err != nil {
+		return fmt.Errorf("failed to update like count: %w", err)
+	}
+	return nil
+}
+
+// insertLike membuat like jika belum ada dan mengembalikan like yang tersimpan
+func (pr *PostgresLikeRepository) insertLike(ctx context.Context, tx *sqlx.Tx, field, userId, targetId string) (*types.Like, error) {
+	insertQuery := fmt.Sprintf(`
+    INSERT INTO likes (id, user_id, %[1]s, created_at)
+    VALUES ($1, $2, $3, $4)
+    ON CONFLICT (user_id, %[1]s) WHERE %[1]s IS NOT NULL DO NOTHING
+    RETURNING id, created_at
+    `, field)
+
+	var id string
+	var createdAt time.Time
+	err := tx.QueryRowContext(ctx, insertQuery,
+		primitive.NewObjectID().Hex(),
+		userId,
+		targetId,
+		time.Now(),
+	).Scan(&id, &createdAt)
+
+	switch {
+	case err == sql.ErrNoRows:
+		// Like sudah ada, ambil yang tersimpan
+		selectQuery := fmt.Sprintf("SELECT id, created_at FROM likes WHERE user_id = $1 AND %s = $2", field)
+		if err := tx.QueryRowContext(ctx, selectQuery, userId, targetId).Scan(&id, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to get like: %w", err)
+		}
+	case isForeignKeyViolation(err):
+		// Post atau comment sudah dihapus (atau tidak pernah ada)
+		return nil, ErrTargetNotFound
+	case err != nil:
+		return nil, fmt.Errorf("failed to create like: %w", err)
+	default:
+		if err := pr.adjustCounter(ctx, tx, field, targetId, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	like := &types.Like{
+		ID:        parseLikeID(id),
+		UserID:    userId,
+		CreatedAt: createdAt,
+	}
+	if field == "post_id" {
+		like.PostID = &targetId
+	} else {
+		like.CommentID = &targetId
+	}
+	return like, nil
+}
+
+// deleteLike menghapus like jika ada dan mengembalikan true jika ada baris yang terhapus
+func (pr *PostgresLikeRepository) deleteLike(ctx context.Context, tx *sqlx.Tx, field, userId, targetId string) (bool, error) {
+	query := fmt.Sprintf("DELETE FROM likes WHERE user_id = $1 AND %s = $2", field)
+	result, err := tx.ExecContext(ctx, query, userId, targetId)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove like: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	return true, pr.adjustCounter(ctx, tx, field, targetId, -1)
+}
+
+func (pr *PostgresLikeRepository) like(ctx context.Context, field, userId, targetId string) (*types.Like, error) {
+	var like *types.Like
+	err := pr.withTx(ctx, func(tx *sqlx.Tx) error {
+		var err error
+		like, err = pr.insertLike(ctx, tx, field, userId, targetId)
+		return err
+	})
+	return like, err
+}
+
+func (pr *PostgresLikeRepository) unlike(ctx context.Context, field, userId, targetId string) error {
+	return pr.withTx(ctx, func(tx *sqlx.Tx) error {
+		_, err := pr.deleteLike(ctx, tx, field, userId, targetId)
+		return err
+	})
+}
+
+// toggle menghapus like jika sudah ada atau membuatnya jika belum, mengembalikan nil jika like dihapus
+func (pr *PostgresLikeRepository) toggle(ctx context.Context, field, userId, targetId string) (*types.Like, error) {
+	var like *types.Like
+	err := pr.withTx(ctx, func(tx *sqlx.Tx) error {
+		deleted, err := pr.deleteLike(ctx, tx, field, userId, targetId)
+		if err != nil || deleted {
+			return err
+		}
+		like, err = pr.insertLike(ctx, tx, field, userId, targetId)
+		return err
+	})
+	return like, err
+}
+
+func (pr *PostgresLikeRepository) LikeComment(ctx context.Context, userId string, commentId string) (*types.LikeComment, error) {
+	like, err := pr.like(ctx, "comment_id", userId, commentId)
+	if err != nil {
+		return nil, err
+	}
+	return toLikeComment(like), nil
+}
+
+func (pr *PostgresLikeRepository) UnlikeComment(ctx context.Context, userId string, commentId string) error {
+	return pr.unlike(ctx, "comment_id", userId, commentId)
+}
+
+func (pr *PostgresLikeRepository) LikePost(ctx context.Context, userId string, postId string) (*types.LikePost, error) {
+	like, err := pr.like(ctx, "post_id", userId, postId)
+	if err != nil {
+		return nil, err
+	}
+	return toLikePost(like), nil
+}
+
+func (pr *PostgresLikeRepository) UnlikePost(ctx context.Context, userId string, postId string) error {
+	return pr.unlike(ctx, "post_id", userId, postId)
+}
+
+func (pr *PostgresLikeRepository) ChangeLikeComment(ctx context.Context, userId string, commentId string) (*types.LikeComment, error) {
+	like, err := pr.toggle(ctx, "comment_id", userId, commentId)
+	if err != nil || like == nil {
+		return nil, err
+	}
+	return toLikeComment(like), nil
+}
+
+func (pr *PostgresLikeRepository) ChangeLikePost(ctx context.Context, userId string, postId string) error {
+	_, err := pr.toggle(ctx, "post_id", userId, postId)
+	return err
+}
+
+// countLikes membaca like_count yang didenormalisasi; target yang tidak ada dianggap 0
+func (pr *PostgresLikeRepository) countLikes(ctx context.Context, field, targetId string) (int64, error) {
+	var count int64
+	query := fmt.Sprintf("SELECT like_count FROM %s WHERE id = $1", counterTables[field])
+	err := pr.db.QueryRowContext(ctx, query, targetId).Scan(&count)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get likes count: %w", err)
+	}
+	return count, nil
+}
+
+func (pr *PostgresLikeRepository) GetCommentLikesCount(ctx context.Context, commentId string) (int64, error) {
+	return pr.countLikes(ctx, "comment_id", commentId)
+}
+
+func (pr *PostgresLikeRepository) GetPostLikesCount(ctx context.Context, postId string) (int64, error) {
+	return pr.countLikes(ctx, "post_id", postId)
+}
+
+func (pr *PostgresLikeRepository) GetUserLiked(ctx context.Context, types, commentID, userID string) (*IsLikes, error) {
+	if err := validateField(types); err != nil {
+		return nil, err
+	}
+
+	var liked bool
+	query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM likes WHERE user_id = $1 AND %s = $2)", types)
+	if err := pr.db.QueryRowContext(ctx, query, userID, commentID).Scan(&liked); err != nil {
+		return nil, err
+	}
+
+	return &IsLikes{Liked: liked}, nil
+}
+
+// queryLikes menjalankan query yang mengembalikan kolom id, user_id, post_id, comment_id, created_at
+func (pr *PostgresLikeRepository) queryLikes(ctx context.Context, query string, args ...interface{}) ([]types.Like, error) {
+	rows, err := pr.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get likes: %w", err)
+	}
+	defer rows.Close()
+
+	likes := []types.Like{}
+	for rows.Next() {
+		var like types.Like
+		var id string
+		var postID, commentID sql.NullString
+
+		if err := rows.Scan(&id, &like.UserID, &postID, &commentID, &like.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan like: %w", err)
+		}
+		like.ID = parseLikeID(id)
+		if postID.Valid {
+			like.PostID = &postID.String
+		}
+		if commentID.Valid {
+			like.CommentID = &commentID.String
+		}
+		likes = append(likes, like)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while iterating likes: %w", err)
+	}
+
+	return likes, nil
+}
+
+func (pr *PostgresLikeRepository) GetUserCommentLikes(ctx context.Context, userId string) ([]types.LikeComment, error) {
+	likes, err := pr.queryLikes(ctx, `
+    SELECT id, user_id, post_id, comment_id, created_at
+    FROM likes
+    WHERE user_id = $1 AND comment_id IS NOT NULL
+    ORDER BY created_at DESC
+    `, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user comment likes: %w", err)
+	}
+
+	result := make([]types.LikeComment, 0, len(likes))
+	for i := range likes {
+		result = append(result, *toLikeComment(&likes[i]))
+	}
+	return result, nil
+}
+
+func (pr *PostgresLikeRepository) GetUserPostLikes(ctx context.Context, userId string) ([]types.LikePost, error) {
+	likes, err := pr.queryLikes(ctx, `
+    SELECT id, user_id, post_id, comment_id, created_at
+    FROM likes
+    WHERE user_id = $1 AND post_id IS NOT NULL
+    ORDER BY created_at DESC
+    `, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user post likes: %w", err)
+	}
+
+	result := make([]types.LikePost, 0, len(likes))
+	for i := range likes {
+		result = append(result, *toLikePost(&likes[i]))
+	}
+	return result, nil
+}
+
+func (pr *PostgresLikeRepository) GetLikers(ctx context.Context, field, targetId string, filter *LikerFilter, skip, limit int64) ([]types.Like, int64, error) {
+	if err := validateField(field); err != nil {
+		return nil, 0, err
+	}
+
+	// $2 NULL berarti tanpa batasan include
+	var include interface{}
+	exclude := []string{}
+	if filter != nil {
+		if filter.Include != nil {
+			include = pq.Array(filter.Include)
+		}
+		exclude = append(exclude, filter.Exclude...)
+	}
+
+	where := fmt.Sprintf(`%s = $1
+        AND ($2::text[] IS NULL OR user_id = ANY($2::text[]))
+        AND NOT (user_id = ANY($3::text[]))`, field)
+
+	var total int64
+	err := pr.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM likes WHERE "+where,
+		targetId, include, pq.Array(exclude),
+	).Scan(&total)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count likers: %w", err)
+	}
+	if limit <= 0 || skip >= total {
+		return []types.Like{}, total, nil
+	}
+
+	likes, err := pr.queryLikes(ctx, `
+    SELECT id, user_id, post_id, comment_id, created_at
+    FROM likes
+    WHERE `+where+`
+    ORDER BY created_at DESC
+    LIMIT $4 OFFSET $5
+    `, targetId, include, pq.Array(exclude), limit, skip)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get likers: %w", err)
+	}
+
+	return likes, total, nil
+}
+
+func (pr *PostgresLikeRepository) countLikesBy(ctx context.Context, field string, targetIds []string) (map[string]int64, error) {
+	query := fmt.Sprintf("SELECT %[1]s, COUNT(*) FROM likes WHERE %[1]s = ANY($1) GROUP BY %[1]s", field)
+	rows, err := pr.db.QueryContext(ctx, query, pq.Array(targetIds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count likes by %s: %w", field, err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var id string
+		var count int64
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan like count: %w", err)
+		}
+		counts[id] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while iterating like counts: %w", err)
+	}
+
+	return counts, nil
+}
+
+func (pr *PostgresLikeRepository) GetPostLikeCounts(ctx context.Context, postIds []string) (map[string]int64, error) {
+	return pr.countLikesBy(ctx, "post_id", postIds)
+}
+
+func (pr *PostgresLikeRepository) GetCommentLikeCounts(ctx context.Context, commentIds []string) (map[string]int64, error) {
+	return pr.countLikesBy(ctx, "comment_id", commentIds)
+}
+
+func toLikePost(like *types.Like) *types.LikePost {
+	return &types.LikePost{
+		ID:        like.ID,
+		UserID:    like.UserID,
+		PostID:    like.PostID,
+		CreatedAt: like.CreatedAt,
+	}
+}
+
+func toLikeComment(like *types.Like) *types.LikeComment {
+	return &types.LikeComment{
+		ID:        like.ID,
+		UserID:    like.UserID,
+		CommentID: like.CommentID,
+		CreatedAt: like.CreatedAt,
+	}
+}
+
+func (pr *PostgresLikeRepository) DeleteUserLikes(ctx context.Context, userId string, postIds, commentIds []string) (int64, error) {
+	res, err := pr.db.ExecContext(ctx, `
+    DELETE FROM likes
+    WHERE user_id = $1 OR post_id = ANY($2) OR comment_id = ANY($3)
+    `, userId, pq.Array(postIds), pq.Array(commentIds))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete user likes: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// isForeignKeyViolation mengenali error 23503 dari constraint likes_*_fkey
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
@@ -0,0 +1,137 @@
+package likes_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/chatting-app/services/likes"
+)
+
+const likeID = "65a1b2c3d4e5f6a7b8c9d0e1"
+
+func newMockPostgresRepository(t *testing.T) (likes.Repository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return likes.NewPostgresLikeRepository(sqlx.NewDb(db, "sqlmock")), mock
+}
+
+func TestLikePostIncrementsCounterInSameTransaction(t *testing.T) {
+	repo, mock := newMockPostgresRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO likes \(id, user_id, post_id, created_at\)`).
+		WithArgs(sqlmock.AnyArg(), "u1", "P1", sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(likeID, time.Now()))
+	mock.ExpectExec(`UPDATE posts SET like_count = GREATEST\(like_count \+ \$1, 0\) WHERE id = \$2`).
+		WithArgs(int64(1), "P1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	like, err := repo.LikePost(context.Background(), "u1", "P1")
+	require.NoError(t, err)
+	assert.Equal(t, likeID, like.ID.Hex())
+	assert.Equal(t, "P1", *like.PostID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLikePostExistingDoesNotCountTwice(t *testing.T) {
+	repo, mock := newMockPostgresRepository(t)
+
+	mock.ExpectBegin()
+	// ON CONFLICT DO NOTHING tidak mengembalikan baris jika like sudah ada
+	mock.ExpectQuery(`INSERT INTO likes`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}))
+	mock.ExpectQuery(`SELECT id, created_at FROM likes WHERE user_id = \$1 AND post_id = \$2`).
+		WithArgs("u1", "P1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(likeID, time.Now()))
+	mock.ExpectCommit()
+
+	_, err := repo.LikePost(context.Background(), "u1", "P1")
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestChangeLikePostRemovesExistingLike(t *testing.T) {
+	repo, mock := newMockPostgresRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM likes WHERE user_id = \$1 AND post_id = \$2`).
+		WithArgs("u1", "P1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE posts SET like_count`).
+		WithArgs(int64(-1), "P1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.ChangeLikePost(context.Background(), "u1", "P1"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLikeCommentRollsBackWhenCounterFails(t *testing.T) {
+	repo, mock := newMockPostgresRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO likes \(id, user_id, comment_id, created_at\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(likeID, time.Now()))
+	mock.ExpectExec(`UPDATE comments SET like_count`).
+		WithArgs(int64(1), "C1").
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	_, err := repo.LikeComment(context.Background(), "u1", "C1")
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLikePostMissingTargetIsNotFound(t *testing.T) {
+	repo, mock := newMockPostgresRepository(t)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO likes \(id, user_id, post_id, created_at\)`).
+		WillReturnError(&pgconn.PgError{Code: "23503", ConstraintName: "likes_post_id_fkey"})
+	mock.ExpectRollback()
+
+	_, err := repo.LikePost(context.Background(), "u1", "deleted")
+	assert.ErrorIs(t, err, likes.ErrTargetNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPostLikesCountReadsCounterColumn(t *testing.T) {
+	repo, mock := newMockPostgresRepository(t)
+
+	mock.ExpectQuery(`SELECT like_count FROM posts WHERE id = \$1`).
+		WithArgs("P1").
+		WillReturnRows(sqlmock.NewRows([]string{"like_count"}).AddRow(42))
+	mock.ExpectQuery(`SELECT like_count FROM posts WHERE id = \$1`).
+		WithArgs("missing").
+		WillReturnRows(sqlmock.NewRows([]string{"like_count"}))
+
+	count, err := repo.GetPostLikesCount(context.Background(), "P1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), count)
+
+	count, err = repo.GetPostLikesCount(context.Background(), "missing")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetCommentLikeCountsOnlyRequestedIds(t *testing.T) {
+	repo, mock := newMockPostgresRepository(t)
+
+	mock.ExpectQuery(`SELECT comment_id, COUNT\(\*\) FROM likes WHERE comment_id = ANY\(\$1\) GROUP BY comment_id`).
+		WillReturnRows(sqlmock.NewRows([]string{"comment_id", "count"}).AddRow("C1", 3))
+
+	counts, err := repo.GetCommentLikeCounts(context.Background(), []string{"C1", "C2"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"C1": 3}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -5,10 +5,16 @@ import "github.com/gin-gonic/gin"
 func RegisterRoutes(r *gin.RouterGroup, handler *LikeHandler) {
 	r.POST("/comment/:id", handler.HandleChangeLikeComment)
 	r.POST("/post/:id", handler.HandleChangeLikePost)
+	r.PUT("/comment/:id", handler.HandleLikeComment)
+	r.DELETE("/comment/:id", handler.HandleUnlikeComment)
+	r.PUT("/post/:id", handler.HandleLikePost)
+	r.DELETE("/post/:id", handler.HandleUnlikePost)
 	r.GET("/post/:id/isliked", handler.HandleGetPostLikedUser)
 	r.GET("/comment/:id/isliked", handler.HandleGetCommentLikedUser)
 	r.GET("/user/comments", handler.HandleGetUserCommentLikes)
 	r.GET("/user/posts", handler.HandleGetUserPostLikes)
 	r.GET("/comment/:id/count", handler.HandleGetCommentLikesCount)
 	r.GET("/post/:id/count", handler.HandleGetPostLikesCount)
+	r.GET("/post/:id/users", handler.HandleGetPostLikers)
+	r.GET("/comment/:id/users", handler.HandleGetCommentLikers)
 }
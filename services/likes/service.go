@@ -0,0 +1,218 @@
+package likes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	authrepository "github.com/wafi04/chatting-app/services/auth/pkg/repository"
+	"github.com/wafi04/chatting-app/services/notifications"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+// Relations memberi informasi follow dan blokir milik viewer
+type Relations interface {
+	GetFollowingIDs(ctx context.Context, userID string) ([]string, error)
+	GetBlockedByIDs(ctx context.Context, userID string) ([]string, error)
+}
+
+// ErrTargetNotFound dipakai untuk target yang tidak ada maupun yang tidak boleh dilihat
+// viewer, supaya keberadaan konten privat tidak bocor
+var ErrTargetNotFound = errors.New("like target not found")
+
+// TargetAccess memeriksa apakah post atau comment ada dan boleh dilihat viewer.
+// Implementasinya reactions.TargetRepository, dengan targetType "post" atau "comment".
+type TargetAccess interface {
+	CanAccess(ctx context.Context, viewerId, targetType, targetId string) (bool, error)
+}
+
+type LikeService struct {
+	likerepo  Repository
+	relations Relations
+	targets   TargetAccess
+	authrepo  *authrepository.AuthRepository
+	notifier  notifications.Notifier
+}
+
+func NewLikeService(likerepo Repository, relations Relations, targets TargetAccess, authrepo *authrepository.AuthRepository, notifier notifications.Notifier) *LikeService {
+	return &LikeService{
+		likerepo:  likerepo,
+		relations: relations,
+		targets:   targets,
+		authrepo:  authrepo,
+		notifier:  notifier,
+	}
+}
+
+// checkTarget menerima field "post_id" atau "comment_id" seperti di repository
+func (s *LikeService) checkTarget(ctx context.Context, viewerId, field, targetId string) error {
+	targetType := types.ReactionTargetPost
+	if field == "comment_id" {
+		targetType = types.ReactionTargetComment
+	}
+	ok, err := s.targets.CanAccess(ctx, viewerId, targetType, targetId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTargetNotFound
+	}
+	return nil
+}
+
+func (s *LikeService) LikePost(ctx context.Context, userId, postId string) (*types.LikePost, error) {
+	if err := s.checkTarget(ctx, userId, "post_id", postId); err != nil {
+		return nil, err
+	}
+	like, err := s.likerepo.LikePost(ctx, userId, postId)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyLike(ctx, types.NotificationPostLike, userId, postId)
+	return like, nil
+}
+
+func (s *LikeService) LikeComment(ctx context.Context, userId, commentId string) (*types.LikeComment, error) {
+	if err := s.checkTarget(ctx, userId, "comment_id", commentId); err != nil {
+		return nil, err
+	}
+	like, err := s.likerepo.LikeComment(ctx, userId, commentId)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyLike(ctx, types.NotificationCommentLike, userId, commentId)
+	return like, nil
+}
+
+func (s *LikeService) ChangeLikePost(ctx context.Context, userId, postId string) error {
+	if err := s.checkTarget(ctx, userId, "post_id", postId); err != nil {
+		return err
+	}
+	if err := s.likerepo.ChangeLikePost(ctx, userId, postId); err != nil {
+		return err
+	}
+
+	liked, err := s.likerepo.GetUserLiked(ctx, "post_id", postId, userId)
+	if err == nil && liked.Liked {
+		s.notifyLike(ctx, types.NotificationPostLike, userId, postId)
+	}
+	return nil
+}
+
+// ChangeLikeComment mengembalikan nil jika toggle menghapus like
+func (s *LikeService) ChangeLikeComment(ctx context.Context, userId, commentId string) (*types.LikeComment, error) {
+	if err := s.checkTarget(ctx, userId, "comment_id", commentId); err != nil {
+		return nil, err
+	}
+	like, err := s.likerepo.ChangeLikeComment(ctx, userId, commentId)
+	if err != nil {
+		return nil, err
+	}
+	if like != nil {
+		s.notifyLike(ctx, types.NotificationCommentLike, userId, commentId)
+	}
+	return like, nil
+}
+
+// Unlike tidak memeriksa akses target supaya like tetap bisa ditarik setelah
+// pemilik target memblokir user atau mengunci akunnya
+func (s *LikeService) UnlikePost(ctx context.Context, userId, postId string) error {
+	return s.likerepo.UnlikePost(ctx, userId, postId)
+}
+
+func (s *LikeService) UnlikeComment(ctx context.Context, userId, commentId string) error {
+	return s.likerepo.UnlikeComment(ctx, userId, commentId)
+}
+
+// notifyLike: like berulang dari user yang sama hanya dinotifikasi sekali (dedupe di notifications)
+func (s *LikeService) notifyLike(ctx context.Context, notificationType, userId, targetId string) {
+	if s.notifier == nil {
+		return
+	}
+	s.notifier.Notify(ctx, &types.NotificationEvent{
+		Type:     notificationType,
+		ActorID:  userId,
+		TargetID: targetId,
+	})
+}
+
+// ListLikers mengembalikan user yang menyukai target. Akun yang di-follow viewer
+// ditampilkan lebih dulu, lalu sisanya; user yang memblokir viewer tidak ditampilkan.
+func (s *LikeService) ListLikers(ctx context.Context, req *types.ListLikersRequest) (*types.ListLikersResponse, error) {
+	if err := s.checkTarget(ctx, req.ViewerID, req.Field, req.TargetID); err != nil {
+		return nil, err
+	}
+
+	following, err := s.relations.GetFollowingIDs(ctx, req.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	blockedBy, err := s.relations.GetBlockedByIDs(ctx, req.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	skip := (req.Page - 1) * req.Limit
+
+	// Halaman dihitung atas gabungan dua daftar: [akun yang di-follow] + [akun lain]
+	followed, followedTotal, err := s.likerepo.GetLikers(ctx, req.Field, req.TargetID, &LikerFilter{
+		Include: following,
+		Exclude: blockedBy,
+	}, skip, req.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	otherSkip := skip - followedTotal
+	if otherSkip < 0 {
+		otherSkip = 0
+	}
+	others, othersTotal, err := s.likerepo.GetLikers(ctx, req.Field, req.TargetID, &LikerFilter{
+		Exclude: append(append([]string{}, following...), blockedBy...),
+	}, otherSkip, req.Limit-int64(len(followed)))
+	if err != nil {
+		return nil, err
+	}
+
+	page := append(followed, others...)
+	userIds := make([]string, 0, len(page))
+	for _, like := range page {
+		userIds = append(userIds, like.UserID)
+	}
+	active, err := s.authrepo.ActiveUserIDs(ctx, userIds)
+	if err != nil {
+		return nil, err
+	}
+
+	total := followedTotal + othersTotal
+	likers := make([]*types.Liker, 0, len(page))
+	for i, like := range page {
+		// Akun yang dinonaktifkan tidak ditampilkan; like-nya tetap tersimpan
+		// supaya kembali terlihat jika akun diaktifkan lagi
+		if !active[like.UserID] {
+			total--
+			continue
+		}
+		user, err := s.authrepo.GetUser(ctx, &types.GetUserRequest{
+			UserId: like.UserID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user %s: %w", like.UserID, err)
+		}
+		likers = append(likers, &types.Liker{
+			UserInfo: types.UserInfo{
+				UserId:  user.UserId,
+				Name:    user.Name,
+				Picture: user.Picture,
+			},
+			IsFollowing: i < len(followed),
+			LikedAt:     like.CreatedAt,
+		})
+	}
+
+	return &types.ListLikersResponse{
+		Likers: likers,
+		Total:  total,
+		Page:   req.Page,
+	}, nil
+}
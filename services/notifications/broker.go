@@ -0,0 +1,107 @@
+package notifications
+
+import (
+	"errors"
+	"sync"
+)
+
+// Nama event SSE
+const (
+	StreamEventNotification = "notification"
+	StreamEventUnreadCount  = "unread_count"
+	StreamEventHeartbeat    = "heartbeat"
+	// Client harus memuat ulang daftar notifikasi karena event yang terlewat terlalu banyak
+	StreamEventResync = "resync"
+)
+
+// Buffer per koneksi; client yang tertinggal lebih dari ini diputus dan
+// melanjutkan dari Last-Event-ID saat reconnect
+const streamBufferSize = 32
+
+var ErrTooManyStreams = errors.New("too many notification streams")
+
+type StreamEvent struct {
+	// ID hanya diisi untuk event notifikasi (id baris), dipakai sebagai Last-Event-ID
+	ID    string
+	Event string
+	Data  interface{}
+}
+
+type subscriber struct {
+	ch   chan StreamEvent
+	once sync.Once
+}
+
+func (s *subscriber) close() {
+	s.once.Do(func() { close(s.ch) })
+}
+
+// Broker meneruskan event ke koneksi SSE yang terbuka di proses ini. Event yang
+// terlewat (koneksi putus, instance lain) diambil ulang dari database lewat Last-Event-ID.
+type Broker struct {
+	mu         sync.Mutex
+	subs       map[string]map[*subscriber]struct{}
+	maxPerUser int
+}
+
+func NewBroker(maxPerUser int) *Broker {
+	return &Broker{
+		subs:       make(map[string]map[*subscriber]struct{}),
+		maxPerUser: maxPerUser,
+	}
+}
+
+// Subscribe membuka stream untuk user. Channel ditutup jika client terlalu lambat;
+// unsubscribe wajib dipanggil saat koneksi selesai.
+func (b *Broker) Subscribe(userId string) (<-chan StreamEvent, func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.maxPerUser > 0 && len(b.subs[userId]) >= b.maxPerUser {
+		return nil, nil, ErrTooManyStreams
+	}
+	sub := &subscriber{ch: make(chan StreamEvent, streamBufferSize)}
+	if b.subs[userId] == nil {
+		b.subs[userId] = make(map[*subscriber]struct{})
+	}
+	b.subs[userId][sub] = struct{}{}
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		b.remove(userId, sub)
+		b.mu.Unlock()
+	}
+	return sub.ch, unsubscribe, nil
+}
+
+// Connected menandakan user punya stream terbuka di proses ini
+func (b *Broker) Connected(userId string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs[userId]) > 0
+}
+
+// Publish tidak pernah memblokir: subscriber yang buffer-nya penuh langsung diputus
+func (b *Broker) Publish(userId string, event StreamEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subs[userId] {
+		select {
+		case sub.ch <- event:
+		default:
+			b.remove(userId, sub)
+		}
+	}
+}
+
+func (b *Broker) remove(userId string, sub *subscriber) {
+	if _, ok := b.subs[userId][sub]; !ok {
+		return
+	}
+	delete(b.subs[userId], sub)
+	if len(b.subs[userId]) == 0 {
+		delete(b.subs, userId)
+	}
+	sub.close()
+}
@@ -0,0 +1,55 @@
+package notifications
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrokerPublish(t *testing.T) {
+	b := NewBroker(2)
+
+	first, unsubFirst, err := b.Subscribe("u1")
+	require.NoError(t, err)
+	second, unsubSecond, err := b.Subscribe("u1")
+	require.NoError(t, err)
+	defer unsubSecond()
+	other, unsubOther, err := b.Subscribe("u2")
+	require.NoError(t, err)
+	defer unsubOther()
+
+	_, _, err = b.Subscribe("u1")
+	assert.ErrorIs(t, err, ErrTooManyStreams)
+
+	b.Publish("u1", StreamEvent{ID: "1", Event: StreamEventNotification})
+	assert.Equal(t, "1", (<-first).ID)
+	assert.Equal(t, "1", (<-second).ID)
+	assert.Empty(t, other)
+
+	// Setelah unsubscribe, slot bisa dipakai lagi dan channel lama ditutup
+	unsubFirst()
+	unsubFirst()
+	_, ok := <-first
+	assert.False(t, ok)
+	_, unsub, err := b.Subscribe("u1")
+	require.NoError(t, err)
+	unsub()
+}
+
+func TestBrokerDropsSlowSubscriber(t *testing.T) {
+	b := NewBroker(0)
+	events, unsubscribe, err := b.Subscribe("u1")
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	for i := 0; i <= streamBufferSize; i++ {
+		b.Publish("u1", StreamEvent{Event: StreamEventUnreadCount, Data: i})
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	assert.Equal(t, streamBufferSize, received)
+}
@@ -0,0 +1,41 @@
+package notifications
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/wafi04/chatting-app/config/env"
+)
+
+type Config struct {
+	// StreamHeartbeat jeda event heartbeat di koneksi SSE; sesi juga dicek ulang di setiap heartbeat
+	StreamHeartbeat time.Duration
+	// MaxStreamsPerUser batas koneksi SSE bersamaan per user (tab/perangkat)
+	MaxStreamsPerUser int
+}
+
+// LoadConfig membaca NOTIFICATION_STREAM_HEARTBEAT dan NOTIFICATION_STREAM_MAX_PER_USER
+func LoadConfig() (*Config, error) {
+	config := &Config{
+		StreamHeartbeat:   25 * time.Second,
+		MaxStreamsPerUser: 5,
+	}
+
+	if raw := env.LoadEnv("NOTIFICATION_STREAM_HEARTBEAT"); raw != "" {
+		value, err := time.ParseDuration(raw)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("invalid NOTIFICATION_STREAM_HEARTBEAT: %q", raw)
+		}
+		config.StreamHeartbeat = value
+	}
+	if raw := env.LoadEnv("NOTIFICATION_STREAM_MAX_PER_USER"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value <= 0 {
+			return nil, fmt.Errorf("invalid NOTIFICATION_STREAM_MAX_PER_USER: %q", raw)
+		}
+		config.MaxStreamsPerUser = value
+	}
+
+	return config, nil
+}
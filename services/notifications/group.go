@@ -0,0 +1,79 @@
+package notifications
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+// groupKey menentukan event mana yang digabung menjadi satu notifikasi
+func groupKey(event *types.NotificationEvent) string {
+	switch event.Type {
+	case types.NotificationNewFollower, types.NotificationFollowRequest, types.NotificationFollowAccepted:
+		return event.Type
+	case types.NotificationMention:
+		// Setiap mention berdiri sendiri
+		return event.Type + ":" + event.SourceID
+	case types.NotificationMessage:
+		// Pesan dikelompokkan per pengirim
+		return event.Type + ":" + event.ActorID
+	default:
+		return event.Type + ":" + event.TargetID
+	}
+}
+
+func dedupeKey(event *types.NotificationEvent) string {
+	return strings.Join([]string{event.Type, event.TargetID, event.ActorID, event.SourceID}, ":")
+}
+
+// summarize membuat teks notifikasi, misal "Budi and 12 others liked your post"
+func summarize(n *types.Notification) string {
+	who := "Someone"
+	if len(n.Actors) > 0 {
+		who = n.Actors[0].Name
+		switch {
+		case n.ActorCount == 2 && len(n.Actors) > 1:
+			who += " and " + n.Actors[1].Name
+		case n.ActorCount == 2:
+			who += " and 1 other"
+		case n.ActorCount > 2:
+			who += fmt.Sprintf(" and %d others", n.ActorCount-1)
+		}
+	}
+
+	switch n.Type {
+	case types.NotificationNewFollower:
+		return who + " started following you"
+	case types.NotificationFollowRequest:
+		return who + " requested to follow you"
+	case types.NotificationFollowAccepted:
+		return who + " accepted your follow request"
+	case types.NotificationPostLike:
+		return who + " liked your post"
+	case types.NotificationCommentLike:
+		return who + " liked your comment"
+	case types.NotificationComment:
+		return who + " commented on your post"
+	case types.NotificationReply:
+		return who + " replied to your comment"
+	case types.NotificationMention:
+		return who + " mentioned you"
+	case types.NotificationMessage:
+		if n.EventCount > 1 {
+			return fmt.Sprintf("%s sent you %d messages", who, n.EventCount)
+		}
+		return who + " sent you a message"
+	default:
+		return who + " interacted with you"
+	}
+}
+
+func itemText(item *types.NotificationItem) string {
+	return summarize(&types.Notification{
+		Type:       item.Type,
+		Actors:     []*types.NotificationActor{item.Actor},
+		ActorCount: 1,
+		EventCount: 1,
+	})
+}
@@ -0,0 +1,43 @@
+package notifications
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+func TestGroupKey(t *testing.T) {
+	like := &types.NotificationEvent{Type: types.NotificationPostLike, ActorID: "u1", TargetID: "p1"}
+	otherLike := &types.NotificationEvent{Type: types.NotificationPostLike, ActorID: "u2", TargetID: "p1"}
+	assert.Equal(t, groupKey(like), groupKey(otherLike))
+	assert.NotEqual(t, dedupeKey(like), dedupeKey(otherLike))
+
+	mention := &types.NotificationEvent{Type: types.NotificationMention, ActorID: "u1", TargetID: "p1", SourceID: "c1"}
+	otherMention := &types.NotificationEvent{Type: types.NotificationMention, ActorID: "u1", TargetID: "p1", SourceID: "c2"}
+	assert.NotEqual(t, groupKey(mention), groupKey(otherMention))
+
+	assert.Equal(t, "new_follower", groupKey(&types.NotificationEvent{Type: types.NotificationNewFollower, ActorID: "u1"}))
+	assert.Equal(t, "new_message:u1", groupKey(&types.NotificationEvent{Type: types.NotificationMessage, ActorID: "u1", SourceID: "m1"}))
+}
+
+func TestSummarize(t *testing.T) {
+	budi := &types.NotificationActor{UserId: "u1", Name: "Budi"}
+	ani := &types.NotificationActor{UserId: "u2", Name: "Ani"}
+
+	tests := []struct {
+		n    *types.Notification
+		want string
+	}{
+		{&types.Notification{Type: types.NotificationPostLike, Actors: []*types.NotificationActor{budi}, ActorCount: 1}, "Budi liked your post"},
+		{&types.Notification{Type: types.NotificationPostLike, Actors: []*types.NotificationActor{budi, ani}, ActorCount: 2}, "Budi and Ani liked your post"},
+		{&types.Notification{Type: types.NotificationPostLike, Actors: []*types.NotificationActor{budi, ani}, ActorCount: 13}, "Budi and 12 others liked your post"},
+		{&types.Notification{Type: types.NotificationNewFollower, Actors: []*types.NotificationActor{budi}, ActorCount: 2}, "Budi and 1 other started following you"},
+		{&types.Notification{Type: types.NotificationMessage, Actors: []*types.NotificationActor{budi}, ActorCount: 1, EventCount: 4}, "Budi sent you 4 messages"},
+		{&types.Notification{Type: types.NotificationReply, ActorCount: 1}, "Someone replied to your comment"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, summarize(tt.n))
+	}
+}
@@ -0,0 +1,201 @@
+package notifications
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/chatting-app/services/shared/middleware"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+	"github.com/wafi04/chatting-app/services/shared/pkg/response"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+type NotificationHandler struct {
+	srv       *NotificationService
+	sessions  *middleware.SessionCache
+	heartbeat time.Duration
+	log       *logger.Logger
+}
+
+func NewNotificationHandler(srv *NotificationService, sessions *middleware.SessionCache, config *Config) *NotificationHandler {
+	return &NotificationHandler{
+		srv:       srv,
+		sessions:  sessions,
+		heartbeat: config.StreamHeartbeat,
+		log:       logger.NewLogger(),
+	}
+}
+
+func (h *NotificationHandler) HandleList(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req types.ListNotificationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil || req.Limit < 0 || req.Offset < 0 {
+		response.SendErrorResponse(c, http.StatusBadRequest, "Invalid query parameters")
+		return
+	}
+	req.UserId = user.UserId
+
+	resp, err := h.srv.List(c.Request.Context(), &req)
+	if err != nil {
+		h.log.Log(logger.ErrorLevel, "Failed to list notifications: %v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to list notifications")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Notifications retrieved successfully", resp)
+}
+
+func (h *NotificationHandler) HandleUnreadCount(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	resp, err := h.srv.UnreadCount(c.Request.Context(), user.UserId)
+	if err != nil {
+		h.log.Log(logger.ErrorLevel, "Failed to count unread notifications: %v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to count unread notifications")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Unread count retrieved successfully", resp)
+}
+
+func (h *NotificationHandler) HandleMarkRead(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req types.MarkNotificationsReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil || (!req.All && len(req.GroupKeys) == 0) {
+		response.SendErrorResponse(c, http.StatusBadRequest, "group_keys or all is required")
+		return
+	}
+
+	resp, err := h.srv.MarkRead(c.Request.Context(), user.UserId, &req)
+	if err != nil {
+		h.log.Log(logger.ErrorLevel, "Failed to mark notifications as read: %v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to mark notifications as read")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Notifications marked as read", resp)
+}
+
+func (h *NotificationHandler) HandleGetSettings(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	settings, err := h.srv.GetSettings(c.Request.Context(), user.UserId)
+	if err != nil {
+		h.log.Log(logger.ErrorLevel, "Failed to get notification settings: %v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to get notification settings")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Notification settings retrieved successfully", settings)
+}
+
+func (h *NotificationHandler) HandleUpdateSettings(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req types.UpdateNotificationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	settings, err := h.srv.UpdateSettings(c.Request.Context(), user.UserId, &req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidSettings) {
+			response.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.log.Log(logger.ErrorLevel, "Failed to update notification settings: %v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to update notification settings")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Notification settings updated successfully", settings)
+}
+
+func (h *NotificationHandler) HandleListMutes(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	mutes, err := h.srv.ListMutes(c.Request.Context(), user.UserId)
+	if err != nil {
+		h.log.Log(logger.ErrorLevel, "Failed to list notification mutes: %v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to list muted notifications")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Muted notifications retrieved successfully", mutes)
+}
+
+func (h *NotificationHandler) HandleMute(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req types.MuteNotificationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	mute, err := h.srv.Mute(c.Request.Context(), user.UserId, &req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidSettings) {
+			response.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.log.Log(logger.ErrorLevel, "Failed to mute notifications: %v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to mute notifications")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusCreated, "Notifications muted", mute)
+}
+
+func (h *NotificationHandler) HandleUnmute(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.srv.Unmute(c.Request.Context(), user.UserId, c.Param("targetID")); err != nil {
+		if errors.Is(err, ErrMuteNotFound) {
+			response.SendErrorResponse(c, http.StatusNotFound, "Mute not found")
+			return
+		}
+		h.log.Log(logger.ErrorLevel, "Failed to unmute notifications: %v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to unmute notifications")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Notifications unmuted", nil)
+}
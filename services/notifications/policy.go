@@ -0,0 +1,110 @@
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	// Data timezone ikut di-embed supaya tidak bergantung pada zoneinfo di server
+	_ "time/tzdata"
+
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+var ErrInvalidSettings = errors.New("invalid notification settings")
+
+const (
+	defaultQuietStart = 22 * 60
+	defaultQuietEnd   = 7 * 60
+)
+
+// policy adalah preferensi penerima yang dipakai dispatcher sebelum mengirim notifikasi
+type policy struct {
+	location     *time.Location
+	quietEnabled bool
+	// Menit sejak tengah malam di timezone user
+	quietStart int
+	quietEnd   int
+	digest     bool
+	// Kombinasi type/channel yang dimatikan; yang tidak ada berarti aktif
+	disabled map[string]bool
+	muted    bool
+}
+
+func preferenceKey(notificationType, channel string) string {
+	return notificationType + "/" + channel
+}
+
+func (p *policy) allows(notificationType, channel string) bool {
+	return !p.disabled[preferenceKey(notificationType, channel)]
+}
+
+// quiet melaporkan apakah now berada di quiet hours penerima
+func (p *policy) quiet(now time.Time) bool {
+	if !p.quietEnabled || p.quietStart == p.quietEnd {
+		return false
+	}
+	local := now.In(p.location)
+	minute := local.Hour()*60 + local.Minute()
+	if p.quietStart < p.quietEnd {
+		return minute >= p.quietStart && minute < p.quietEnd
+	}
+	// Melewati tengah malam, misal 22:00 - 07:00
+	return minute >= p.quietStart || minute < p.quietEnd
+}
+
+// settings mengubah policy menjadi bentuk response API
+func (p *policy) settings() *types.NotificationSettings {
+	settings := &types.NotificationSettings{
+		Timezone: p.location.String(),
+		QuietHours: types.QuietHours{
+			Enabled: p.quietEnabled,
+			Start:   formatClock(p.quietStart),
+			End:     formatClock(p.quietEnd),
+		},
+		Digest:      p.digest,
+		Preferences: make([]*types.NotificationPreference, 0, len(types.NotificationTypes)),
+	}
+	for _, t := range types.NotificationTypes {
+		settings.Preferences = append(settings.Preferences, &types.NotificationPreference{
+			Type:  t,
+			InApp: p.allows(t, types.NotificationChannelInApp),
+			Email: p.allows(t, types.NotificationChannelEmail),
+			Push:  p.allows(t, types.NotificationChannelPush),
+		})
+	}
+	return settings
+}
+
+func defaultPolicy() *policy {
+	return &policy{
+		location:   time.UTC,
+		quietStart: defaultQuietStart,
+		quietEnd:   defaultQuietEnd,
+		digest:     true,
+		disabled:   map[string]bool{},
+	}
+}
+
+// parseClock mengubah "HH:MM" menjadi menit sejak tengah malam
+func parseClock(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("%w: time must be in HH:MM format", ErrInvalidSettings)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func formatClock(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidSettings, name)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidSettings, name)
+	}
+	return location, nil
+}
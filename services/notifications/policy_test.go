@@ -0,0 +1,64 @@
+package notifications
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+func TestPolicyQuietHours(t *testing.T) {
+	jakarta, err := loadLocation("Asia/Jakarta")
+	require.NoError(t, err)
+
+	p := defaultPolicy()
+	p.location = jakarta
+	p.quietEnabled = true
+
+	// 22:00 - 07:00 waktu Jakarta (UTC+7)
+	assert.True(t, p.quiet(time.Date(2024, 1, 1, 16, 0, 0, 0, time.UTC)))   // 23:00 WIB
+	assert.True(t, p.quiet(time.Date(2024, 1, 1, 23, 59, 0, 0, time.UTC)))  // 06:59 WIB
+	assert.False(t, p.quiet(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)))   // 07:00 WIB
+	assert.False(t, p.quiet(time.Date(2024, 1, 1, 14, 59, 0, 0, time.UTC))) // 21:59 WIB
+
+	p.quietStart, p.quietEnd = 13*60, 15*60
+	assert.True(t, p.quiet(time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC)))  // 14:00 WIB
+	assert.False(t, p.quiet(time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC))) // 16:00 WIB
+
+	p.quietEnabled = false
+	assert.False(t, p.quiet(time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC)))
+}
+
+func TestPolicySettings(t *testing.T) {
+	p := defaultPolicy()
+	p.disabled[preferenceKey(types.NotificationPostLike, types.NotificationChannelPush)] = true
+
+	assert.False(t, p.allows(types.NotificationPostLike, types.NotificationChannelPush))
+	assert.True(t, p.allows(types.NotificationPostLike, types.NotificationChannelInApp))
+
+	settings := p.settings()
+	assert.Equal(t, "UTC", settings.Timezone)
+	assert.Equal(t, "22:00", settings.QuietHours.Start)
+	assert.Equal(t, "07:00", settings.QuietHours.End)
+	require.Len(t, settings.Preferences, len(types.NotificationTypes))
+	for _, pref := range settings.Preferences {
+		assert.Equal(t, pref.Type != types.NotificationPostLike, pref.Push, pref.Type)
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	minutes, err := parseClock("07:30")
+	require.NoError(t, err)
+	assert.Equal(t, 450, minutes)
+	assert.Equal(t, "07:30", formatClock(minutes))
+
+	for _, invalid := range []string{"", "7", "24:00", "12:60", "noon"} {
+		_, err := parseClock(invalid)
+		assert.ErrorIs(t, err, ErrInvalidSettings, invalid)
+	}
+
+	_, err = loadLocation("Mars/Olympus")
+	assert.ErrorIs(t, err, ErrInvalidSettings)
+}
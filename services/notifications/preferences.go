@@ -0,0 +1,173 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+var ErrMuteNotFound = errors.New("mute not found")
+
+// preferenceChange satu perubahan type/channel dari request update
+type preferenceChange struct {
+	Type    string
+	Channel string
+	Enabled bool
+}
+
+// Policy memuat preferensi user. targets adalah id post/comment/percakapan dari event;
+// policy.muted true jika salah satunya di-mute user.
+func (r *NotificationRepository) Policy(ctx context.Context, userId string, targets []string) (*policy, error) {
+	p := defaultPolicy()
+
+	var timezone string
+	err := r.db.QueryRowContext(ctx, `
+        SELECT timezone, quiet_hours_enabled, quiet_start, quiet_end, digest_enabled
+        FROM notification_settings
+        WHERE user_id = $1
+    `, userId).Scan(&timezone, &p.quietEnabled, &p.quietStart, &p.quietEnd, &p.digest)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to get notification settings: %w", err)
+	}
+	if timezone != "" {
+		// Timezone sudah divalidasi saat disimpan; kalau tzdata berubah, pakai UTC
+		if location, err := loadLocation(timezone); err == nil {
+			p.location = location
+		}
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT type, channel FROM notification_preferences
+        WHERE user_id = $1 AND enabled = false
+    `, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification preferences: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var notificationType, channel string
+		if err := rows.Scan(&notificationType, &channel); err != nil {
+			return nil, fmt.Errorf("failed to scan notification preference: %w", err)
+		}
+		p.disabled[preferenceKey(notificationType, channel)] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification preferences: %w", err)
+	}
+
+	if len(targets) > 0 {
+		err := r.db.QueryRowContext(ctx, `
+            SELECT EXISTS(SELECT 1 FROM notification_mutes WHERE user_id = $1 AND target_id = ANY($2))
+        `, userId, pq.Array(targets)).Scan(&p.muted)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check notification mutes: %w", err)
+		}
+	}
+
+	return p, nil
+}
+
+// SaveSettings menyimpan timezone, quiet hours dan perubahan preferensi dalam satu transaksi
+func (r *NotificationRepository) SaveSettings(ctx context.Context, userId string, p *policy, changes []preferenceChange) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO notification_settings (user_id, timezone, quiet_hours_enabled, quiet_start, quiet_end, digest_enabled)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (user_id) DO UPDATE
+        SET timezone = EXCLUDED.timezone,
+            quiet_hours_enabled = EXCLUDED.quiet_hours_enabled,
+            quiet_start = EXCLUDED.quiet_start,
+            quiet_end = EXCLUDED.quiet_end,
+            digest_enabled = EXCLUDED.digest_enabled,
+            updated_at = NOW()
+    `, userId, p.location.String(), p.quietEnabled, p.quietStart, p.quietEnd, p.digest)
+	if err != nil {
+		return fmt.Errorf("failed to save notification settings: %w", err)
+	}
+
+	for _, change := range changes {
+		// Default-nya aktif, jadi hanya yang dimatikan yang perlu disimpan
+		if change.Enabled {
+			_, err = tx.ExecContext(ctx, `
+                DELETE FROM notification_preferences
+                WHERE user_id = $1 AND type = $2 AND channel = $3
+            `, userId, change.Type, change.Channel)
+		} else {
+			_, err = tx.ExecContext(ctx, `
+                INSERT INTO notification_preferences (user_id, type, channel, enabled)
+                VALUES ($1, $2, $3, false)
+                ON CONFLICT (user_id, type, channel) DO UPDATE SET enabled = false
+            `, userId, change.Type, change.Channel)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to save notification preference: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *NotificationRepository) ListMutes(ctx context.Context, userId string) ([]*types.NotificationMute, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT target_id, target_type, EXTRACT(EPOCH FROM created_at)::bigint
+        FROM notification_mutes
+        WHERE user_id = $1
+        ORDER BY created_at DESC
+    `, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notification mutes: %w", err)
+	}
+	defer rows.Close()
+
+	mutes := []*types.NotificationMute{}
+	for rows.Next() {
+		mute := &types.NotificationMute{}
+		if err := rows.Scan(&mute.TargetID, &mute.TargetType, &mute.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan notification mute: %w", err)
+		}
+		mutes = append(mutes, mute)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification mutes: %w", err)
+	}
+	return mutes, nil
+}
+
+func (r *NotificationRepository) Mute(ctx context.Context, userId string, req *types.MuteNotificationsRequest) (*types.NotificationMute, error) {
+	mute := &types.NotificationMute{TargetID: req.TargetID, TargetType: req.TargetType}
+	err := r.db.QueryRowContext(ctx, `
+        INSERT INTO notification_mutes (user_id, target_id, target_type)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (user_id, target_id) DO UPDATE SET target_type = EXCLUDED.target_type
+        RETURNING EXTRACT(EPOCH FROM created_at)::bigint
+    `, userId, req.TargetID, req.TargetType).Scan(&mute.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to mute notifications: %w", err)
+	}
+	return mute, nil
+}
+
+func (r *NotificationRepository) Unmute(ctx context.Context, userId, targetId string) error {
+	res, err := r.db.ExecContext(ctx, `
+        DELETE FROM notification_mutes WHERE user_id = $1 AND target_id = $2
+    `, userId, targetId)
+	if err != nil {
+		return fmt.Errorf("failed to unmute notifications: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrMuteNotFound
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+	// Jumlah actor terbaru yang ditampilkan per grup
+	maxGroupActors = 3
+)
+
+type NotificationRepository struct {
+	db *sqlx.DB
+}
+
+func NewNotificationRepository(db *sqlx.DB) *NotificationRepository {
+	return &NotificationRepository{
+		db: db,
+	}
+}
+
+// record adalah satu baris notifications yang siap disimpan
+type record struct {
+	RecipientID string
+	ActorID     string
+	Type        string
+	GroupKey    string
+	TargetID    string
+	SourceID    string
+	Preview     string
+	DedupeKey   string
+	// false jika user mematikan channel in-app untuk jenis event ini
+	InApp bool
+}
+
+// Insert menyimpan notifikasi dan mengembalikan id-nya. Id 0 berarti event sudah pernah
+// dicatat atau penerima memblokir actor.
+func (r *NotificationRepository) Insert(ctx context.Context, rec *record) (int64, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+        INSERT INTO notifications (recipient_id, actor_id, type, group_key, target_id, source_id, preview, dedupe_key, in_app)
+        SELECT $1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), $8, $9
+        WHERE NOT EXISTS (
+            SELECT 1 FROM followers
+            WHERE follower_id = $2 AND following_id = $1 AND is_blocked = true
+        )
+        ON CONFLICT (recipient_id, dedupe_key) DO NOTHING
+        RETURNING id
+    `, rec.RecipientID, rec.ActorID, rec.Type, rec.GroupKey, rec.TargetID, rec.SourceID, rec.Preview, rec.DedupeKey, rec.InApp).Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to insert notification: %w", err)
+	}
+	return id, nil
+}
+
+// Items mengembalikan notifikasi milik user dengan id > afterId, terbaru lebih dulu
+// LatestID mengembalikan id notifikasi in-app terbaru milik user, 0 jika belum ada
+func (r *NotificationRepository) LatestID(ctx context.Context, userId string) (int64, error) {
+	var id int64
+	err := r.db.QueryRowContext(ctx, `
+        SELECT COALESCE(MAX(id), 0) FROM notifications WHERE recipient_id = $1 AND in_app = true
+    `, userId).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest notification id: %w", err)
+	}
+	return id, nil
+}
+
+func (r *NotificationRepository) Items(ctx context.Context, userId string, afterId int64, limit int) ([]*types.NotificationItem, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT
+            n.id,
+            n.type,
+            n.group_key,
+            COALESCE(n.target_id, ''),
+            COALESCE(n.preview, ''),
+            EXTRACT(EPOCH FROM n.created_at)::bigint,
+            a.user_id,
+            a.name,
+            COALESCE(a.picture, '')
+        FROM notifications n
+        JOIN users a ON a.user_id = n.actor_id AND a.is_active = true
+        WHERE n.recipient_id = $1 AND n.in_app = true AND n.id > $2
+        ORDER BY n.id DESC
+        LIMIT $3
+    `, userId, afterId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification items: %w", err)
+	}
+	defer rows.Close()
+
+	items := []*types.NotificationItem{}
+	for rows.Next() {
+		item := &types.NotificationItem{Actor: &types.NotificationActor{}}
+		if err := rows.Scan(
+			&item.Id,
+			&item.Type,
+			&item.GroupKey,
+			&item.TargetID,
+			&item.Preview,
+			&item.CreatedAt,
+			&item.Actor.UserId,
+			&item.Actor.Name,
+			&item.Actor.Picture,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan notification item: %w", err)
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notification items: %w", err)
+	}
+	return items, nil
+}
+
+func (r *NotificationRepository) PostOwner(ctx context.Context, postId string) (string, error) {
+	return r.owner(ctx, `SELECT user_id FROM posts WHERE id = $1`, postId)
+}
+
+func (r *NotificationRepository) CommentOwner(ctx context.Context, commentId string) (string, error) {
+	return r.owner(ctx, `SELECT user_id FROM comments WHERE id = $1`, commentId)
+}
+
+func (r *NotificationRepository) owner(ctx context.Context, query, id string) (string, error) {
+	var owner string
+	if err := r.db.QueryRowContext(ctx, query, id).Scan(&owner); err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to resolve notification recipient: %w", err)
+	}
+	return owner, nil
+}
+
+// ResolveMentions mengubah daftar username / user id menjadi user id yang aktif
+func (r *NotificationRepository) ResolveMentions(ctx context.Context, mentions []string) ([]string, error) {
+	ids := []string{}
+	err := r.db.SelectContext(ctx, &ids, `
+        SELECT DISTINCT u.user_id
+        FROM users u
+        LEFT JOIN user_profile p ON p.user_id = u.user_id
+        WHERE u.is_active = true
+        AND (u.user_id = ANY($1::text[]) OR LOWER(p.username) = ANY(SELECT LOWER(m) FROM UNNEST($1::text[]) m))
+    `, pq.Array(mentions))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve mentions: %w", err)
+	}
+	return ids, nil
+}
+
+// List mengembalikan notifikasi yang dikelompokkan per group_key. Event yang sudah dibaca
+// dan yang belum dipisah, supaya event baru tidak tercampur dengan grup lama.
+func (r *NotificationRepository) List(ctx context.Context, req *types.ListNotificationsRequest) ([]*types.Notification, error) {
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultListLimit
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	offset := req.Offset
+	if offset < 0 {
+		offset = 0
+	}
+
+	// Actor yang akunnya nonaktif disembunyikan, sama seperti konten mereka
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT
+            n.group_key,
+            n.type,
+            (n.read_at IS NULL) AS unread,
+            COALESCE((ARRAY_AGG(n.target_id ORDER BY n.created_at DESC))[1], ''),
+            COALESCE((ARRAY_AGG(n.preview ORDER BY n.created_at DESC))[1], ''),
+            COUNT(DISTINCT n.actor_id),
+            COUNT(*),
+            (ARRAY_AGG(n.actor_id ORDER BY n.created_at DESC))[1:20],
+            EXTRACT(EPOCH FROM MAX(n.created_at))::bigint AS latest_at
+        FROM notifications n
+        JOIN users a ON a.user_id = n.actor_id AND a.is_active = true
+        WHERE n.recipient_id = $1 AND n.in_app = true AND ($2 = false OR n.read_at IS NULL)
+        GROUP BY n.group_key, n.type, (n.read_at IS NULL)
+        ORDER BY latest_at DESC, n.group_key
+        LIMIT $3 OFFSET $4
+    `, req.UserId, req.UnreadOnly, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []*types.Notification{}
+	actorIds := map[*types.Notification][]string{}
+	for rows.Next() {
+		n := &types.Notification{}
+		var actors pq.StringArray
+		if err := rows.Scan(
+			&n.GroupKey,
+			&n.Type,
+			&n.Unread,
+			&n.TargetID,
+			&n.Preview,
+			&n.ActorCount,
+			&n.EventCount,
+			&actors,
+			&n.LatestAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		actorIds[n] = latestDistinct(actors, maxGroupActors)
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating notifications: %w", err)
+	}
+
+	var all []string
+	for _, ids := range actorIds {
+		all = append(all, ids...)
+	}
+	users, err := r.actors(ctx, all)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range notifications {
+		n.Actors = []*types.NotificationActor{}
+		for _, id := range actorIds[n] {
+			if actor, ok := users[id]; ok {
+				n.Actors = append(n.Actors, actor)
+			}
+		}
+	}
+
+	return notifications, nil
+}
+
+func (r *NotificationRepository) actors(ctx context.Context, ids []string) (map[string]*types.NotificationActor, error) {
+	actors := make(map[string]*types.NotificationActor, len(ids))
+	if len(ids) == 0 {
+		return actors, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT user_id, name, COALESCE(picture, '')
+        FROM users
+        WHERE user_id = ANY($1)
+    `, pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification actors: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		actor := &types.NotificationActor{}
+		if err := rows.Scan(&actor.UserId, &actor.Name, &actor.Picture); err != nil {
+			return nil, fmt.Errorf("failed to scan notification actor: %w", err)
+		}
+		actors[actor.UserId] = actor
+	}
+	return actors, rows.Err()
+}
+
+// UnreadCount menghitung grup yang belum dibaca, sama dengan yang tampil di List
+func (r *NotificationRepository) UnreadCount(ctx context.Context, userId string) (int, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+        SELECT COUNT(DISTINCT n.group_key)
+        FROM notifications n
+        JOIN users a ON a.user_id = n.actor_id AND a.is_active = true
+        WHERE n.recipient_id = $1 AND n.in_app = true AND n.read_at IS NULL
+    `, userId).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead menandai grup tertentu (atau semua jika all) sebagai sudah dibaca
+func (r *NotificationRepository) MarkRead(ctx context.Context, userId string, groupKeys []string, all bool) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `
+        UPDATE notifications
+        SET read_at = NOW()
+        WHERE recipient_id = $1 AND read_at IS NULL
+        AND ($2 = true OR group_key = ANY($3))
+    `, userId, all, pq.Array(groupKeys))
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// latestDistinct mengambil maksimal n id unik pertama dengan urutan tetap
+func latestDistinct(ids []string, n int) []string {
+	seen := make(map[string]bool, n)
+	out := make([]string, 0, n)
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+		if len(out) == n {
+			break
+		}
+	}
+	return out
+}
@@ -0,0 +1,15 @@
+package notifications
+
+import "github.com/gin-gonic/gin"
+
+func RegisterRoutes(r *gin.RouterGroup, h *NotificationHandler) {
+	r.GET("", h.HandleList)
+	r.GET("/unread-count", h.HandleUnreadCount)
+	r.GET("/stream", h.HandleStream)
+	r.POST("/read", h.HandleMarkRead)
+	r.GET("/settings", h.HandleGetSettings)
+	r.PUT("/settings", h.HandleUpdateSettings)
+	r.GET("/mutes", h.HandleListMutes)
+	r.POST("/mutes", h.HandleMute)
+	r.DELETE("/mutes/:targetID", h.HandleUnmute)
+}
@@ -0,0 +1,330 @@
+package notifications
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+// Notifier dipakai service lain untuk mencatat notifikasi. Notify tidak mengembalikan
+// error: notifikasi tidak boleh membuat aksi utama (follow, like, comment) gagal.
+type Notifier interface {
+	Notify(ctx context.Context, event *types.NotificationEvent)
+}
+
+const (
+	// Panjang maksimal potongan teks yang disimpan bersama notifikasi
+	maxPreviewLength = 140
+	// Batas event yang diputar ulang saat reconnect; lebih dari ini client diminta resync
+	maxReplay = 100
+)
+
+// Channel mengirim notifikasi ke luar aplikasi (email, push). In-app & SSE ditangani
+// langsung oleh NotificationService.
+type Channel interface {
+	// Name harus salah satu types.NotificationChannel*, dipakai untuk mencocokkan preferensi
+	Name() string
+	Deliver(ctx context.Context, delivery *Delivery) error
+}
+
+type Delivery struct {
+	Recipient string
+	Item      *types.NotificationItem
+	// Quiet true jika penerima sedang dalam quiet hours; channel memutuskan apakah
+	// notifikasi ditunda, dikirim tanpa suara atau tetap dikirim (misal digest)
+	Quiet bool
+}
+
+type NotificationService struct {
+	repo     *NotificationRepository
+	broker   *Broker
+	channels []Channel
+	log      *logger.Logger
+}
+
+func NewNotificationService(repo *NotificationRepository, broker *Broker, channels ...Channel) *NotificationService {
+	return &NotificationService{
+		repo:     repo,
+		broker:   broker,
+		channels: channels,
+		log:      logger.NewLogger(),
+	}
+}
+
+// Notify mencatat event di background supaya tidak menambah latensi request
+func (s *NotificationService) Notify(ctx context.Context, event *types.NotificationEvent) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := s.Record(ctx, event); err != nil {
+			s.log.Log(logger.ErrorLevel, "Failed to record %s notification: %v", event.Type, err)
+		}
+	}()
+}
+
+// Record menentukan penerima event lalu menyimpannya
+func (s *NotificationService) Record(ctx context.Context, event *types.NotificationEvent) error {
+	recipients, err := s.recipients(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	preview := []rune(event.Preview)
+	if len(preview) > maxPreviewLength {
+		preview = append(preview[:maxPreviewLength-1], '…')
+	}
+
+	for _, recipient := range recipients {
+		// Aksi terhadap konten sendiri tidak perlu dinotifikasi
+		if recipient == "" || recipient == event.ActorID {
+			continue
+		}
+		if err := s.dispatch(ctx, recipient, event, string(preview)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dispatch menyimpan event untuk satu penerima lalu mengirimnya ke channel yang
+// diizinkan preferensi penerima
+func (s *NotificationService) dispatch(ctx context.Context, recipient string, event *types.NotificationEvent, preview string) error {
+	var targets []string
+	for _, id := range []string{event.TargetID, event.ThreadID} {
+		if id != "" {
+			targets = append(targets, id)
+		}
+	}
+	policy, err := s.repo.Policy(ctx, recipient, targets)
+	if err != nil {
+		return err
+	}
+	if policy.muted {
+		return nil
+	}
+
+	// Event tetap dicatat walau in-app dimatikan supaya dedupe dan blokir berlaku untuk semua channel
+	inApp := policy.allows(event.Type, types.NotificationChannelInApp)
+	id, err := s.repo.Insert(ctx, &record{
+		RecipientID: recipient,
+		ActorID:     event.ActorID,
+		Type:        event.Type,
+		GroupKey:    groupKey(event),
+		TargetID:    event.TargetID,
+		SourceID:    event.SourceID,
+		Preview:     preview,
+		DedupeKey:   dedupeKey(event),
+		InApp:       inApp,
+	})
+	if err != nil || id == 0 {
+		return err
+	}
+
+	var channels []Channel
+	for _, ch := range s.channels {
+		if policy.allows(event.Type, ch.Name()) {
+			channels = append(channels, ch)
+		}
+	}
+	streaming := inApp && s.broker.Connected(recipient)
+	if !streaming && len(channels) == 0 {
+		return nil
+	}
+
+	item, err := s.buildItem(ctx, id, event, preview)
+	if err != nil || item == nil {
+		return err
+	}
+	if streaming {
+		s.broker.Publish(recipient, StreamEvent{
+			ID:    strconv.FormatInt(id, 10),
+			Event: StreamEventNotification,
+			Data:  item,
+		})
+		s.publishUnreadCount(ctx, recipient)
+	}
+
+	delivery := &Delivery{
+		Recipient: recipient,
+		Item:      item,
+		Quiet:     policy.quiet(time.Now()),
+	}
+	for _, ch := range channels {
+		if err := ch.Deliver(ctx, delivery); err != nil {
+			s.log.Log(logger.ErrorLevel, "Failed to deliver notification %d via %s: %v", id, ch.Name(), err)
+		}
+	}
+	return nil
+}
+
+// buildItem mengembalikan nil jika actor sudah tidak aktif
+func (s *NotificationService) buildItem(ctx context.Context, id int64, event *types.NotificationEvent, preview string) (*types.NotificationItem, error) {
+	actors, err := s.repo.actors(ctx, []string{event.ActorID})
+	if err != nil || actors[event.ActorID] == nil {
+		return nil, err
+	}
+
+	item := &types.NotificationItem{
+		Id:        id,
+		Type:      event.Type,
+		GroupKey:  groupKey(event),
+		TargetID:  event.TargetID,
+		Actor:     actors[event.ActorID],
+		Preview:   preview,
+		CreatedAt: time.Now().Unix(),
+	}
+	item.Text = itemText(item)
+	return item, nil
+}
+
+// publishUnreadCount mengirim jumlah notifikasi belum dibaca ke semua stream user
+func (s *NotificationService) publishUnreadCount(ctx context.Context, userId string) {
+	if !s.broker.Connected(userId) {
+		return
+	}
+	count, err := s.repo.UnreadCount(ctx, userId)
+	if err != nil {
+		s.log.Log(logger.WarnLevel, "Failed to count unread notifications: %v", err)
+		return
+	}
+	s.broker.Publish(userId, StreamEvent{
+		Event: StreamEventUnreadCount,
+		Data:  &types.UnreadCountResponse{Count: count},
+	})
+}
+
+func (s *NotificationService) Subscribe(userId string) (<-chan StreamEvent, func(), error) {
+	return s.broker.Subscribe(userId)
+}
+
+// Replay mengembalikan notifikasi setelah lastId dari yang terlama. complete false berarti
+// yang terlewat lebih dari maxReplay dan client sebaiknya memuat ulang daftar.
+// LatestID dipakai stream baru (tanpa Last-Event-ID) sebagai titik awal, supaya
+// notifikasi lama tidak dikirim ulang sebagai event baru
+func (s *NotificationService) LatestID(ctx context.Context, userId string) (int64, error) {
+	return s.repo.LatestID(ctx, userId)
+}
+
+func (s *NotificationService) Replay(ctx context.Context, userId string, lastId int64) ([]*types.NotificationItem, bool, error) {
+	items, err := s.repo.Items(ctx, userId, lastId, maxReplay+1)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(items) > maxReplay {
+		return nil, false, nil
+	}
+
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+	for _, item := range items {
+		item.Text = itemText(item)
+	}
+	return items, true, nil
+}
+
+func (s *NotificationService) recipients(ctx context.Context, event *types.NotificationEvent) ([]string, error) {
+	if event.Type == types.NotificationMention {
+		if len(event.Mentions) == 0 {
+			return nil, nil
+		}
+		return s.repo.ResolveMentions(ctx, event.Mentions)
+	}
+	if event.RecipientID != "" {
+		return []string{event.RecipientID}, nil
+	}
+
+	var owner string
+	var err error
+	switch event.Type {
+	case types.NotificationPostLike, types.NotificationComment:
+		owner, err = s.repo.PostOwner(ctx, event.TargetID)
+	case types.NotificationCommentLike, types.NotificationReply:
+		owner, err = s.repo.CommentOwner(ctx, event.TargetID)
+	}
+	if err != nil || owner == "" {
+		return nil, err
+	}
+	return []string{owner}, nil
+}
+
+func (s *NotificationService) List(ctx context.Context, req *types.ListNotificationsRequest) (*types.ListNotificationsResponse, error) {
+	notifications, err := s.repo.List(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range notifications {
+		n.Text = summarize(n)
+	}
+
+	unread, err := s.repo.UnreadCount(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.ListNotificationsResponse{
+		Notifications: notifications,
+		UnreadCount:   unread,
+	}, nil
+}
+
+// UnreadForEmail mengembalikan grup notifikasi belum dibaca yang muncul setelah since dan
+// jenisnya tidak dimatikan user untuk channel email. Dipakai digest email.
+func (s *NotificationService) UnreadForEmail(ctx context.Context, userId string, since time.Time, limit int) ([]*types.Notification, error) {
+	policy, err := s.repo.Policy(ctx, userId, nil)
+	if err != nil {
+		return nil, err
+	}
+	notifications, err := s.repo.List(ctx, &types.ListNotificationsRequest{
+		UserId:     userId,
+		UnreadOnly: true,
+		Limit:      maxListLimit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := []*types.Notification{}
+	for _, n := range notifications {
+		// Urut dari yang terbaru, jadi sisanya pasti lebih lama dari since
+		if n.LatestAt <= since.Unix() || len(result) >= limit {
+			break
+		}
+		if !policy.allows(n.Type, types.NotificationChannelEmail) {
+			continue
+		}
+		n.Text = summarize(n)
+		result = append(result, n)
+	}
+	return result, nil
+}
+
+func (s *NotificationService) UnreadCount(ctx context.Context, userId string) (*types.UnreadCountResponse, error) {
+	count, err := s.repo.UnreadCount(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return &types.UnreadCountResponse{Count: count}, nil
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, userId string, req *types.MarkNotificationsReadRequest) (*types.MarkNotificationsReadResponse, error) {
+	updated, err := s.repo.MarkRead(ctx, userId, req.GroupKeys, req.All)
+	if err != nil {
+		return nil, err
+	}
+	// Tab/perangkat lain ikut memperbarui badge
+	if updated > 0 {
+		s.publishUnreadCount(ctx, userId)
+	}
+
+	unread, err := s.repo.UnreadCount(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return &types.MarkNotificationsReadResponse{
+		Updated:     updated,
+		UnreadCount: unread,
+	}, nil
+}
@@ -0,0 +1,94 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+const maxMuteTargetLength = 100
+
+func (s *NotificationService) GetSettings(ctx context.Context, userId string) (*types.NotificationSettings, error) {
+	policy, err := s.repo.Policy(ctx, userId, nil)
+	if err != nil {
+		return nil, err
+	}
+	return policy.settings(), nil
+}
+
+// UpdateSettings menerapkan perubahan parsial: field yang tidak dikirim tidak diubah
+func (s *NotificationService) UpdateSettings(ctx context.Context, userId string, req *types.UpdateNotificationSettingsRequest) (*types.NotificationSettings, error) {
+	policy, err := s.repo.Policy(ctx, userId, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Timezone != nil {
+		if policy.location, err = loadLocation(strings.TrimSpace(*req.Timezone)); err != nil {
+			return nil, err
+		}
+	}
+	if req.QuietHours != nil {
+		start, err := parseClock(req.QuietHours.Start)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseClock(req.QuietHours.End)
+		if err != nil {
+			return nil, err
+		}
+		policy.quietEnabled, policy.quietStart, policy.quietEnd = req.QuietHours.Enabled, start, end
+	}
+	if req.Digest != nil {
+		policy.digest = *req.Digest
+	}
+
+	var changes []preferenceChange
+	for _, pref := range req.Preferences {
+		if pref == nil || !types.IsValidNotificationType(pref.Type) {
+			return nil, fmt.Errorf("%w: unknown notification type", ErrInvalidSettings)
+		}
+		for channel, enabled := range map[string]*bool{
+			types.NotificationChannelInApp: pref.InApp,
+			types.NotificationChannelEmail: pref.Email,
+			types.NotificationChannelPush:  pref.Push,
+		} {
+			if enabled == nil {
+				continue
+			}
+			changes = append(changes, preferenceChange{Type: pref.Type, Channel: channel, Enabled: *enabled})
+			delete(policy.disabled, preferenceKey(pref.Type, channel))
+			if !*enabled {
+				policy.disabled[preferenceKey(pref.Type, channel)] = true
+			}
+		}
+	}
+
+	if err := s.repo.SaveSettings(ctx, userId, policy, changes); err != nil {
+		return nil, err
+	}
+	return policy.settings(), nil
+}
+
+func (s *NotificationService) ListMutes(ctx context.Context, userId string) ([]*types.NotificationMute, error) {
+	return s.repo.ListMutes(ctx, userId)
+}
+
+func (s *NotificationService) Mute(ctx context.Context, userId string, req *types.MuteNotificationsRequest) (*types.NotificationMute, error) {
+	req.TargetID = strings.TrimSpace(req.TargetID)
+	if req.TargetID == "" || len(req.TargetID) > maxMuteTargetLength {
+		return nil, fmt.Errorf("%w: target_id is required", ErrInvalidSettings)
+	}
+	switch req.TargetType {
+	case types.MuteTargetPost, types.MuteTargetComment, types.MuteTargetConversation:
+	default:
+		return nil, fmt.Errorf("%w: target_type must be post, comment or conversation", ErrInvalidSettings)
+	}
+	return s.repo.Mute(ctx, userId, req)
+}
+
+func (s *NotificationService) Unmute(ctx context.Context, userId, targetId string) error {
+	return s.repo.Unmute(ctx, userId, targetId)
+}
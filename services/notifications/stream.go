@@ -0,0 +1,143 @@
+package notifications
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/chatting-app/services/shared/middleware"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+	"github.com/wafi04/chatting-app/services/shared/pkg/response"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+// Jeda reconnect yang disarankan ke EventSource (ms)
+const streamRetry = 5000
+
+// HandleStream membuka stream SSE berisi notifikasi baru dan jumlah belum dibaca.
+// Event notifikasi membawa id; saat reconnect browser mengirim Last-Event-ID dan
+// notifikasi yang terlewat diputar ulang dari database.
+func (h *NotificationHandler) HandleStream(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	lastId, resume, err := lastEventID(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusBadRequest, "Invalid Last-Event-ID")
+		return
+	}
+
+	// Subscribe sebelum replay supaya tidak ada event yang jatuh di antara keduanya
+	events, unsubscribe, err := h.srv.Subscribe(user.UserId)
+	if err != nil {
+		if errors.Is(err, ErrTooManyStreams) {
+			response.SendErrorResponse(c, http.StatusTooManyRequests, "Too many open notification streams")
+			return
+		}
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to open notification stream")
+		return
+	}
+	defer unsubscribe()
+
+	ctx := c.Request.Context()
+	// Replay hanya untuk reconnect; koneksi baru mulai dari notifikasi terbaru saat ini
+	var missed []*types.NotificationItem
+	complete := true
+	if resume {
+		missed, complete, err = h.srv.Replay(ctx, user.UserId, lastId)
+		if err != nil {
+			h.log.Log(logger.ErrorLevel, "Failed to replay notifications: %v", err)
+			response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to open notification stream")
+			return
+		}
+	} else {
+		lastId, err = h.srv.LatestID(ctx, user.UserId)
+		if err != nil {
+			h.log.Log(logger.ErrorLevel, "Failed to get latest notification id: %v", err)
+			response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to open notification stream")
+			return
+		}
+	}
+	unread, err := h.srv.UnreadCount(ctx, user.UserId)
+	if err != nil {
+		h.log.Log(logger.ErrorLevel, "Failed to count unread notifications: %v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to open notification stream")
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Nginx tidak boleh menahan response di buffer
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if !complete {
+		h.send(c, sse.Event{Event: StreamEventResync, Data: gin.H{}, Retry: streamRetry})
+	}
+	sent := lastId
+	for _, item := range missed {
+		h.send(c, sse.Event{Id: strconv.FormatInt(item.Id, 10), Event: StreamEventNotification, Data: item, Retry: streamRetry})
+		sent = item.Id
+	}
+	h.send(c, sse.Event{Event: StreamEventUnreadCount, Data: unread, Retry: streamRetry})
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	sessionId := middleware.GetSessionIDFromGinContext(c)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				// Client terlalu lambat; reconnect akan melanjutkan dari Last-Event-ID
+				return
+			}
+			if event.ID != "" {
+				id, _ := strconv.ParseInt(event.ID, 10, 64)
+				if id <= sent {
+					continue
+				}
+				sent = id
+			}
+			h.send(c, sse.Event{Id: event.ID, Event: event.Event, Data: event.Data})
+		case now := <-heartbeat.C:
+			// Sesi yang sudah dicabut (logout, revoke) tidak boleh terus menerima event
+			if sessionId != "" {
+				if active, err := h.sessions.IsActive(ctx, sessionId); err == nil && !active {
+					return
+				}
+			}
+			h.send(c, sse.Event{Event: StreamEventHeartbeat, Data: gin.H{"time": now.Unix()}})
+		}
+	}
+}
+
+func (h *NotificationHandler) send(c *gin.Context, event sse.Event) {
+	c.Render(-1, event)
+	c.Writer.Flush()
+}
+
+// lastEventID membaca header Last-Event-ID, atau query last_event_id untuk client
+// yang tidak bisa mengatur header. resume false berarti client tidak mengirim keduanya.
+func lastEventID(c *gin.Context) (id int64, resume bool, err error) {
+	raw := c.GetHeader("Last-Event-ID")
+	if raw == "" {
+		raw = c.Query("last_event_id")
+	}
+	if raw == "" {
+		return 0, false, nil
+	}
+	id, err = strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 0 {
+		return 0, false, errors.New("invalid last event id")
+	}
+	return id, true, nil
+}
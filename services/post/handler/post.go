@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	authservice "github.com/wafi04/chatting-app/services/auth/pkg/service"
@@ -151,3 +152,66 @@ func (h *PostHandler) HandleDeletePosts(c *gin.Context) {
 
 	response.SendSuccessResponse(c, http.StatusOK, "Fetch Data Successfully", nil)
 }
+
+func (h *PostHandler) HandleGetPostsByTag(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	tag := c.Param("tag")
+	if tag == "" {
+		response.SendErrorResponse(c, http.StatusBadRequest, "Tag is required")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if page <= 0 {
+		page = 1
+	}
+
+	postsData, err := h.postclient.GetPostsByTag(c, &types.GetPostsByTagRequest{
+		ViewerID: user.UserId,
+		Tag:      tag,
+		Page:     int32(page),
+		PerPage:  10,
+	})
+	if err != nil {
+		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to get posts", err.Error())
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Fetch Data Successfully", postsData)
+}
+
+func (h *PostHandler) HandleGetTrendingTags(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	windowHours, _ := strconv.Atoi(c.DefaultQuery("window", "24"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	// Jendela maksimal 7 hari supaya query tetap ringan
+	if windowHours <= 0 || windowHours > 168 {
+		windowHours = 24
+	}
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	tags, err := h.postclient.GetTrendingTags(c, &types.GetTrendingTagsRequest{
+		ViewerID: user.UserId,
+		Window:   time.Duration(windowHours) * time.Hour,
+		Limit:    int32(limit),
+	})
+	if err != nil {
+		response.SendErrorResponseWithDetails(c, http.StatusInternalServerError, "Failed to get trending tags", err.Error())
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Fetch Data Successfully", tags)
+}
@@ -4,9 +4,19 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterRoutes(r *gin.RouterGroup, h *PostHandler) {
-	r.POST("", h.HandleCreatePost)
+func RegisterRoutes(r *gin.RouterGroup, h *PostHandler, requireVerified gin.HandlerFunc) {
+	r.POST("", requireVerified, h.HandleCreatePost)
 	r.GET("/all", h.HandleGetAllPosts)
 	r.GET("/user", h.HandleGetPostByUser)
 	r.DELETE("/:id", h.HandleGetPostByUser)
 }
+
+func RegisterTagRoutes(r *gin.RouterGroup, h *PostHandler) {
+	r.GET("/trending", h.HandleGetTrendingTags)
+	r.GET("/:tag/posts", h.HandleGetPostsByTag)
+}
+
+// RegisterModerationRoutes dipasang di group yang dilindungi RequireRole(moderator, admin)
+func RegisterModerationRoutes(r *gin.RouterGroup, h *PostHandler) {
+	r.DELETE("/:postID", h.HandleDeletePosts)
+}
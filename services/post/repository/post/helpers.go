@@ -3,13 +3,40 @@ package postrepo
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/lib/pq"
 	"github.com/wafi04/chatting-app/services/shared/types"
 )
 
+// AuthorActive menyembunyikan konten milik akun yang dinonaktifkan atau menunggu penghapusan
+func AuthorActive(ownerColumn string) string {
+	return fmt.Sprintf(`EXISTS (SELECT 1 FROM users au WHERE au.user_id = %s AND au.is_active = true)`, ownerColumn)
+}
+
+// NotBlocked memastikan tidak ada blokir di antara viewer dan pemilik konten, dari arah manapun.
+// viewer adalah placeholder parameter viewer id, misalnya "$1".
+func NotBlocked(ownerColumn, viewer string) string {
+	return fmt.Sprintf(`NOT EXISTS (
+            SELECT 1 FROM followers b
+            WHERE b.is_blocked = true
+            AND ((b.follower_id = %[2]s AND b.following_id = %[1]s)
+              OR (b.follower_id = %[1]s AND b.following_id = %[2]s))
+        )`, ownerColumn, viewer)
+}
+
+// VisibleTo membatasi konten akun privat hanya untuk pemiliknya dan follower-nya
+func VisibleTo(ownerColumn, viewer string) string {
+	return fmt.Sprintf(`(
+            %[1]s = %[2]s
+            OR NOT EXISTS (SELECT 1 FROM user_profile up WHERE up.user_id = %[1]s AND up.is_privacy = true)
+            OR EXISTS (
+                SELECT 1 FROM followers f
+                WHERE f.follower_id = %[2]s AND f.following_id = %[1]s AND f.is_blocked = false
+            )
+        )`, ownerColumn, viewer)
+}
+
 func (r *PostRepository) QueryPosts(ctx context.Context, query string, args ...interface{}) ([]*types.Post, error) {
 	rows, err := r.DB.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -32,6 +59,8 @@ func (r *PostRepository) QueryPosts(ctx context.Context, query string, args ...i
 			pq.Array(&dbMentions),
 			&created_at,
 			&updated_at,
+			&post.LikeCount,
+			&post.CommentCount,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan post: %w", err)
@@ -46,12 +75,6 @@ func (r *PostRepository) QueryPosts(ctx context.Context, query string, args ...i
 		if err != nil {
 			return nil, fmt.Errorf("failed to get media for post %s: %w", post.Id, err)
 		}
-		count, err := r.commentRepo.GetCountComment(ctx, post.Id)
-		log.Printf("count ; %d", count.Count)
-
-		if err != nil {
-			return nil, fmt.Errorf("failed to get count comment : %s : %w", post.Id, err)
-		}
 		user, err := r.authrepo.GetUser(ctx, &types.GetUserRequest{
 			UserId: post.UserId,
 		})
@@ -60,7 +83,6 @@ func (r *PostRepository) QueryPosts(ctx context.Context, query string, args ...i
 		}
 
 		post.Media = mediaList
-		post.CommentCount = count.Count
 		post.UserInfo = types.UserInfo{
 			UserId: user.UserId,
 			Name:   user.Name,
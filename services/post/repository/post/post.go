@@ -104,6 +104,12 @@ func (r *PostRepository) CreatePost(ctx context.Context, req *types.Post) (*type
 	post.Tags = dbTags
 	post.Mentions = dbMentions
 
+	err = r.CreatePostTags(ctx, tx, post.Id, post.Tags, created_at)
+	if err != nil {
+		r.logger.Log(logger.ErrorLevel, "Failed to index post tags: %v", err)
+		return nil, err
+	}
+
 	for _, mediaUpload := range req.Media {
 		mediaID := utils.GenerateRandomId("MEDIA")
 		r.logger.Log(logger.InfoLevel, "url : %s", mediaUpload.FileUrl)
@@ -136,11 +142,14 @@ func (r *PostRepository) GetUserPosts(ctx context.Context, req *types.GetUserPos
         tags,
         mentions,
         created_at,
-        updated_at
+        updated_at,
+        like_count,
+        comment_count
     FROM 
         posts
     WHERE 
         user_id = $1
+        AND ` + AuthorActive("posts.user_id") + `
     ORDER BY 
         created_at DESC
     LIMIT $2 OFFSET $3
@@ -169,9 +178,13 @@ func (r *PostRepository) GetAllPosts(ctx context.Context, req *types.GetAllPosts
         tags,
         mentions,
         created_at,
-        updated_at
+        updated_at,
+        like_count,
+        comment_count
     FROM 
         posts
+    WHERE 
+        ` + AuthorActive("posts.user_id") + `
     ORDER BY 
         created_at DESC
     LIMIT $1 OFFSET $2
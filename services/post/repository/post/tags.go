@@ -0,0 +1,144 @@
+package postrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+// CreatePostTags menyimpan tag post ke index post_tags di dalam transaksi pembuatan post
+func (r *PostRepository) CreatePostTags(ctx context.Context, tx *sqlx.Tx, postID string, tags []string, createdAt time.Time) error {
+	query := `
+    INSERT INTO post_tags (post_id, tag, created_at)
+    VALUES ($1, $2, $3)
+    ON CONFLICT (post_id, tag) DO NOTHING
+    `
+
+	for _, tag := range tags {
+		if _, err := tx.ExecContext(ctx, query, postID, tag, createdAt); err != nil {
+			return fmt.Errorf("failed to create post tag %s: %w", tag, err)
+		}
+	}
+
+	return nil
+}
+
+// tagVisible menyaring post di index tag dengan aturan yang sama seperti feed: pemilik
+// aktif, akun privat hanya untuk follower, dan tidak ada blokir dengan viewer ($1)
+func tagVisible(ownerColumn string) string {
+	return AuthorActive(ownerColumn) + `
+        AND ` + VisibleTo(ownerColumn, "$1") + `
+        AND ` + NotBlocked(ownerColumn, "$1")
+}
+
+func (r *PostRepository) GetPostsByTag(ctx context.Context, req *types.GetPostsByTagRequest) (*types.GetPostsByTagResponse, error) {
+	query := `
+    SELECT 
+        p.id,
+        p.user_id,
+        p.caption,
+        p.location,
+        p.tags,
+        p.mentions,
+        p.created_at,
+        p.updated_at,
+        p.like_count,
+        p.comment_count
+    FROM 
+        post_tags pt
+    JOIN 
+        posts p ON p.id = pt.post_id
+    WHERE 
+        pt.tag = $2
+        AND ` + tagVisible("p.user_id") + `
+    ORDER BY 
+        pt.created_at DESC
+    LIMIT $3 OFFSET $4
+    `
+
+	limit := req.PerPage
+	offset := (req.Page - 1) * req.PerPage
+
+	posts, err := r.QueryPosts(ctx, query, req.ViewerID, req.Tag, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	countQuery := `
+    SELECT COUNT(*)
+    FROM 
+        post_tags pt
+    JOIN 
+        posts p ON p.id = pt.post_id
+    WHERE 
+        pt.tag = $2
+        AND ` + tagVisible("p.user_id") + `
+    `
+
+	var total int64
+	err = r.DB.QueryRowContext(ctx, countQuery, req.ViewerID, req.Tag).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count posts for tag %s: %w", req.Tag, err)
+	}
+
+	return &types.GetPostsByTagResponse{
+		Tag:   req.Tag,
+		Posts: posts,
+		Total: total,
+	}, nil
+}
+
+// GetTrendingTags menghitung skor tag dalam jendela waktu tertentu.
+// Setiap pemakaian tag bernilai exp(-ln2 * umur / halfLife), jadi pemakaian
+// baru lebih berbobot dibanding pemakaian yang mendekati batas jendela.
+// Hanya post yang boleh dilihat viewer yang ikut dihitung.
+func (r *PostRepository) GetTrendingTags(ctx context.Context, req *types.GetTrendingTagsRequest) (*types.GetTrendingTagsResponse, error) {
+	query := `
+    SELECT 
+        pt.tag,
+        COUNT(*) AS post_count,
+        SUM(EXP(-LN(2) * EXTRACT(EPOCH FROM (NOW() - pt.created_at)) / $3)) AS score
+    FROM 
+        post_tags pt
+    JOIN 
+        posts p ON p.id = pt.post_id
+    WHERE 
+        pt.created_at > NOW() - make_interval(secs => $2)
+        AND ` + tagVisible("p.user_id") + `
+    GROUP BY 
+        pt.tag
+    ORDER BY 
+        score DESC, post_count DESC, pt.tag
+    LIMIT $4
+    `
+
+	window := req.Window.Seconds()
+	halfLife := req.HalfLife.Seconds()
+
+	rows, err := r.DB.QueryContext(ctx, query, req.ViewerID, window, halfLife, req.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trending tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []*types.TrendingTag{}
+	for rows.Next() {
+		tag := &types.TrendingTag{}
+		if err := rows.Scan(&tag.Tag, &tag.PostCount, &tag.Score); err != nil {
+			return nil, fmt.Errorf("failed to scan trending tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while iterating trending tags: %w", err)
+	}
+
+	return &types.GetTrendingTagsResponse{
+		Tags:   tags,
+		Window: int64(window),
+	}, nil
+}
@@ -4,25 +4,30 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/wafi04/chatting-app/services/notifications"
 	cloudrepo "github.com/wafi04/chatting-app/services/post/repository/cloud"
 	postrepo "github.com/wafi04/chatting-app/services/post/repository/post"
 	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
 	"github.com/wafi04/chatting-app/services/shared/types"
+	"github.com/wafi04/chatting-app/services/shared/utils"
 )
 
 type PostService struct {
 	cloudrepo *cloudrepo.Cloudinary
 	postrepo  *postrepo.PostRepository
+	notifier  notifications.Notifier
 	logger    logger.Logger
 }
 
 func NewPostService(
 	cloudrepo *cloudrepo.Cloudinary,
 	postrepo *postrepo.PostRepository,
+	notifier notifications.Notifier,
 ) *PostService {
 	return &PostService{
 		cloudrepo: cloudrepo,
 		postrepo:  postrepo,
+		notifier:  notifier,
 	}
 }
 
@@ -50,7 +55,7 @@ func (s *PostService) CreatePost(ctx context.Context, req *types.CreatePostReque
 		Media:    uploadedMedia,
 		Mentions: req.Mentions,
 		Location: req.Location,
-		Tags:     req.Tags,
+		Tags:     utils.MergeHashtags(req.Tags, utils.ExtractHashtags(req.Caption)),
 	})
 	if err != nil {
 		s.logger.Log(logger.ErrorLevel, "Failed to create post: %v", err)
@@ -58,6 +63,18 @@ func (s *PostService) CreatePost(ctx context.Context, req *types.CreatePostReque
 		return nil, fmt.Errorf("failed to create post: %v", err)
 	}
 
+	// Mention bisa dikirim eksplisit lewat field mentions atau ditulis "@username" di caption
+	if mentions := append(append([]string{}, req.Mentions...), utils.ExtractMentions(req.Caption)...); s.notifier != nil && len(mentions) > 0 {
+		s.notifier.Notify(ctx, &types.NotificationEvent{
+			Type:     types.NotificationMention,
+			ActorID:  post.UserId,
+			TargetID: post.Id,
+			SourceID: post.Id,
+			Preview:  post.Caption,
+			Mentions: mentions,
+		})
+	}
+
 	return &types.PostResponse{
 		Post: post,
 	}, nil
@@ -80,6 +97,24 @@ func (s *PostService) GetAllPosts(ctx context.Context, req *types.GetAllPostsReq
 
 	return s.postrepo.GetAllPosts(ctx, req)
 }
+func (s *PostService) GetPostsByTag(ctx context.Context, req *types.GetPostsByTagRequest) (*types.GetPostsByTagResponse, error) {
+	tag := utils.NormalizeHashtag(req.Tag)
+	if tag == "" {
+		return nil, fmt.Errorf("invalid tag: %s", req.Tag)
+	}
+	req.Tag = tag
+
+	return s.postrepo.GetPostsByTag(ctx, req)
+}
+
+func (s *PostService) GetTrendingTags(ctx context.Context, req *types.GetTrendingTagsRequest) (*types.GetTrendingTagsResponse, error) {
+	if req.HalfLife <= 0 {
+		req.HalfLife = req.Window / 4
+	}
+
+	return s.postrepo.GetTrendingTags(ctx, req)
+}
+
 func (s *PostService) DeletePosts(ctx context.Context, req *types.DeletePostRequest) (*types.DeletePostResponse, error) {
 
 	return s.postrepo.DeletePosts(ctx, req)
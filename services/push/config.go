@@ -0,0 +1,75 @@
+package push
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/wafi04/chatting-app/config/env"
+)
+
+type Config struct {
+	// VAPID kosong berarti Web Push tidak aktif
+	VAPID *VAPIDKeys
+	// MaxAttempts total percobaan kirim per subscription termasuk yang pertama
+	MaxAttempts int
+	// BaseBackoff jeda sebelum retry pertama; berlipat dua di setiap retry sampai MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// MaxFailures jumlah kegagalan berturut-turut sebelum subscription dihapus
+	MaxFailures int
+}
+
+// LoadConfig membaca VAPID_PUBLIC_KEY, VAPID_PRIVATE_KEY, VAPID_SUBJECT, PUSH_MAX_ATTEMPTS,
+// PUSH_BASE_BACKOFF, PUSH_MAX_BACKOFF dan PUSH_MAX_FAILURES
+func LoadConfig() (*Config, error) {
+	config := &Config{
+		MaxAttempts: 3,
+		BaseBackoff: 500 * time.Millisecond,
+		MaxBackoff:  30 * time.Second,
+		MaxFailures: 5,
+	}
+
+	publicKey := env.LoadEnv("VAPID_PUBLIC_KEY")
+	privateKey := env.LoadEnv("VAPID_PRIVATE_KEY")
+	if publicKey != "" || privateKey != "" {
+		subject := env.LoadEnv("VAPID_SUBJECT")
+		if publicKey == "" || privateKey == "" || subject == "" {
+			return nil, fmt.Errorf("VAPID_PUBLIC_KEY, VAPID_PRIVATE_KEY and VAPID_SUBJECT are required together")
+		}
+		config.VAPID = &VAPIDKeys{PublicKey: publicKey, PrivateKey: privateKey, Subject: subject}
+	}
+
+	for _, setting := range []struct {
+		name  string
+		value *int
+	}{
+		{"PUSH_MAX_ATTEMPTS", &config.MaxAttempts},
+		{"PUSH_MAX_FAILURES", &config.MaxFailures},
+	} {
+		if raw := env.LoadEnv(setting.name); raw != "" {
+			value, err := strconv.Atoi(raw)
+			if err != nil || value <= 0 {
+				return nil, fmt.Errorf("invalid %s: %q", setting.name, raw)
+			}
+			*setting.value = value
+		}
+	}
+	for _, setting := range []struct {
+		name  string
+		value *time.Duration
+	}{
+		{"PUSH_BASE_BACKOFF", &config.BaseBackoff},
+		{"PUSH_MAX_BACKOFF", &config.MaxBackoff},
+	} {
+		if raw := env.LoadEnv(setting.name); raw != "" {
+			value, err := time.ParseDuration(raw)
+			if err != nil || value <= 0 {
+				return nil, fmt.Errorf("invalid %s: %q", setting.name, raw)
+			}
+			*setting.value = value
+		}
+	}
+
+	return config, nil
+}
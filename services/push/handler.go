@@ -0,0 +1,101 @@
+package push
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/chatting-app/services/shared/middleware"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+	"github.com/wafi04/chatting-app/services/shared/pkg/response"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+type PushHandler struct {
+	srv *PushService
+	log *logger.Logger
+}
+
+func NewPushHandler(srv *PushService) *PushHandler {
+	return &PushHandler{
+		srv: srv,
+		log: logger.NewLogger(),
+	}
+}
+
+func (h *PushHandler) HandlePublicKey(c *gin.Context) {
+	publicKey := h.srv.PublicKey()
+	if publicKey == "" {
+		response.SendErrorResponse(c, http.StatusNotFound, "Web push is not enabled")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "VAPID public key retrieved successfully", gin.H{
+		"public_key": publicKey,
+	})
+}
+
+func (h *PushHandler) HandleRegister(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req types.RegisterPushSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendErrorResponse(c, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userAgent := middleware.ClientInfoFromContext(c.Request.Context()).UserAgent
+	sub, err := h.srv.Register(c.Request.Context(), user.UserId, userAgent, &req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidSubscription) {
+			response.SendErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.log.Log(logger.ErrorLevel, "Failed to register push subscription: %v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to register push subscription")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusCreated, "Push subscription registered", sub)
+}
+
+func (h *PushHandler) HandleList(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	subs, err := h.srv.List(c.Request.Context(), user.UserId)
+	if err != nil {
+		h.log.Log(logger.ErrorLevel, "Failed to list push subscriptions: %v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to list push subscriptions")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Push subscriptions retrieved successfully", subs)
+}
+
+func (h *PushHandler) HandleUnregister(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.srv.Unregister(c.Request.Context(), user.UserId, c.Param("id")); err != nil {
+		if errors.Is(err, ErrSubscriptionNotFound) {
+			response.SendErrorResponse(c, http.StatusNotFound, "Push subscription not found")
+			return
+		}
+		h.log.Log(logger.ErrorLevel, "Failed to delete push subscription: %v", err)
+		response.SendErrorResponse(c, http.StatusInternalServerError, "Failed to delete push subscription")
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Push subscription deleted", nil)
+}
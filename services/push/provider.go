@@ -0,0 +1,55 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSubscriptionGone dikembalikan provider jika subscription sudah tidak berlaku
+// (misal Web Push 404/410); subscription langsung dihapus tanpa retry
+var ErrSubscriptionGone = errors.New("push subscription is no longer valid")
+
+// TemporaryError menandai kegagalan yang boleh dicoba ulang (rate limit, 5xx, jaringan)
+type TemporaryError struct {
+	Err error
+	// RetryAfter dari provider; 0 berarti pakai backoff biasa
+	RetryAfter time.Duration
+}
+
+func (e *TemporaryError) Error() string {
+	return fmt.Sprintf("temporary push failure: %v", e.Err)
+}
+
+func (e *TemporaryError) Unwrap() error {
+	return e.Err
+}
+
+// Subscription adalah tujuan push milik satu perangkat/browser
+type Subscription struct {
+	Id       string
+	UserId   string
+	Platform string
+	Endpoint string
+	// Kunci dari browser (base64url), khusus Web Push
+	P256dh string
+	Auth   string
+}
+
+// Message adalah isi push yang diterima service worker / aplikasi
+type Message struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	// Tag dipakai client untuk mengganti notifikasi lama dengan grup yang sama
+	Tag  string            `json:"tag,omitempty"`
+	Data map[string]string `json:"data,omitempty"`
+	// TTL berapa lama push service menyimpan pesan jika perangkat offline
+	TTL time.Duration `json:"-"`
+}
+
+// PushProvider mengirim pesan ke satu platform (web, dan nanti fcm/apns)
+type PushProvider interface {
+	Platform() string
+	Send(ctx context.Context, sub *Subscription, msg *Message) error
+}
@@ -0,0 +1,68 @@
+// Package pushtest berisi provider push palsu untuk test
+package pushtest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/wafi04/chatting-app/services/push"
+)
+
+// Sent adalah satu panggilan Send yang dicatat
+type Sent struct {
+	Subscription push.Subscription
+	Message      push.Message
+}
+
+// RecordingProvider mencatat semua pesan yang dikirim. Errors dipakai berurutan untuk
+// setiap panggilan Send; setelah habis Send selalu berhasil.
+type RecordingProvider struct {
+	PlatformName string
+	Errors       []error
+
+	mu   sync.Mutex
+	sent []Sent
+	// calls termasuk panggilan yang gagal
+	calls int
+}
+
+func NewRecordingProvider(platform string, errs ...error) *RecordingProvider {
+	return &RecordingProvider{
+		PlatformName: platform,
+		Errors:       errs,
+	}
+}
+
+func (p *RecordingProvider) Platform() string {
+	return p.PlatformName
+}
+
+func (p *RecordingProvider) Send(ctx context.Context, sub *push.Subscription, msg *push.Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.calls++
+	if len(p.Errors) > 0 {
+		err := p.Errors[0]
+		p.Errors = p.Errors[1:]
+		if err != nil {
+			return err
+		}
+	}
+	p.sent = append(p.sent, Sent{Subscription: *sub, Message: *msg})
+	return nil
+}
+
+// Sent mengembalikan salinan pesan yang berhasil dikirim
+func (p *RecordingProvider) Sent() []Sent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Sent(nil), p.sent...)
+}
+
+// Calls jumlah panggilan Send termasuk yang gagal
+func (p *RecordingProvider) Calls() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls
+}
@@ -0,0 +1,180 @@
+package push
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+// Subscription lama (paling jarang dipakai) dibuang jika user melewati batas ini
+const maxSubscriptionsPerUser = 20
+
+var ErrSubscriptionNotFound = errors.New("push subscription not found")
+
+type PushRepository struct {
+	db *sqlx.DB
+}
+
+func NewPushRepository(db *sqlx.DB) *PushRepository {
+	return &PushRepository{
+		db: db,
+	}
+}
+
+// Register menyimpan subscription; endpoint yang sudah ada diperbarui kunci & pemiliknya
+func (r *PushRepository) Register(ctx context.Context, userId, userAgent string, req *types.RegisterPushSubscriptionRequest) (*types.PushSubscription, error) {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var sub types.PushSubscription
+	err = tx.QueryRowContext(ctx, `
+        INSERT INTO push_subscriptions (id, user_id, platform, endpoint, p256dh, auth, user_agent)
+        VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''))
+        ON CONFLICT (endpoint) DO UPDATE SET
+            user_id = EXCLUDED.user_id,
+            platform = EXCLUDED.platform,
+            p256dh = EXCLUDED.p256dh,
+            auth = EXCLUDED.auth,
+            user_agent = EXCLUDED.user_agent,
+            failure_count = 0
+        RETURNING id, platform, endpoint, COALESCE(user_agent, ''),
+            EXTRACT(EPOCH FROM created_at)::bigint,
+            COALESCE(EXTRACT(EPOCH FROM last_used_at)::bigint, 0)
+    `, uuid.New().String(), userId, req.Platform, req.Endpoint, req.Keys.P256dh, req.Keys.Auth, userAgent).
+		Scan(&sub.Id, &sub.Platform, &sub.Endpoint, &sub.UserAgent, &sub.CreatedAt, &sub.LastUsedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to register push subscription: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        DELETE FROM push_subscriptions
+        WHERE user_id = $1 AND id NOT IN (
+            SELECT id FROM push_subscriptions
+            WHERE user_id = $1
+            ORDER BY COALESCE(last_used_at, created_at) DESC
+            LIMIT $2
+        )
+    `, userId, maxSubscriptionsPerUser)
+	if err != nil {
+		return nil, fmt.Errorf("failed to trim push subscriptions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &sub, nil
+}
+
+func (r *PushRepository) List(ctx context.Context, userId string) ([]*types.PushSubscription, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT id, platform, endpoint, COALESCE(user_agent, ''),
+            EXTRACT(EPOCH FROM created_at)::bigint,
+            COALESCE(EXTRACT(EPOCH FROM last_used_at)::bigint, 0)
+        FROM push_subscriptions
+        WHERE user_id = $1
+        ORDER BY created_at DESC
+    `, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list push subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	subs := []*types.PushSubscription{}
+	for rows.Next() {
+		var sub types.PushSubscription
+		if err := rows.Scan(&sub.Id, &sub.Platform, &sub.Endpoint, &sub.UserAgent, &sub.CreatedAt, &sub.LastUsedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan push subscription: %w", err)
+		}
+		subs = append(subs, &sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating push subscriptions: %w", err)
+	}
+	return subs, nil
+}
+
+// Targets mengembalikan subscription user lengkap dengan kunci untuk dikirimi push
+func (r *PushRepository) Targets(ctx context.Context, userId string) ([]*Subscription, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT id, user_id, platform, endpoint, p256dh, auth
+        FROM push_subscriptions
+        WHERE user_id = $1
+    `, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get push targets: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []*Subscription
+	for rows.Next() {
+		var sub Subscription
+		if err := rows.Scan(&sub.Id, &sub.UserId, &sub.Platform, &sub.Endpoint, &sub.P256dh, &sub.Auth); err != nil {
+			return nil, fmt.Errorf("failed to scan push target: %w", err)
+		}
+		subs = append(subs, &sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating push targets: %w", err)
+	}
+	return subs, nil
+}
+
+func (r *PushRepository) Delete(ctx context.Context, userId, id string) error {
+	result, err := r.db.ExecContext(ctx, `
+        DELETE FROM push_subscriptions WHERE id = $1 AND user_id = $2
+    `, id, userId)
+	if err != nil {
+		return fmt.Errorf("failed to delete push subscription: %w", err)
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+// Remove menghapus subscription yang ditolak push service (404/410)
+func (r *PushRepository) Remove(ctx context.Context, id string) error {
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM push_subscriptions WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to remove push subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *PushRepository) MarkDelivered(ctx context.Context, id string) error {
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE push_subscriptions SET last_used_at = NOW(), failure_count = 0 WHERE id = $1
+    `, id)
+	if err != nil {
+		return fmt.Errorf("failed to update push subscription: %w", err)
+	}
+	return nil
+}
+
+// RecordFailure menambah hitungan gagal dan menghapus subscription jika sudah mencapai
+// maxFailures. pruned true jika subscription dihapus.
+func (r *PushRepository) RecordFailure(ctx context.Context, id string, maxFailures int) (bool, error) {
+	var count int
+	err := r.db.QueryRowContext(ctx, `
+        UPDATE push_subscriptions SET failure_count = failure_count + 1
+        WHERE id = $1
+        RETURNING failure_count
+    `, id).Scan(&count)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to record push failure: %w", err)
+	}
+	if count < maxFailures {
+		return false, nil
+	}
+	return true, r.Remove(ctx, id)
+}
@@ -0,0 +1,73 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy menentukan berapa kali dan berapa lama menunggu sebelum mengirim ulang
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Sleep bisa diganti di test supaya tidak benar-benar menunggu; nil berarti timer biasa
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// Backoff mengembalikan jeda sebelum retry ke-attempt (mulai dari 1) dengan jitter ±20%.
+// Retry-After dari provider dipakai jika lebih lama.
+func (p RetryPolicy) Backoff(attempt int, retryAfter time.Duration) time.Duration {
+	delay := p.BaseBackoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	delay += time.Duration((rand.Float64()*0.4 - 0.2) * float64(delay))
+	if retryAfter > delay {
+		delay = retryAfter
+	}
+	return delay
+}
+
+// Send mencoba kirim sampai berhasil, error permanen, atau percobaan habis.
+// Hanya TemporaryError yang dicoba ulang.
+func (p RetryPolicy) Send(ctx context.Context, provider PushProvider, sub *Subscription, msg *Message) error {
+	sleep := p.Sleep
+	if sleep == nil {
+		sleep = sleepContext
+	}
+
+	var err error
+	for attempt := 1; attempt <= p.MaxAttempts; attempt++ {
+		err = provider.Send(ctx, sub, msg)
+		var temporary *TemporaryError
+		if err == nil || !errors.As(err, &temporary) || attempt == p.MaxAttempts {
+			return err
+		}
+
+		delay := p.Backoff(attempt, temporary.RetryAfter)
+		// Retry-After yang melebihi batas tidak ditunggu; dihitung gagal dan dicoba di notifikasi berikutnya
+		if delay > p.MaxBackoff+p.MaxBackoff/5 {
+			return err
+		}
+		if sleep(ctx, delay) != nil {
+			return err
+		}
+	}
+	return err
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,80 @@
+package push_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/chatting-app/services/push"
+	"github.com/wafi04/chatting-app/services/push/pushtest"
+)
+
+func newPolicy(slept *[]time.Duration) push.RetryPolicy {
+	return push.RetryPolicy{
+		MaxAttempts: 3,
+		BaseBackoff: time.Second,
+		MaxBackoff:  10 * time.Second,
+		Sleep: func(ctx context.Context, d time.Duration) error {
+			*slept = append(*slept, d)
+			return nil
+		},
+	}
+}
+
+func TestRetrySucceedsAfterTemporaryErrors(t *testing.T) {
+	var slept []time.Duration
+	provider := pushtest.NewRecordingProvider("web",
+		&push.TemporaryError{Err: errors.New("503")},
+		&push.TemporaryError{Err: errors.New("429"), RetryAfter: 5 * time.Second},
+	)
+
+	err := newPolicy(&slept).Send(context.Background(), provider, &push.Subscription{Id: "s1"}, &push.Message{Title: "hi"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, provider.Calls())
+	require.Len(t, provider.Sent(), 1)
+	assert.Equal(t, "s1", provider.Sent()[0].Subscription.Id)
+
+	require.Len(t, slept, 2)
+	assert.InDelta(t, float64(time.Second), float64(slept[0]), float64(200*time.Millisecond))
+	// Retry-After lebih lama dari backoff ke-2 (2s) jadi dipakai
+	assert.Equal(t, 5*time.Second, slept[1])
+}
+
+func TestRetryStopsOnPermanentError(t *testing.T) {
+	var slept []time.Duration
+	provider := pushtest.NewRecordingProvider("web", push.ErrSubscriptionGone)
+
+	err := newPolicy(&slept).Send(context.Background(), provider, &push.Subscription{}, &push.Message{})
+	assert.ErrorIs(t, err, push.ErrSubscriptionGone)
+	assert.Equal(t, 1, provider.Calls())
+	assert.Empty(t, slept)
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	var slept []time.Duration
+	temporary := &push.TemporaryError{Err: errors.New("503")}
+	provider := pushtest.NewRecordingProvider("web", temporary, temporary, temporary, nil)
+
+	err := newPolicy(&slept).Send(context.Background(), provider, &push.Subscription{}, &push.Message{})
+	assert.ErrorAs(t, err, &temporary)
+	assert.Equal(t, 3, provider.Calls())
+	assert.Empty(t, provider.Sent())
+
+	// Retry-After melewati batas backoff tidak ditunggu
+	slept = nil
+	provider = pushtest.NewRecordingProvider("web", &push.TemporaryError{Err: errors.New("429"), RetryAfter: time.Hour})
+	err = newPolicy(&slept).Send(context.Background(), provider, &push.Subscription{}, &push.Message{})
+	assert.Error(t, err)
+	assert.Equal(t, 1, provider.Calls())
+	assert.Empty(t, slept)
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	policy := push.RetryPolicy{BaseBackoff: time.Second, MaxBackoff: 4 * time.Second}
+	for attempt := 1; attempt <= 10; attempt++ {
+		assert.LessOrEqual(t, policy.Backoff(attempt, 0), 4*time.Second+800*time.Millisecond)
+	}
+}
@@ -0,0 +1,10 @@
+package push
+
+import "github.com/gin-gonic/gin"
+
+func RegisterRoutes(r *gin.RouterGroup, h *PushHandler) {
+	r.GET("/vapid-public-key", h.HandlePublicKey)
+	r.GET("/subscriptions", h.HandleList)
+	r.POST("/subscriptions", h.HandleRegister)
+	r.DELETE("/subscriptions/:id", h.HandleUnregister)
+}
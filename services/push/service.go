@@ -0,0 +1,183 @@
+package push
+
+import (
+	"context"
+	"crypto/ecdh"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/wafi04/chatting-app/services/notifications"
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+const (
+	// Batas waktu satu push termasuk semua retry
+	deliverTimeout = 2 * time.Minute
+	maxEndpointLen = 2048
+)
+
+var ErrInvalidSubscription = errors.New("invalid push subscription")
+
+// PushService mendaftarkan subscription dan mengirim notifikasi sebagai channel "push"
+type PushService struct {
+	repo        *PushRepository
+	providers   map[string]PushProvider
+	retry       RetryPolicy
+	maxFailures int
+	log         *logger.Logger
+}
+
+func NewPushService(repo *PushRepository, config *Config, providers ...PushProvider) *PushService {
+	byPlatform := make(map[string]PushProvider, len(providers))
+	for _, provider := range providers {
+		byPlatform[provider.Platform()] = provider
+	}
+
+	return &PushService{
+		repo:      repo,
+		providers: byPlatform,
+		retry: RetryPolicy{
+			MaxAttempts: config.MaxAttempts,
+			BaseBackoff: config.BaseBackoff,
+			MaxBackoff:  config.MaxBackoff,
+		},
+		maxFailures: config.MaxFailures,
+		log:         logger.NewLogger(),
+	}
+}
+
+var _ notifications.Channel = (*PushService)(nil)
+
+func (s *PushService) Name() string {
+	return types.NotificationChannelPush
+}
+
+// Deliver mengirim push ke semua perangkat penerima di background. Selama quiet hours
+// push tidak dikirim; notifikasi tetap ada di in-app.
+func (s *PushService) Deliver(ctx context.Context, delivery *notifications.Delivery) error {
+	if delivery.Quiet || len(s.providers) == 0 {
+		return nil
+	}
+
+	subs, err := s.repo.Targets(ctx, delivery.Recipient)
+	if err != nil || len(subs) == 0 {
+		return err
+	}
+
+	msg := buildMessage(delivery.Item)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), deliverTimeout)
+	var wg sync.WaitGroup
+	for _, sub := range subs {
+		provider := s.providers[sub.Platform]
+		if provider == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(sub *Subscription) {
+			defer wg.Done()
+			s.send(ctx, provider, sub, msg)
+		}(sub)
+	}
+	go func() {
+		wg.Wait()
+		cancel()
+	}()
+	return nil
+}
+
+// send mengirim ke satu subscription lalu memperbarui status atau menghapusnya
+func (s *PushService) send(ctx context.Context, provider PushProvider, sub *Subscription, msg *Message) {
+	err := s.retry.Send(ctx, provider, sub, msg)
+	switch {
+	case err == nil:
+		if err := s.repo.MarkDelivered(ctx, sub.Id); err != nil {
+			s.log.Log(logger.WarnLevel, "Failed to mark push subscription %s as used: %v", sub.Id, err)
+		}
+	case errors.Is(err, ErrSubscriptionGone):
+		if err := s.repo.Remove(ctx, sub.Id); err != nil {
+			s.log.Log(logger.ErrorLevel, "Failed to remove expired push subscription %s: %v", sub.Id, err)
+		}
+	default:
+		s.log.Log(logger.WarnLevel, "Failed to send push to subscription %s: %v", sub.Id, err)
+		pruned, err := s.repo.RecordFailure(ctx, sub.Id, s.maxFailures)
+		if err != nil {
+			s.log.Log(logger.ErrorLevel, "Failed to record push failure: %v", err)
+		} else if pruned {
+			s.log.Log(logger.InfoLevel, "Removed push subscription %s after %d failures", sub.Id, s.maxFailures)
+		}
+	}
+}
+
+func buildMessage(item *types.NotificationItem) *Message {
+	msg := &Message{
+		Title: item.Text,
+		Body:  item.Preview,
+		Tag:   item.GroupKey,
+		Data: map[string]string{
+			"type":      item.Type,
+			"group_key": item.GroupKey,
+		},
+	}
+	if item.TargetID != "" {
+		msg.Data["target_id"] = item.TargetID
+	}
+	return msg
+}
+
+func (s *PushService) Register(ctx context.Context, userId, userAgent string, req *types.RegisterPushSubscriptionRequest) (*types.PushSubscription, error) {
+	if req.Platform == "" {
+		req.Platform = types.PushPlatformWeb
+	}
+	if s.providers[req.Platform] == nil {
+		return nil, fmt.Errorf("%w: platform %q is not supported", ErrInvalidSubscription, req.Platform)
+	}
+	if err := validateSubscription(req); err != nil {
+		return nil, err
+	}
+	return s.repo.Register(ctx, userId, userAgent, req)
+}
+
+func (s *PushService) List(ctx context.Context, userId string) ([]*types.PushSubscription, error) {
+	return s.repo.List(ctx, userId)
+}
+
+func (s *PushService) Unregister(ctx context.Context, userId, id string) error {
+	return s.repo.Delete(ctx, userId, id)
+}
+
+// PublicKey mengembalikan kunci VAPID untuk applicationServerKey; kosong jika Web Push tidak aktif
+func (s *PushService) PublicKey() string {
+	if web, ok := s.providers[types.PushPlatformWeb].(*WebPushProvider); ok {
+		return web.PublicKey()
+	}
+	return ""
+}
+
+func validateSubscription(req *types.RegisterPushSubscriptionRequest) error {
+	endpoint, err := url.Parse(req.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" || len(req.Endpoint) > maxEndpointLen {
+		return fmt.Errorf("%w: endpoint must be an https URL", ErrInvalidSubscription)
+	}
+	if err := validateEndpointHost(endpoint.Hostname()); err != nil {
+		return err
+	}
+
+	if req.Platform == types.PushPlatformWeb {
+		p256dh, err := decodeBase64URL(req.Keys.P256dh)
+		if err != nil {
+			return fmt.Errorf("%w: invalid p256dh key", ErrInvalidSubscription)
+		}
+		if _, err := ecdh.P256().NewPublicKey(p256dh); err != nil {
+			return fmt.Errorf("%w: invalid p256dh key", ErrInvalidSubscription)
+		}
+		auth, err := decodeBase64URL(req.Keys.Auth)
+		if err != nil || len(auth) != 16 {
+			return fmt.Errorf("%w: invalid auth secret", ErrInvalidSubscription)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package push
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var errForbiddenAddress = errors.New("push endpoint resolves to a non-public address")
+
+// Rentang yang tidak tercakup helper net.IP: shared address space (CGNAT) dan 0.0.0.0/8
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// isPublicIP melaporkan apakah ip boleh dihubungi untuk push (bukan loopback, private, link-local, dll)
+func isPublicIP(ip net.IP) bool {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateEndpointHost menolak host yang jelas bukan push service publik. DNS bisa berubah,
+// jadi alamat hasil resolve dicek lagi saat dial oleh publicOnlyClient.
+func validateEndpointHost(hostname string) error {
+	host := strings.ToLower(strings.TrimSuffix(hostname, "."))
+	if host == "" || net.ParseIP(host) != nil {
+		return fmt.Errorf("%w: endpoint host must be a domain name", ErrInvalidSubscription)
+	}
+	if host == "localhost" || !strings.Contains(host, ".") {
+		return fmt.Errorf("%w: endpoint host is not public", ErrInvalidSubscription)
+	}
+	for _, suffix := range []string{".localhost", ".local", ".internal", ".lan", ".home.arpa"} {
+		if strings.HasSuffix(host, suffix) {
+			return fmt.Errorf("%w: endpoint host is not public", ErrInvalidSubscription)
+		}
+	}
+	return nil
+}
+
+// publicOnlyClient adalah http client yang hanya mau terhubung ke alamat publik dan tidak
+// mengikuti redirect, supaya endpoint subscription tidak bisa dipakai untuk SSRF
+func publicOnlyClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !isPublicIP(net.ParseIP(host)) {
+				return errForbiddenAddress
+			}
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: 10 * time.Second,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
@@ -0,0 +1,61 @@
+package push
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	for _, ip := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "100.64.0.1", "0.0.0.0", "::1", "fe80::1", "fd00::1", "::ffff:127.0.0.1"} {
+		assert.False(t, isPublicIP(net.ParseIP(ip)), ip)
+	}
+	for _, ip := range []string{"142.250.4.95", "2a00:1450:4001:80b::200a"} {
+		assert.True(t, isPublicIP(net.ParseIP(ip)), ip)
+	}
+}
+
+func TestValidateSubscriptionEndpoint(t *testing.T) {
+	ua := newUserAgent(t)
+	keys := types.PushKeys{P256dh: ua.subscription("").P256dh, Auth: ua.subscription("").Auth}
+
+	for _, endpoint := range []string{
+		"http://fcm.googleapis.com/fcm/send/abc",
+		"https://127.0.0.1/push",
+		"https://[::1]/push",
+		"https://169.254.169.254/latest",
+		"https://localhost/push",
+		"https://metadata.internal/push",
+		"https://intranet/push",
+	} {
+		err := validateSubscription(&types.RegisterPushSubscriptionRequest{Platform: types.PushPlatformWeb, Endpoint: endpoint, Keys: keys})
+		assert.ErrorIs(t, err, ErrInvalidSubscription, endpoint)
+	}
+
+	err := validateSubscription(&types.RegisterPushSubscriptionRequest{
+		Platform: types.PushPlatformWeb,
+		Endpoint: "https://fcm.googleapis.com/fcm/send/abc",
+		Keys:     keys,
+	})
+	assert.NoError(t, err)
+}
+
+func TestPublicOnlyClientRefusesLoopback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL, nil)
+	require.NoError(t, err)
+	_, err = publicOnlyClient(time.Second).Do(req)
+	assert.True(t, errors.Is(err, errForbiddenAddress), "%v", err)
+}
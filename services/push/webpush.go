@@ -0,0 +1,287 @@
+package push
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/wafi04/chatting-app/services/shared/types"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// Ukuran record aes128gcm; payload push selalu muat dalam satu record
+	webPushRecordSize = 4096
+	// Batas payload Web Push setelah enkripsi
+	maxWebPushPayload = 4078
+	vapidTokenTTL     = 12 * time.Hour
+	defaultPushTTL    = 24 * time.Hour
+)
+
+var ErrPayloadTooLarge = errors.New("push payload is too large")
+
+// VAPIDKeys adalah pasangan kunci P-256 server dalam base64url (tanpa padding):
+// public key 65 byte format uncompressed, private key 32 byte
+type VAPIDKeys struct {
+	PublicKey  string
+	PrivateKey string
+	// Subject kontak operator, "mailto:..." atau URL https
+	Subject string
+}
+
+// WebPushProvider mengirim push ke browser lewat Web Push Protocol (RFC 8030)
+// dengan enkripsi payload RFC 8291 dan autentikasi VAPID (RFC 8292)
+type WebPushProvider struct {
+	key       *ecdsa.PrivateKey
+	publicKey string
+	subject   string
+	client    *http.Client
+	now       func() time.Time
+}
+
+// NewWebPushProvider memvalidasi kunci VAPID; client nil berarti http client yang hanya
+// terhubung ke alamat publik
+func NewWebPushProvider(keys VAPIDKeys, client *http.Client) (*WebPushProvider, error) {
+	key, err := parseVAPIDKeys(keys.PublicKey, keys.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(keys.Subject, "mailto:") && !strings.HasPrefix(keys.Subject, "https://") {
+		return nil, errors.New("vapid subject must be a mailto: or https: URL")
+	}
+	if client == nil {
+		client = publicOnlyClient(15 * time.Second)
+	}
+
+	return &WebPushProvider{
+		key:       key,
+		publicKey: keys.PublicKey,
+		subject:   keys.Subject,
+		client:    client,
+		now:       time.Now,
+	}, nil
+}
+
+func (p *WebPushProvider) Platform() string {
+	return types.PushPlatformWeb
+}
+
+// PublicKey dipakai browser sebagai applicationServerKey saat subscribe
+func (p *WebPushProvider) PublicKey() string {
+	return p.publicKey
+}
+
+func (p *WebPushProvider) Send(ctx context.Context, sub *Subscription, msg *Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode push message: %w", err)
+	}
+	body, err := encryptWebPush(payload, sub.P256dh, sub.Auth)
+	if err != nil {
+		return err
+	}
+
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return fmt.Errorf("%w: invalid endpoint", ErrSubscriptionGone)
+	}
+	token, err := p.vapidToken(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return err
+	}
+
+	ttl := msg.TTL
+	if ttl <= 0 {
+		ttl = defaultPushTTL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create push request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	req.Header.Set("Urgency", "normal")
+	req.Header.Set("Authorization", fmt.Sprintf("vapid t=%s, k=%s", token, p.publicKey))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return &TemporaryError{Err: err}
+	}
+	defer resp.Body.Close()
+	detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrSubscriptionGone
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return &TemporaryError{
+			Err:        fmt.Errorf("push service returned %d: %s", resp.StatusCode, detail),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), p.now()),
+		}
+	default:
+		return fmt.Errorf("push service returned %d: %s", resp.StatusCode, detail)
+	}
+}
+
+// vapidToken membuat JWT ES256 untuk origin push service (RFC 8292)
+func (p *WebPushProvider) vapidToken(audience string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.StandardClaims{
+		Audience:  audience,
+		ExpiresAt: p.now().Add(vapidTokenTTL).Unix(),
+		Subject:   p.subject,
+	})
+	signed, err := token.SignedString(p.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign vapid token: %w", err)
+	}
+	return signed, nil
+}
+
+// encryptWebPush mengenkripsi payload dengan skema aes128gcm (RFC 8291)
+func encryptWebPush(payload []byte, p256dh, authSecret string) ([]byte, error) {
+	if len(payload) > maxWebPushPayload-1 {
+		return nil, ErrPayloadTooLarge
+	}
+	uaPublicBytes, err := decodeBase64URL(p256dh)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid p256dh key", ErrSubscriptionGone)
+	}
+	auth, err := decodeBase64URL(authSecret)
+	if err != nil || len(auth) != 16 {
+		return nil, fmt.Errorf("%w: invalid auth secret", ErrSubscriptionGone)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid p256dh key", ErrSubscriptionGone)
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate push key: %w", err)
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+	shared, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive push secret: %w", err)
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("failed to generate push salt: %w", err)
+	}
+
+	keyInfo := append(append([]byte("WebPush: info\x00"), uaPublicBytes...), asPublic...)
+	ikm, err := hkdfExpand(auth, shared, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdfExpand(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdfExpand(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create push cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create push cipher: %w", err)
+	}
+	// 0x02 menandai record terakhir
+	plaintext := append(append([]byte{}, payload...), 0x02)
+
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, webPushRecordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+func hkdfExpand(salt, secret, info []byte, length int) ([]byte, error) {
+	out := make([]byte, length)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, salt, info), out); err != nil {
+		return nil, fmt.Errorf("failed to derive push key: %w", err)
+	}
+	return out, nil
+}
+
+func parseVAPIDKeys(publicKey, privateKey string) (*ecdsa.PrivateKey, error) {
+	privateBytes, err := decodeBase64URL(privateKey)
+	if err != nil {
+		return nil, errors.New("invalid vapid private key")
+	}
+	ecdhKey, err := ecdh.P256().NewPrivateKey(privateBytes)
+	if err != nil {
+		return nil, errors.New("invalid vapid private key")
+	}
+	publicBytes, err := decodeBase64URL(publicKey)
+	if err != nil || !bytes.Equal(publicBytes, ecdhKey.PublicKey().Bytes()) {
+		return nil, errors.New("vapid public key does not match private key")
+	}
+
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(publicBytes[1:33]),
+			Y:     new(big.Int).SetBytes(publicBytes[33:]),
+		},
+		D: new(big.Int).SetBytes(privateBytes),
+	}, nil
+}
+
+// GenerateVAPIDKeys membuat pasangan kunci VAPID baru dalam base64url
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// decodeBase64URL menerima base64url dengan atau tanpa padding (browser bisa mengirim keduanya)
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,172 @@
+package push
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// userAgent mensimulasikan browser yang memegang kunci subscription
+type userAgent struct {
+	key  *ecdh.PrivateKey
+	auth []byte
+}
+
+func newUserAgent(t *testing.T) *userAgent {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	_, err = rand.Read(auth)
+	require.NoError(t, err)
+	return &userAgent{key: key, auth: auth}
+}
+
+func (ua *userAgent) subscription(endpoint string) *Subscription {
+	return &Subscription{
+		Id:       "sub-1",
+		Platform: "web",
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(ua.key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(ua.auth),
+	}
+}
+
+// decrypt membuka body aes128gcm seperti yang dilakukan browser (RFC 8291)
+func (ua *userAgent) decrypt(t *testing.T, body []byte) []byte {
+	require.Greater(t, len(body), 21)
+	salt := body[:16]
+	assert.Equal(t, uint32(webPushRecordSize), binary.BigEndian.Uint32(body[16:20]))
+	idLen := int(body[20])
+	asPublicBytes := body[21 : 21+idLen]
+
+	asPublic, err := ecdh.P256().NewPublicKey(asPublicBytes)
+	require.NoError(t, err)
+	shared, err := ua.key.ECDH(asPublic)
+	require.NoError(t, err)
+
+	keyInfo := append(append([]byte("WebPush: info\x00"), ua.key.PublicKey().Bytes()...), asPublicBytes...)
+	ikm, err := hkdfExpand(ua.auth, shared, keyInfo, 32)
+	require.NoError(t, err)
+	cek, err := hkdfExpand(salt, ikm, []byte("Content-Encoding: aes128gcm\x00"), 16)
+	require.NoError(t, err)
+	nonce, err := hkdfExpand(salt, ikm, []byte("Content-Encoding: nonce\x00"), 12)
+	require.NoError(t, err)
+
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	plaintext, err := gcm.Open(nil, nonce, body[21+idLen:], nil)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x02), plaintext[len(plaintext)-1])
+	return plaintext[:len(plaintext)-1]
+}
+
+func newTestProvider(t *testing.T, client *http.Client) *WebPushProvider {
+	publicKey, privateKey, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	provider, err := NewWebPushProvider(VAPIDKeys{
+		PublicKey:  publicKey,
+		PrivateKey: privateKey,
+		Subject:    "mailto:ops@example.com",
+	}, client)
+	require.NoError(t, err)
+	return provider
+}
+
+func TestWebPushSend(t *testing.T) {
+	ua := newUserAgent(t)
+	var received *http.Request
+	var body []byte
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	provider := newTestProvider(t, server.Client())
+	msg := &Message{Title: "Budi liked your post", Tag: "post_like:p1", TTL: time.Hour}
+	require.NoError(t, provider.Send(context.Background(), ua.subscription(server.URL+"/push/abc"), msg))
+
+	assert.Equal(t, "aes128gcm", received.Header.Get("Content-Encoding"))
+	assert.Equal(t, "3600", received.Header.Get("TTL"))
+
+	var decoded Message
+	require.NoError(t, json.Unmarshal(ua.decrypt(t, body), &decoded))
+	assert.Equal(t, msg.Title, decoded.Title)
+	assert.Equal(t, msg.Tag, decoded.Tag)
+
+	// Token VAPID ditandatangani kunci server dan ditujukan ke origin push service
+	auth := received.Header.Get("Authorization")
+	require.True(t, strings.HasPrefix(auth, "vapid t="))
+	parts := strings.SplitN(strings.TrimPrefix(auth, "vapid t="), ", k=", 2)
+	require.Len(t, parts, 2)
+	assert.Equal(t, provider.PublicKey(), parts[1])
+
+	claims := &jwt.StandardClaims{}
+	_, err := jwt.ParseWithClaims(parts[0], claims, func(*jwt.Token) (interface{}, error) {
+		return &provider.key.PublicKey, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, server.URL, claims.Audience)
+	assert.Equal(t, "mailto:ops@example.com", claims.Subject)
+}
+
+func TestWebPushStatusMapping(t *testing.T) {
+	cases := []struct {
+		status    int
+		gone      bool
+		temporary bool
+	}{
+		{http.StatusGone, true, false},
+		{http.StatusNotFound, true, false},
+		{http.StatusTooManyRequests, false, true},
+		{http.StatusServiceUnavailable, false, true},
+		{http.StatusBadRequest, false, false},
+	}
+
+	for _, tc := range cases {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Retry-After", "7")
+			w.WriteHeader(tc.status)
+		}))
+		provider := newTestProvider(t, server.Client())
+		err := provider.Send(context.Background(), newUserAgent(t).subscription(server.URL), &Message{Title: "hi"})
+		server.Close()
+
+		require.Error(t, err, tc.status)
+		assert.Equal(t, tc.gone, errors.Is(err, ErrSubscriptionGone), tc.status)
+		var temporary *TemporaryError
+		assert.Equal(t, tc.temporary, errors.As(err, &temporary), tc.status)
+		if tc.temporary {
+			assert.Equal(t, 7*time.Second, temporary.RetryAfter)
+		}
+	}
+}
+
+func TestNewWebPushProviderRejectsMismatchedKeys(t *testing.T) {
+	publicKey, _, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+	_, privateKey, err := GenerateVAPIDKeys()
+	require.NoError(t, err)
+
+	_, err = NewWebPushProvider(VAPIDKeys{PublicKey: publicKey, PrivateKey: privateKey, Subject: "mailto:a@b.c"}, nil)
+	assert.Error(t, err)
+}
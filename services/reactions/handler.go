@@ -0,0 +1,125 @@
+package reactions
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/chatting-app/services/shared/middleware"
+	"github.com/wafi04/chatting-app/services/shared/pkg/response"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+type ReactionHandler struct {
+	srv *ReactionService
+}
+
+func NewReactionHandler(srv *ReactionService) *ReactionHandler {
+	return &ReactionHandler{
+		srv: srv,
+	}
+}
+
+// HandleSetReaction adds or replaces the current user's reaction on a target
+func (h *ReactionHandler) HandleSetReaction(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	var req struct {
+		Reaction string `json:"reaction"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed input", err.Error())
+		return
+	}
+
+	data, err := h.srv.SetReaction(c, user.UserId, c.Param("type"), c.Param("id"), req.Reaction)
+	if errors.Is(err, ErrTargetNotFound) {
+		response.SendErrorResponse(c, http.StatusNotFound, "Target not found")
+		return
+	}
+	if err != nil {
+		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to set reaction", err.Error())
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Reaction set successfully", data)
+}
+
+// HandleRemoveReaction removes the current user's reaction from a target
+func (h *ReactionHandler) HandleRemoveReaction(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	if err := h.srv.RemoveReaction(c, user.UserId, c.Param("type"), c.Param("id")); err != nil {
+		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to remove reaction", err.Error())
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Reaction removed successfully", nil)
+}
+
+// HandleGetReactionSummary returns per-reaction counts for a target
+func (h *ReactionHandler) HandleGetReactionSummary(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	data, err := h.srv.GetReactionSummary(c, user.UserId, c.Param("type"), c.Param("id"))
+	if errors.Is(err, ErrTargetNotFound) {
+		response.SendErrorResponse(c, http.StatusNotFound, "Target not found")
+		return
+	}
+	if err != nil {
+		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to get reactions", err.Error())
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Reactions retrieved successfully", data)
+}
+
+// HandleListReactedUsers lists users who reacted on a target, optionally filtered by reaction
+func (h *ReactionHandler) HandleListReactedUsers(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	data, err := h.srv.ListReactedUsers(c, &types.ListReactedUsersRequest{
+		ViewerID:   user.UserId,
+		TargetType: c.Param("type"),
+		TargetID:   c.Param("id"),
+		Reaction:   c.Query("reaction"),
+		Page:       int64(page),
+		Limit:      int64(limit),
+	})
+	if errors.Is(err, ErrTargetNotFound) {
+		response.SendErrorResponse(c, http.StatusNotFound, "Target not found")
+		return
+	}
+	if err != nil {
+		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to get reacted users", err.Error())
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Reacted users retrieved successfully", data)
+}
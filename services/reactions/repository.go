@@ -0,0 +1,230 @@
+package reactions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/wafi04/chatting-app/services/likes"
+	"github.com/wafi04/chatting-app/services/shared/types"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReactionRepository struct {
+	mongoClient *mongo.Client
+	database    string
+}
+
+type Repository interface {
+	EnsureIndexes(ctx context.Context) error
+	SetReaction(ctx context.Context, userId, targetType, targetId, reaction string) (*types.Reaction, error)
+	RemoveReaction(ctx context.Context, userId, targetType, targetId string) error
+	GetReactionSummary(ctx context.Context, userId, targetType, targetId string) (*types.ReactionSummary, error)
+	GetReactions(ctx context.Context, req *types.ListReactedUsersRequest, excludeUsers []string) ([]types.Reaction, int64, error)
+	GetUserReactions(ctx context.Context, userId string) ([]types.Reaction, error)
+	DeleteUserReactions(ctx context.Context, userId string, targetIds []string) (int64, error)
+}
+
+func NewReactionRepository(mongoClient *mongo.Client) Repository {
+	return &ReactionRepository{
+		mongoClient: mongoClient,
+		database:    likes.MongoDatabase(),
+	}
+}
+
+func (rr *ReactionRepository) collection() *mongo.Collection {
+	return rr.mongoClient.Database(rr.database).Collection("reactions")
+}
+
+// EnsureIndexes memastikan satu user hanya punya satu reaction per target
+func (rr *ReactionRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := rr.collection().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys: bson.D{
+				{Key: "target_type", Value: 1},
+				{Key: "target_id", Value: 1},
+				{Key: "user_id", Value: 1},
+			},
+			Options: options.Index().SetName("uniq_target_user").SetUnique(true),
+		},
+		{
+			Keys: bson.D{
+				{Key: "target_type", Value: 1},
+				{Key: "target_id", Value: 1},
+				{Key: "reaction", Value: 1},
+				{Key: "created_at", Value: -1},
+			},
+			Options: options.Index().SetName("target_reaction_created_at"),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create reaction indexes: %w", err)
+	}
+
+	return nil
+}
+
+// SetReaction membuat reaction baru atau mengganti reaction user yang sudah ada pada target
+func (rr *ReactionRepository) SetReaction(ctx context.Context, userId, targetType, targetId, reaction string) (*types.Reaction, error) {
+	filter := bson.M{
+		"user_id":     userId,
+		"target_type": targetType,
+		"target_id":   targetId,
+	}
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"reaction":   reaction,
+			"updated_at": now,
+		},
+		"$setOnInsert": bson.M{
+			"_id":        primitive.NewObjectID(),
+			"created_at": now,
+		},
+	}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	var result types.Reaction
+	err := rr.collection().FindOneAndUpdate(ctx, filter, update, opts).Decode(&result)
+	if mongo.IsDuplicateKeyError(err) {
+		// Upsert bersamaan dari user yang sama, ulangi sebagai update biasa
+		err = rr.collection().FindOneAndUpdate(ctx, filter, bson.M{"$set": update["$set"]}, opts).Decode(&result)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to set reaction: %w", err)
+	}
+
+	return &result, nil
+}
+
+func (rr *ReactionRepository) RemoveReaction(ctx context.Context, userId, targetType, targetId string) error {
+	_, err := rr.collection().DeleteOne(ctx, bson.M{
+		"user_id":     userId,
+		"target_type": targetType,
+		"target_id":   targetId,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove reaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetReactionSummary mengembalikan jumlah per reaction dan reaction milik userId pada target
+func (rr *ReactionRepository) GetReactionSummary(ctx context.Context, userId, targetType, targetId string) (*types.ReactionSummary, error) {
+	target := bson.M{
+		"target_type": targetType,
+		"target_id":   targetId,
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: target}},
+		{{Key: "$group", Value: bson.M{"_id": "$reaction", "count": bson.M{"$sum": 1}}}},
+	}
+
+	cursor, err := rr.collection().Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count reactions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	summary := &types.ReactionSummary{
+		TargetType: targetType,
+		TargetID:   targetId,
+		Counts:     make(map[string]int64),
+	}
+	for cursor.Next(ctx) {
+		var row struct {
+			Reaction string `bson:"_id"`
+			Count    int64  `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, fmt.Errorf("failed to decode reaction count: %w", err)
+		}
+		summary.Counts[row.Reaction] = row.Count
+		summary.Total += row.Count
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("error while iterating reaction counts: %w", err)
+	}
+
+	var mine types.Reaction
+	target["user_id"] = userId
+	err = rr.collection().FindOne(ctx, target).Decode(&mine)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, fmt.Errorf("failed to get user reaction: %w", err)
+	}
+	summary.MyReaction = mine.Reaction
+
+	return summary, nil
+}
+
+// GetReactions mengembalikan reaction pada target, terbaru lebih dulu, beserta total keseluruhan
+func (rr *ReactionRepository) GetReactions(ctx context.Context, req *types.ListReactedUsersRequest, excludeUsers []string) ([]types.Reaction, int64, error) {
+	filter := bson.M{
+		"target_type": req.TargetType,
+		"target_id":   req.TargetID,
+	}
+	if req.Reaction != "" {
+		filter["reaction"] = req.Reaction
+	}
+	if len(excludeUsers) > 0 {
+		filter["user_id"] = bson.M{"$nin": excludeUsers}
+	}
+
+	total, err := rr.collection().CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count reactions: %w", err)
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip((req.Page - 1) * req.Limit).
+		SetLimit(req.Limit)
+
+	cursor, err := rr.collection().Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get reactions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var reactions []types.Reaction
+	if err = cursor.All(ctx, &reactions); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode reactions: %w", err)
+	}
+
+	return reactions, total, nil
+}
+
+func (rr *ReactionRepository) GetUserReactions(ctx context.Context, userId string) ([]types.Reaction, error) {
+	cursor, err := rr.collection().Find(ctx, bson.M{"user_id": userId}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user reactions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	reactions := []types.Reaction{}
+	if err := cursor.All(ctx, &reactions); err != nil {
+		return nil, fmt.Errorf("failed to decode user reactions: %w", err)
+	}
+	return reactions, nil
+}
+
+// DeleteUserReactions menghapus reaction milik user dan semua reaction pada targetIds
+func (rr *ReactionRepository) DeleteUserReactions(ctx context.Context, userId string, targetIds []string) (int64, error) {
+	conditions := bson.A{bson.M{"user_id": userId}}
+	if len(targetIds) > 0 {
+		conditions = append(conditions, bson.M{"target_id": bson.M{"$in": targetIds}})
+	}
+
+	res, err := rr.collection().DeleteMany(ctx, bson.M{"$or": conditions})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete user reactions: %w", err)
+	}
+	return res.DeletedCount, nil
+}
@@ -0,0 +1,11 @@
+package reactions
+
+import "github.com/gin-gonic/gin"
+
+// :type adalah post atau comment
+func RegisterRoutes(r *gin.RouterGroup, handler *ReactionHandler) {
+	r.PUT("/:type/:id", handler.HandleSetReaction)
+	r.DELETE("/:type/:id", handler.HandleRemoveReaction)
+	r.GET("/:type/:id", handler.HandleGetReactionSummary)
+	r.GET("/:type/:id/users", handler.HandleListReactedUsers)
+}
@@ -0,0 +1,175 @@
+package reactions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/wafi04/chatting-app/config/env"
+	authrepository "github.com/wafi04/chatting-app/services/auth/pkg/repository"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+// DefaultReactions dipakai jika env REACTIONS tidak diset
+var DefaultReactions = []string{"❤️", "😂", "😮", "😢", "😡", "👍"}
+
+// LoadAllowedReactions membaca daftar reaction dari env REACTIONS (dipisah koma)
+func LoadAllowedReactions() []string {
+	value := env.LoadEnv("REACTIONS")
+	if value == "" {
+		return DefaultReactions
+	}
+
+	var allowed []string
+	for _, r := range strings.Split(value, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			allowed = append(allowed, r)
+		}
+	}
+	if len(allowed) == 0 {
+		return DefaultReactions
+	}
+	return allowed
+}
+
+// Relations memberi informasi blokir milik viewer
+type Relations interface {
+	GetBlockedByIDs(ctx context.Context, userID string) ([]string, error)
+}
+
+type ReactionService struct {
+	repo      Repository
+	authrepo  *authrepository.AuthRepository
+	relations Relations
+	targets   TargetAccess
+	allowed   map[string]bool
+}
+
+func NewReactionService(repo Repository, authrepo *authrepository.AuthRepository, relations Relations, targets TargetAccess, allowed []string) *ReactionService {
+	set := make(map[string]bool, len(allowed))
+	for _, r := range allowed {
+		set[r] = true
+	}
+
+	return &ReactionService{
+		repo:      repo,
+		authrepo:  authrepo,
+		relations: relations,
+		targets:   targets,
+		allowed:   set,
+	}
+}
+
+// Pesan chat belum disimpan di service ini sehingga keanggotaan percakapan tidak
+// bisa dicek; target "message" ditolak sebagai input tidak valid sampai ada
+func validateTargetType(targetType string) error {
+	switch targetType {
+	case types.ReactionTargetPost, types.ReactionTargetComment:
+		return nil
+	default:
+		return fmt.Errorf("invalid reaction target: %s", targetType)
+	}
+}
+
+// checkTarget memastikan target ada dan boleh dilihat viewer
+func (s *ReactionService) checkTarget(ctx context.Context, viewerId, targetType, targetId string) error {
+	ok, err := s.targets.CanAccess(ctx, viewerId, targetType, targetId)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTargetNotFound
+	}
+	return nil
+}
+
+func (s *ReactionService) validateReaction(reaction string) error {
+	if !s.allowed[reaction] {
+		return fmt.Errorf("reaction %q is not allowed", reaction)
+	}
+	return nil
+}
+
+func (s *ReactionService) SetReaction(ctx context.Context, userId, targetType, targetId, reaction string) (*types.Reaction, error) {
+	if err := validateTargetType(targetType); err != nil {
+		return nil, err
+	}
+	if err := s.validateReaction(reaction); err != nil {
+		return nil, err
+	}
+	if err := s.checkTarget(ctx, userId, targetType, targetId); err != nil {
+		return nil, err
+	}
+
+	return s.repo.SetReaction(ctx, userId, targetType, targetId, reaction)
+}
+
+func (s *ReactionService) RemoveReaction(ctx context.Context, userId, targetType, targetId string) error {
+	if err := validateTargetType(targetType); err != nil {
+		return err
+	}
+
+	return s.repo.RemoveReaction(ctx, userId, targetType, targetId)
+}
+
+func (s *ReactionService) GetReactionSummary(ctx context.Context, userId, targetType, targetId string) (*types.ReactionSummary, error) {
+	if err := validateTargetType(targetType); err != nil {
+		return nil, err
+	}
+	if err := s.checkTarget(ctx, userId, targetType, targetId); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetReactionSummary(ctx, userId, targetType, targetId)
+}
+
+// ListReactedUsers mengembalikan user yang memberi reaction pada target; seperti daftar
+// liker, user yang memblokir viewer tidak ditampilkan
+func (s *ReactionService) ListReactedUsers(ctx context.Context, req *types.ListReactedUsersRequest) (*types.ListReactedUsersResponse, error) {
+	if err := validateTargetType(req.TargetType); err != nil {
+		return nil, err
+	}
+	if req.Reaction != "" {
+		if err := s.validateReaction(req.Reaction); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.checkTarget(ctx, req.ViewerID, req.TargetType, req.TargetID); err != nil {
+		return nil, err
+	}
+	blockedBy, err := s.relations.GetBlockedByIDs(ctx, req.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	reactions, total, err := s.repo.GetReactions(ctx, req, blockedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make([]*types.ReactedUser, 0, len(reactions))
+	for _, r := range reactions {
+		user, err := s.authrepo.GetUser(ctx, &types.GetUserRequest{
+			UserId: r.UserID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user %s: %w", r.UserID, err)
+		}
+		users = append(users, &types.ReactedUser{
+			UserInfo: types.UserInfo{
+				UserId:  user.UserId,
+				Name:    user.Name,
+				Picture: user.Picture,
+			},
+			Reaction:  r.Reaction,
+			ReactedAt: r.CreatedAt,
+		})
+	}
+
+	return &types.ListReactedUsersResponse{
+		Users: users,
+		Total: total,
+		Page:  req.Page,
+	}, nil
+}
@@ -0,0 +1,67 @@
+package reactions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	postrepo "github.com/wafi04/chatting-app/services/post/repository/post"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+// ErrTargetNotFound dipakai untuk target yang tidak ada maupun yang tidak boleh dilihat
+// viewer, supaya keberadaan konten privat tidak bocor
+var ErrTargetNotFound = errors.New("reaction target not found")
+
+// TargetAccess memeriksa apakah target reaction ada dan boleh dilihat viewer
+type TargetAccess interface {
+	CanAccess(ctx context.Context, viewerId, targetType, targetId string) (bool, error)
+}
+
+// TargetRepository memeriksa post dan comment di Postgres dengan aturan yang sama
+// seperti feed: pemilik aktif, akun privat hanya untuk follower, dan tidak ada blokir
+type TargetRepository struct {
+	db *sqlx.DB
+}
+
+func NewTargetRepository(db *sqlx.DB) *TargetRepository {
+	return &TargetRepository{
+		db: db,
+	}
+}
+
+func (r *TargetRepository) CanAccess(ctx context.Context, viewerId, targetType, targetId string) (bool, error) {
+	var query string
+	switch targetType {
+	case types.ReactionTargetPost:
+		query = `
+        SELECT EXISTS (
+            SELECT 1 FROM posts p
+            WHERE p.id = $2
+            AND ` + postrepo.AuthorActive("p.user_id") + `
+            AND ` + postrepo.VisibleTo("p.user_id", "$1") + `
+            AND ` + postrepo.NotBlocked("p.user_id", "$1") + `
+        )`
+	case types.ReactionTargetComment:
+		query = `
+        SELECT EXISTS (
+            SELECT 1 FROM comments c
+            JOIN posts p ON p.id = c.post_id
+            WHERE c.id = $2
+            AND ` + postrepo.AuthorActive("p.user_id") + `
+            AND ` + postrepo.AuthorActive("c.user_id") + `
+            AND ` + postrepo.VisibleTo("p.user_id", "$1") + `
+            AND ` + postrepo.NotBlocked("p.user_id", "$1") + `
+            AND ` + postrepo.NotBlocked("c.user_id", "$1") + `
+        )`
+	default:
+		return false, nil
+	}
+
+	var ok bool
+	if err := r.db.QueryRowContext(ctx, query, viewerId, targetId).Scan(&ok); err != nil {
+		return false, fmt.Errorf("failed to check reaction target: %w", err)
+	}
+	return ok, nil
+}
@@ -0,0 +1,65 @@
+package search
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/chatting-app/services/shared/middleware"
+	"github.com/wafi04/chatting-app/services/shared/pkg/response"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+type SearchHandler struct {
+	srv *SearchService
+}
+
+func NewSearchHandler(srv *SearchService) *SearchHandler {
+	return &SearchHandler{
+		srv: srv,
+	}
+}
+
+func (h *SearchHandler) HandleSearch(c *gin.Context) {
+	user, err := middleware.GetUserFromGinContext(c)
+	if err != nil {
+		response.SendErrorResponse(c, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+	if query == "" {
+		response.SendErrorResponse(c, http.StatusBadRequest, "Query is required")
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if page <= 0 {
+		page = 1
+	}
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+
+	// ?type=users,posts — default semua tipe
+	searchTypes := []string{types.SearchTypeUsers, types.SearchTypePosts, types.SearchTypeComments}
+	if t := c.Query("type"); t != "" && t != "all" {
+		searchTypes = strings.Split(t, ",")
+	}
+
+	data, err := h.srv.Search(c, &types.SearchRequest{
+		ViewerID: user.UserId,
+		Query:    query,
+		Types:    searchTypes,
+		Page:     int32(page),
+		Limit:    int32(limit),
+	})
+	if err != nil {
+		response.SendErrorResponseWithDetails(c, http.StatusBadRequest, "Failed to search", err.Error())
+		return
+	}
+
+	response.SendSuccessResponse(c, http.StatusOK, "Search Successfully", data)
+}
@@ -0,0 +1,225 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/jmoiron/sqlx"
+	authrepository "github.com/wafi04/chatting-app/services/auth/pkg/repository"
+	postrepo "github.com/wafi04/chatting-app/services/post/repository/post"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+type SearchRepository struct {
+	db       *sqlx.DB
+	authrepo *authrepository.AuthRepository
+	postrepo *postrepo.PostRepository
+}
+
+func NewSearchRepository(db *sqlx.DB, authrepo *authrepository.AuthRepository, postrepo *postrepo.PostRepository) *SearchRepository {
+	return &SearchRepository{
+		db:       db,
+		authrepo: authrepo,
+		postrepo: postrepo,
+	}
+}
+
+// Semua query di bawah memakai $1 sebagai viewer id.
+
+// BuildPrefixQuery mengubah input bebas menjadi tsquery prefix, misal "go lang" -> "go:* & lang:*".
+// Karakter selain huruf, angka dan underscore dibuang supaya input tidak bisa merusak sintaks tsquery.
+func BuildPrefixQuery(input string) string {
+	var terms []string
+	for _, word := range strings.Fields(strings.ToLower(input)) {
+		word = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+				return r
+			}
+			return -1
+		}, word)
+		if word != "" {
+			terms = append(terms, word+":*")
+		}
+	}
+	return strings.Join(terms, " & ")
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (r *SearchRepository) SearchUsers(ctx context.Context, req *types.SearchRequest, tsQuery string) ([]*types.SearchUser, error) {
+	// Username dicocokkan dengan prefix dan trigram similarity supaya salah ketik kecil tetap ketemu
+	query := `
+    SELECT 
+        u.user_id,
+        u.name,
+        COALESCE(p.username, ''),
+        COALESCE(u.picture, ''),
+        COALESCE(p.is_privacy, false),
+        ts_rank(u.search_vector || COALESCE(p.search_vector, ''::tsvector), to_tsquery('simple', $2))
+            + similarity(COALESCE(p.username, ''), $3)
+            + CASE WHEN p.username ILIKE $4 THEN 1 ELSE 0 END AS rank
+    FROM 
+        users u
+    LEFT JOIN 
+        user_profile p ON p.user_id = u.user_id
+    WHERE 
+        u.is_active = true
+        AND (
+            (u.search_vector || COALESCE(p.search_vector, ''::tsvector)) @@ to_tsquery('simple', $2)
+            OR p.username ILIKE $4
+            OR p.username % $3
+        )
+        AND ` + postrepo.NotBlocked("u.user_id", "$1") + `
+    ORDER BY 
+        rank DESC, u.name
+    LIMIT $5 OFFSET $6
+    `
+
+	term := strings.ToLower(strings.TrimSpace(req.Query))
+	offset := (req.Page - 1) * req.Limit
+
+	rows, err := r.db.QueryContext(ctx, query, req.ViewerID, tsQuery, term, escapeLike(term)+"%", req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []*types.SearchUser{}
+	for rows.Next() {
+		user := &types.SearchUser{}
+		if err := rows.Scan(
+			&user.UserId,
+			&user.Name,
+			&user.Username,
+			&user.Picture,
+			&user.IsPrivacy,
+			&user.Rank,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while iterating users: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *SearchRepository) SearchPosts(ctx context.Context, req *types.SearchRequest, tsQuery string) ([]*types.Post, error) {
+	query := `
+    SELECT 
+        p.id,
+        p.user_id,
+        p.caption,
+        p.location,
+        p.tags,
+        p.mentions,
+        p.created_at,
+        p.updated_at,
+        p.like_count,
+        p.comment_count
+    FROM 
+        posts p
+    WHERE 
+        p.search_vector @@ to_tsquery('simple', $2)
+        AND ` + postrepo.VisibleTo("p.user_id", "$1") + `
+        AND ` + postrepo.NotBlocked("p.user_id", "$1") + `
+        AND ` + postrepo.AuthorActive("p.user_id") + `
+    ORDER BY 
+        ts_rank(p.search_vector, to_tsquery('simple', $2)) DESC, p.created_at DESC
+    LIMIT $3 OFFSET $4
+    `
+
+	offset := (req.Page - 1) * req.Limit
+
+	posts, err := r.postrepo.QueryPosts(ctx, query, req.ViewerID, tsQuery, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search posts: %w", err)
+	}
+
+	return posts, nil
+}
+
+func (r *SearchRepository) SearchComments(ctx context.Context, req *types.SearchRequest, tsQuery string) ([]*types.Comment, error) {
+	query := `
+    SELECT 
+        c.id,
+        c.user_id,
+        c.post_id,
+        c.content,
+        c.depth,
+        c.created_at,
+        c.parent_comment_id,
+        c.like_count,
+        c.reply_count
+    FROM 
+        comments c
+    JOIN 
+        posts p ON p.id = c.post_id
+    WHERE 
+        c.search_vector @@ to_tsquery('simple', $2)
+        AND ` + postrepo.VisibleTo("p.user_id", "$1") + `
+        AND ` + postrepo.NotBlocked("p.user_id", "$1") + `
+        AND ` + postrepo.NotBlocked("c.user_id", "$1") + `
+        AND ` + postrepo.AuthorActive("p.user_id") + `
+        AND ` + postrepo.AuthorActive("c.user_id") + `
+    ORDER BY 
+        ts_rank(c.search_vector, to_tsquery('simple', $2)) DESC, c.created_at DESC
+    LIMIT $3 OFFSET $4
+    `
+
+	offset := (req.Page - 1) * req.Limit
+
+	rows, err := r.db.QueryContext(ctx, query, req.ViewerID, tsQuery, req.Limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search comments: %w", err)
+	}
+	defer rows.Close()
+
+	comments := []*types.Comment{}
+	for rows.Next() {
+		comm := &types.Comment{}
+		var parentID sql.NullString
+
+		if err := rows.Scan(
+			&comm.ID,
+			&comm.UserID,
+			&comm.PostID,
+			&comm.Content,
+			&comm.Depth,
+			&comm.CreatedAT,
+			&parentID,
+			&comm.LikeCount,
+			&comm.ReplyCount,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		if parentID.Valid {
+			comm.ParentID = &parentID.String
+		}
+		comments = append(comments, comm)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error while iterating comments: %w", err)
+	}
+
+	for _, comm := range comments {
+		user, err := r.authrepo.GetUser(ctx, &types.GetUserRequest{
+			UserId: comm.UserID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user for comment %s: %w", comm.ID, err)
+		}
+		comm.UserInfo = *user
+	}
+
+	return comments, nil
+}
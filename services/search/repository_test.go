@@ -0,0 +1,27 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/chatting-app/services/search"
+)
+
+func TestBuildPrefixQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "single term", input: "wafi", want: "wafi:*"},
+		{name: "multiple terms", input: "Go  Lang", want: "go:* & lang:*"},
+		{name: "strip tsquery operators", input: "foo & !bar | (baz):*", want: "foo:* & bar:* & baz:*"},
+		{name: "only symbols", input: "&& !!", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, search.BuildPrefixQuery(tt.input))
+		})
+	}
+}
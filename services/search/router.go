@@ -0,0 +1,9 @@
+package search
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.RouterGroup, h *SearchHandler) {
+	r.GET("", h.HandleSearch)
+}
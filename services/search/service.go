@@ -0,0 +1,48 @@
+package search
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+type SearchService struct {
+	repo *SearchRepository
+}
+
+func NewSearchService(repo *SearchRepository) *SearchService {
+	return &SearchService{
+		repo: repo,
+	}
+}
+
+func (s *SearchService) Search(ctx context.Context, req *types.SearchRequest) (*types.SearchResponse, error) {
+	tsQuery := BuildPrefixQuery(req.Query)
+	if tsQuery == "" {
+		return nil, fmt.Errorf("invalid search query")
+	}
+
+	resp := &types.SearchResponse{
+		Query: req.Query,
+	}
+
+	for _, t := range req.Types {
+		var err error
+		switch t {
+		case types.SearchTypeUsers:
+			resp.Users, err = s.repo.SearchUsers(ctx, req, tsQuery)
+		case types.SearchTypePosts:
+			resp.Posts, err = s.repo.SearchPosts(ctx, req, tsQuery)
+		case types.SearchTypeComments:
+			resp.Comments, err = s.repo.SearchComments(ctx, req, tsQuery)
+		default:
+			return nil, fmt.Errorf("invalid search type: %s", t)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ClientInfo adalah IP dan User-Agent dari request yang sedang diproses
+type ClientInfo struct {
+	IpAddress string
+	UserAgent string
+}
+
+type clientInfoKey struct{}
+
+// CaptureClientInfo menyimpan ClientInfo di context request, supaya service bisa
+// mencatat asal request tanpa menerima *gin.Context
+func CaptureClientInfo() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request = c.Request.WithContext(WithClientInfo(c.Request.Context(), ClientInfo{
+			IpAddress: c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}))
+		c.Next()
+	}
+}
+
+func WithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	info, _ := ctx.Value(clientInfoKey{}).(ClientInfo)
+	return info
+}
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/chatting-app/config/env"
+)
+
+const (
+	AccessTokenCookie  = "access_token"
+	RefreshTokenCookie = "refresh_token"
+	SessionCookie      = "auth_session"
+	CSRFCookie         = "csrf_token"
+	// OIDCStateCookie mengikat state OIDC ke browser yang memulai login
+	OIDCStateCookie = "oidc_state"
+)
+
+type CookieConfig struct {
+	// Domain kosong berarti cookie host-only
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+var cookieConfig = CookieConfig{SameSite: http.SameSiteLaxMode}
+
+// SetCookieConfig mengatur atribut cookie auth, dipanggil sekali saat startup
+func SetCookieConfig(config CookieConfig) {
+	cookieConfig = config
+}
+
+// LoadCookieConfigFromEnv membaca COOKIE_DOMAIN, COOKIE_SECURE (true/false) dan
+// COOKIE_SAMESITE (lax, strict, none). SameSite=None wajib Secure.
+func LoadCookieConfigFromEnv() (CookieConfig, error) {
+	config := CookieConfig{
+		Domain:   env.LoadEnv("COOKIE_DOMAIN"),
+		SameSite: http.SameSiteLaxMode,
+	}
+
+	if raw := env.LoadEnv("COOKIE_SECURE"); raw != "" {
+		secure, err := strconv.ParseBool(raw)
+		if err != nil {
+			return config, fmt.Errorf("invalid COOKIE_SECURE: %q", raw)
+		}
+		config.Secure = secure
+	}
+
+	switch raw := strings.ToLower(env.LoadEnv("COOKIE_SAMESITE")); raw {
+	case "", "lax":
+		config.SameSite = http.SameSiteLaxMode
+	case "strict":
+		config.SameSite = http.SameSiteStrictMode
+	case "none":
+		if !config.Secure {
+			return config, fmt.Errorf("COOKIE_SAMESITE=none requires COOKIE_SECURE=true")
+		}
+		config.SameSite = http.SameSiteNoneMode
+	default:
+		return config, fmt.Errorf("invalid COOKIE_SAMESITE: %q", raw)
+	}
+
+	return config, nil
+}
+
+func setCookie(c *gin.Context, name, value string, maxAge int, httpOnly bool) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		MaxAge:   maxAge,
+		Path:     "/",
+		Domain:   cookieConfig.Domain,
+		Secure:   cookieConfig.Secure,
+		HttpOnly: httpOnly,
+		SameSite: cookieConfig.SameSite,
+	})
+}
+
+// SetAuthCookies menyimpan token sesi di cookie untuk client browser, beserta token CSRF baru
+func SetAuthCookies(c *gin.Context, accessToken, refreshToken, sessionID string) error {
+	SetAccressTokenCookie(c, accessToken)
+	SetRefreshTokenCookie(c, refreshToken)
+	SetSessionCookie(c, sessionID)
+	_, err := SetCSRFCookie(c)
+	return err
+}
+
+func SetRefreshTokenCookie(c *gin.Context, token string) {
+	setCookie(c, RefreshTokenCookie, token, 168*3600, true)
+}
+
+func SetAccressTokenCookie(c *gin.Context, token string) {
+	setCookie(c, AccessTokenCookie, token, 24*3600, true)
+}
+
+func SetSessionCookie(c *gin.Context, sessionID string) {
+	setCookie(c, SessionCookie, sessionID, 168*3600, true)
+}
+
+// SetOIDCStateCookie menyimpan hash state OIDC. Callback dari provider adalah navigasi
+// lintas situs, jadi SameSite=Strict tidak bisa dipakai dan diturunkan ke Lax.
+func SetOIDCStateCookie(c *gin.Context, stateHash string, maxAge int) {
+	sameSite := cookieConfig.SameSite
+	if sameSite == http.SameSiteStrictMode {
+		sameSite = http.SameSiteLaxMode
+	}
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     OIDCStateCookie,
+		Value:    stateHash,
+		MaxAge:   maxAge,
+		Path:     "/",
+		Domain:   cookieConfig.Domain,
+		Secure:   cookieConfig.Secure,
+		HttpOnly: true,
+		SameSite: sameSite,
+	})
+}
+
+func ClearTokens(c *gin.Context) {
+	for _, name := range []string{AccessTokenCookie, RefreshTokenCookie, SessionCookie, CSRFCookie} {
+		setCookie(c, name, "", -1, name != CSRFCookie)
+	}
+
+	c.Header("Authorization", "")
+}
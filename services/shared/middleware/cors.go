@@ -11,7 +11,7 @@ func SetUpCors(r *gin.Engine) {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://192.168.100.9:3000"}, // Domain frontend
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", CSRFHeader},
 		AllowCredentials: true, // Izinkan cookies lintas domain
 		MaxAge:           12 * time.Hour,
 	}))
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/chatting-app/services/shared/utils"
+)
+
+const CSRFHeader = "X-CSRF-Token"
+
+// SetCSRFCookie membuat token CSRF baru. Cookie ini sengaja bisa dibaca JavaScript
+// supaya frontend bisa mengirimnya kembali lewat header X-CSRF-Token.
+func SetCSRFCookie(c *gin.Context) (string, error) {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", err
+	}
+	setCookie(c, CSRFCookie, token, 168*3600, false)
+	return token, nil
+}
+
+// CSRFProtection menerapkan double-submit cookie untuk request yang terautentikasi lewat
+// cookie. Request dengan header Authorization tidak memakai cookie ambient sehingga tidak
+// perlu dicek; request tanpa cookie auth juga dilewatkan. exemptPaths untuk endpoint yang
+// diotorisasi token di URL (misalnya unsubscribe digest dari form HTML), bukan oleh cookie.
+func CSRFProtection(exemptPaths ...string) gin.HandlerFunc {
+	exempt := make(map[string]bool, len(exemptPaths))
+	for _, path := range exemptPaths {
+		exempt[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" || !hasAuthCookie(c) || exempt[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		cookieToken, _ := c.Cookie(CSRFCookie)
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			// Sesi yang terbit sebelum CSRF diaktifkan mendapat token di request aman berikutnya
+			if cookieToken == "" {
+				if _, err := SetCSRFCookie(c); err != nil {
+					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+						"error": "Failed to issue CSRF token",
+					})
+					return
+				}
+			}
+			c.Next()
+			return
+		}
+
+		headerToken := c.GetHeader(CSRFHeader)
+		if cookieToken == "" || headerToken == "" ||
+			subtle.ConstantTimeCompare([]byte(cookieToken), []byte(headerToken)) != 1 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Invalid or missing CSRF token",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func hasAuthCookie(c *gin.Context) bool {
+	for _, name := range []string{AccessTokenCookie, RefreshTokenCookie} {
+		if value, err := c.Cookie(name); err == nil && value != "" {
+			return true
+		}
+	}
+	return false
+}
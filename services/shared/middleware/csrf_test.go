@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCSRFProtection(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(CSRFProtection("/exempt"))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/exempt", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serveAt := func(method, path string, cookies map[string]string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for name, value := range cookies {
+			req.AddCookie(&http.Cookie{Name: name, Value: value})
+		}
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+	serve := func(method string, cookies map[string]string, headers map[string]string) *httptest.ResponseRecorder {
+		return serveAt(method, "/", cookies, headers)
+	}
+
+	auth := map[string]string{AccessTokenCookie: "tok", CSRFCookie: "csrf-1"}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, nil, nil).Code, "no auth cookie")
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, auth, nil).Code, "missing header")
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, auth, map[string]string{CSRFHeader: "other"}).Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, auth, map[string]string{CSRFHeader: "csrf-1"}).Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, auth, map[string]string{"Authorization": "Bearer tok"}).Code, "bearer is exempt")
+	assert.Equal(t, http.StatusOK, serveAt(http.MethodPost, "/exempt", auth, nil).Code, "exempt path")
+
+	// sesi lama tanpa cookie CSRF mendapat token di GET
+	w := serve(http.MethodGet, map[string]string{AccessTokenCookie: "tok"}, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Set-Cookie"), CSRFCookie+"=")
+}
+
+func TestAccessTokenFromRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	extract := func(header string, cookie string) (string, string) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			c.Request.Header.Set("Authorization", header)
+		}
+		if cookie != "" {
+			c.Request.AddCookie(&http.Cookie{Name: AccessTokenCookie, Value: cookie})
+		}
+		return AccessTokenFromRequest(c)
+	}
+
+	token, method := extract("Bearer abc", "xyz")
+	assert.Equal(t, "abc", token)
+	assert.Equal(t, AuthMethodBearer, method)
+
+	token, method = extract("", "xyz")
+	assert.Equal(t, "xyz", token)
+	assert.Equal(t, AuthMethodCookie, method)
+
+	token, _ = extract("Basic Zm9vOmJhcg==", "xyz")
+	assert.Empty(t, token, "non-bearer Authorization must not fall back to cookie")
+}
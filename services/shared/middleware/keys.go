@@ -0,0 +1,229 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/wafi04/chatting-app/config/env"
+)
+
+// SigningKey adalah satu kunci JWT. Kunci tanpa private key hanya dipakai untuk
+// verifikasi, misalnya kunci lama yang sedang dirotasi keluar.
+type SigningKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+func NewHMACKey(id string, secret []byte) *SigningKey {
+	return &SigningKey{
+		ID:        id,
+		Method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+// NewRSAKey membuat kunci RS256; private boleh nil untuk kunci verify-only
+func NewRSAKey(id string, private *rsa.PrivateKey, public *rsa.PublicKey) *SigningKey {
+	key := &SigningKey{
+		ID:        id,
+		Method:    jwt.SigningMethodRS256,
+		verifyKey: public,
+	}
+	if private != nil {
+		key.signKey = private
+		key.verifyKey = &private.PublicKey
+	}
+	return key
+}
+
+// NewEd25519Key membuat kunci EdDSA; private boleh nil untuk kunci verify-only
+func NewEd25519Key(id string, private ed25519.PrivateKey, public ed25519.PublicKey) *SigningKey {
+	key := &SigningKey{
+		ID:        id,
+		Method:    jwt.SigningMethodEdDSA,
+		verifyKey: public,
+	}
+	if private != nil {
+		key.signKey = private
+		key.verifyKey = private.Public()
+	}
+	return key
+}
+
+// KeySet berisi satu kunci aktif untuk signing dan semua kunci yang masih diterima saat verifikasi
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+func NewKeySet(activeID string, keys ...*SigningKey) (*KeySet, error) {
+	ks := &KeySet{keys: make(map[string]*SigningKey)}
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, errors.New("jwt key id is required")
+		}
+		if _, exists := ks.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate jwt key id: %s", key.ID)
+		}
+		ks.keys[key.ID] = key
+	}
+
+	active, ok := ks.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("active jwt key %q not found", activeID)
+	}
+	if active.signKey == nil {
+		return nil, fmt.Errorf("active jwt key %q has no private key", activeID)
+	}
+	ks.active = active
+
+	return ks, nil
+}
+
+func (ks *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.active.Method, claims)
+	token.Header["kid"] = ks.active.ID
+	return token.SignedString(ks.active.signKey)
+}
+
+func (ks *KeySet) Parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := ks.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown key id: %q", kid)
+		}
+		// Algoritma harus sesuai kunci, mencegah alg confusion (mis. RS256 public key dipakai sebagai secret HS256)
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.verifyKey, nil
+	})
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS mengembalikan public key asimetris. Kunci HS256 tidak pernah dipublikasikan.
+func (ks *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	for _, key := range ks.keys {
+		switch pub := key.verifyKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: key.ID,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	return set
+}
+
+// LoadKeySetFromEnv membaca kunci dari env:
+//
+//	JWT_KEYS="kid:ALG:/path/key.pem,..."  ALG = HS256 | RS256 | EdDSA
+//	JWT_ACTIVE_KID="kid"                  kunci untuk signing (default: kunci pertama)
+//
+// File HS256 berisi secret, file RS256/EdDSA berisi PEM private key atau public key
+// (verify-only). Jika JWT_KEYS kosong, JWT_SECRET dipakai sebagai kunci HS256 "default".
+func LoadKeySetFromEnv() (*KeySet, error) {
+	spec := env.LoadEnv("JWT_KEYS")
+	if spec == "" {
+		secret := env.LoadEnv("JWT_SECRET")
+		if secret == "" {
+			return nil, errors.New("no jwt keys configured: set JWT_KEYS or JWT_SECRET")
+		}
+		return NewKeySet("default", NewHMACKey("default", []byte(secret)))
+	}
+
+	var keys []*SigningKey
+	for _, entry := range strings.Split(spec, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), ":", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("invalid JWT_KEYS entry %q, expected kid:alg:path", entry)
+		}
+		key, err := loadKeyFile(parts[0], parts[1], parts[2])
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	activeID := env.LoadEnv("JWT_ACTIVE_KID")
+	if activeID == "" {
+		activeID = keys[0].ID
+	}
+
+	return NewKeySet(activeID, keys...)
+}
+
+func loadKeyFile(id, alg, path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jwt key %s: %w", id, err)
+	}
+
+	switch alg {
+	case jwt.SigningMethodHS256.Alg():
+		secret := strings.TrimSpace(string(data))
+		if len(secret) < 32 {
+			return nil, fmt.Errorf("jwt key %s: HS256 secret must be at least 32 bytes", id)
+		}
+		return NewHMACKey(id, []byte(secret)), nil
+	case jwt.SigningMethodRS256.Alg():
+		if private, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+			return NewRSAKey(id, private, nil), nil
+		}
+		public, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: invalid RSA key: %w", id, err)
+		}
+		return NewRSAKey(id, nil, public), nil
+	case jwt.SigningMethodEdDSA.Alg():
+		if private, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+			return NewEd25519Key(id, private.(ed25519.PrivateKey), nil), nil
+		}
+		public, err := jwt.ParseEdPublicKeyFromPEM(data)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %s: invalid Ed25519 key: %w", id, err)
+		}
+		return NewEd25519Key(id, nil, public.(ed25519.PublicKey)), nil
+	default:
+		return nil, fmt.Errorf("jwt key %s: unsupported algorithm %s", id, alg)
+	}
+}
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeySetRotation(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	oldSet, err := NewKeySet("old", NewRSAKey("old", rsaKey, nil))
+	require.NoError(t, err)
+	oldToken, err := oldSet.Sign(&JWTClaims{UserID: "u1"})
+	require.NoError(t, err)
+
+	// Kunci baru aktif, kunci lama tinggal public key untuk verifikasi
+	newSet, err := NewKeySet("new",
+		NewEd25519Key("new", edKey, nil),
+		NewRSAKey("old", nil, &rsaKey.PublicKey),
+	)
+	require.NoError(t, err)
+
+	newToken, err := newSet.Sign(&JWTClaims{UserID: "u2"})
+	require.NoError(t, err)
+
+	for token, user := range map[string]string{oldToken: "u1", newToken: "u2"} {
+		claims := &JWTClaims{}
+		_, err := newSet.Parse(token, claims)
+		require.NoError(t, err)
+		assert.Equal(t, user, claims.UserID)
+	}
+
+	jwks := newSet.JWKS()
+	assert.Len(t, jwks.Keys, 2)
+}
+
+func TestKeySetRejectsUnknownKidAndAlgMismatch(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	set, err := NewKeySet("rsa", NewRSAKey("rsa", rsaKey, nil))
+	require.NoError(t, err)
+
+	// HS256 dengan kid RSA tidak boleh diterima
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &JWTClaims{UserID: "u1"})
+	forged.Header["kid"] = "rsa"
+	forgedString, err := forged.SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = set.Parse(forgedString, &JWTClaims{})
+	assert.Error(t, err)
+
+	other, err := NewKeySet("hs", NewHMACKey("hs", []byte("secret")))
+	require.NoError(t, err)
+	token, err := other.Sign(&JWTClaims{UserID: "u1"})
+	require.NoError(t, err)
+	_, err = set.Parse(token, &JWTClaims{})
+	assert.Error(t, err)
+
+	_, err = NewKeySet("rsa", NewRSAKey("rsa", nil, &rsaKey.PublicKey))
+	assert.Error(t, err, "verify-only key cannot be active")
+}
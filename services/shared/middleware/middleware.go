@@ -5,14 +5,23 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"github.com/wafi04/chatting-app/services/shared/types"
 )
 
-var jwtSecretKey = []byte("jsjxakabxjaigisyqyg189")
+var keySet *KeySet
+
+// SetKeySet mengatur kunci yang dipakai GenerateToken dan ValidateToken, dipanggil sekali saat startup
+func SetKeySet(ks *KeySet) {
+	keySet = ks
+}
+
+var errNoKeySet = errors.New("jwt signing keys are not configured")
 
 type JWTClaims struct {
 	UserID          string `json:"user_id"`
@@ -21,16 +30,16 @@ type JWTClaims struct {
 	Role            string `json:"role"`
 	IsActive        bool   `json:"is_active"`
 	IsEmailVerified bool   `json:"is_email_verified"`
+	SessionID       string `json:"sid,omitempty"`
 	jwt.StandardClaims
 }
 
 func ValidateToken(tokenString string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return jwtSecretKey, nil
-	})
+	if keySet == nil {
+		return nil, errNoKeySet
+	}
+
+	token, err := keySet.Parse(tokenString, &JWTClaims{})
 
 	if err != nil {
 		return nil, err
@@ -43,21 +52,29 @@ func ValidateToken(tokenString string) (*JWTClaims, error) {
 
 	return claims, nil
 }
-func GenerateToken(user *types.UserInfo, day int64) (string, error) {
+
+// GenerateToken membuat access token untuk sesi sessionID; jti unik per token
+func GenerateToken(user *types.UserInfo, sessionID string, hours int64) (string, error) {
 	claims := JWTClaims{
 		UserID:          user.UserId,
 		Email:           user.Email,
 		Name:            user.Name,
+		Role:            user.Role,
 		IsEmailVerified: user.IsEmailVerified,
+		SessionID:       sessionID,
 		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(time.Duration(day) * time.Hour).Unix(),
+			Id:        uuid.New().String(),
+			ExpiresAt: time.Now().Add(time.Duration(hours) * time.Hour).Unix(),
 			IssuedAt:  time.Now().Unix(),
 			Issuer:    "wafiuddin",
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	signedToken, err := token.SignedString(jwtSecretKey)
+	if keySet == nil {
+		return "", errNoKeySet
+	}
+
+	signedToken, err := keySet.Sign(claims)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -80,114 +97,98 @@ func GetUserFromGinContext(c *gin.Context) (*types.UserInfo, error) {
 	}
 	return userInfo, nil
 }
-func AuthMiddleware() gin.HandlerFunc {
+
+// AuthMiddleware memvalidasi access token dari header `Authorization: Bearer` (API client,
+// aplikasi mobile) atau cookie access_token (browser). Jika sessions tidak nil, sesi pada
+// claim `sid` juga harus masih aktif sehingga logout/revoke langsung berlaku untuk token yang sudah terbit.
+// Personal access token ditolak; pakai AuthMiddlewareWithScopes untuk route yang boleh diakses PAT.
+func AuthMiddleware(sessions *SessionCache) gin.HandlerFunc {
+	return authenticate(sessions, nil)
+}
+
+func authenticate(sessions *SessionCache, policy *ScopePolicy) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Step 1: Cek cookie untuk access_token
-		accessToken, err := c.Cookie("access_token")
-		if err == nil && accessToken != "" {
-			// Validasi token dari cookie
-			if claims, err := ValidateToken(accessToken); err == nil {
-				user := &types.UserInfo{
-					UserId:          claims.UserID,
-					Email:           claims.Email,
-					Name:            claims.Name,
-					IsEmailVerified: claims.IsEmailVerified,
-				}
-				fmt.Printf("token : %s", err)
-				// Set user di context
-				c.Set(string(UserContextKey), user)
-				c.Next()
-				return
-			}
+		accessToken, method := AccessTokenFromRequest(c)
+		if accessToken == "" {
+			// Client harus memanggil /auth/refresh untuk merotasi refresh token.
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "No valid tokens found",
+			})
+			return
 		}
 
-		// Step 2: Jika tidak ada access_token valid, cek refresh_token dari cookie
-		refreshToken, err := c.Cookie("refresh_token")
-		if err == nil && refreshToken != "" {
-			// Validasi refresh token
-			if claims, err := ValidateToken(refreshToken); err == nil {
-				user := &types.UserInfo{
-					UserId:          claims.UserID,
-					Email:           claims.Email,
-					Name:            claims.Name,
-					IsEmailVerified: claims.IsEmailVerified,
-				}
-				// Generate new access token
-				newAccessToken, err := GenerateToken(user, 24) // Token baru berlaku 24 jam
-				if err != nil {
-					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-						"error": "Failed to generate new access token",
-					})
-					return
-				}
-				// Set new access token sebagai cookie
-				SetAccressTokenCookie(c, newAccessToken)
-				// Set user di context
-				c.Set(string(UserContextKey), user)
-				c.Next()
+		if method == AuthMethodBearer && strings.HasPrefix(accessToken, PATPrefix) {
+			authenticatePAT(c, accessToken, policy)
+			return
+		}
+
+		claims, err := ValidateToken(accessToken)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Invalid or expired token",
+			})
+			return
+		}
+
+		if sessions != nil {
+			active, err := sessions.IsActive(c.Request.Context(), claims.SessionID)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to check session",
+				})
+				return
+			}
+			if !active {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+					"error": "Session has been revoked",
+				})
 				return
 			}
 		}
 
-		// Step 3: Jika tidak ada token valid, return unauthorized
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-			"error": "No valid tokens found",
-		})
+		user := &types.UserInfo{
+			UserId:          claims.UserID,
+			Email:           claims.Email,
+			Name:            claims.Name,
+			IsEmailVerified: claims.IsEmailVerified,
+			Role:            claims.Role,
+		}
+		// Set user di context
+		c.Set(string(UserContextKey), user)
+		c.Set(string(SessionContextKey), claims.SessionID)
+		c.Set(string(accessTokenContextKey), accessToken)
+		c.Set(string(AuthMethodContextKey), method)
+		c.Next()
 	}
 }
-func SetRefreshTokenCookie(c *gin.Context, token string) {
-	log.Printf("addedd called")
-
-	c.SetCookie(
-		"refresh_token",
-		token,
-		int(168*3600),
-		"/",
-		"192.168.100.9",
-		false,
-		true,
-	)
-}
 
-func SetAccressTokenCookie(c *gin.Context, token string) {
-	log.Printf("addedd called")
-
-	c.SetCookie(
-		"access_token",
-		token,
-		int(24*3600),
-		"/",
-		"192.168.100.9",
-		false,
-		true,
-	)
-}
+const (
+	AuthMethodBearer = "bearer"
+	AuthMethodCookie = "cookie"
+
+	AuthMethodContextKey  contextKey = "auth_method"
+	accessTokenContextKey contextKey = "access_token"
+)
 
-func SetSessionCookie(c *gin.Context, sessionID string) {
-	log.Printf("addedd called")
-	c.SetCookie(
-		"auth_session",
-		sessionID,
-		int(168*3600),
-		"/",
-		"192.168.100.9",
-		false,
-		true,
-	)
+// AccessTokenFromRequest mengambil access token dari header Bearer, atau dari cookie jika header tidak ada
+func AccessTokenFromRequest(c *gin.Context) (token string, method string) {
+	if header := c.GetHeader("Authorization"); header != "" {
+		scheme, value, found := strings.Cut(header, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(value), AuthMethodBearer
+		}
+		return "", ""
+	}
+
+	if cookie, err := c.Cookie(AccessTokenCookie); err == nil && cookie != "" {
+		return cookie, AuthMethodCookie
+	}
+	return "", ""
 }
 
-func ClearTokens(c *gin.Context) {
-	c.SetCookie(
-		"refresh_token",
-		"",
-		-1,
-		"/",
-		"",
-		true,
-		true,
-	)
-
-	c.Header("Authorization", "")
+// GetAccessTokenFromGinContext mengembalikan token yang dipakai untuk request ini
+func GetAccessTokenFromGinContext(c *gin.Context) string {
+	return c.GetString(string(accessTokenContextKey))
 }
 
 func ResponseTime(r *gin.Engine) {
@@ -0,0 +1,112 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+const (
+	AuthMethodPAT = "pat"
+	// PATPrefix membedakan personal access token dari JWT di header Bearer
+	PATPrefix = "pat_"
+
+	PATContextKey contextKey = "pat"
+)
+
+// PATPrincipal adalah hasil validasi personal access token
+type PATPrincipal struct {
+	TokenID string
+	User    *types.UserInfo
+	Scopes  []string
+}
+
+// PATLookup memvalidasi token mentah; nil tanpa error berarti token tidak valid,
+// sudah kedaluwarsa atau sudah dicabut
+type PATLookup func(ctx context.Context, token string) (*PATPrincipal, error)
+
+var patLookup PATLookup
+
+// SetPATLookup mengaktifkan autentikasi personal access token, dipanggil sekali saat startup
+func SetPATLookup(lookup PATLookup) {
+	patLookup = lookup
+}
+
+// ScopePolicy menentukan scope PAT yang dibutuhkan sebuah route group: Read untuk
+// GET/HEAD/OPTIONS, Write untuk method lain. Scope kosong berarti PAT ditolak.
+type ScopePolicy struct {
+	Read  string
+	Write string
+}
+
+func (p ScopePolicy) requiredScope(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return p.Read
+	}
+	return p.Write
+}
+
+// AuthMiddlewareWithScopes sama dengan AuthMiddleware tetapi juga menerima personal access
+// token yang memiliki scope sesuai policy. Sesi login biasa tidak dibatasi scope.
+func AuthMiddlewareWithScopes(sessions *SessionCache, policy ScopePolicy) gin.HandlerFunc {
+	return authenticate(sessions, &policy)
+}
+
+func HasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPATFromGinContext mengembalikan principal PAT, nil jika request memakai sesi login
+func GetPATFromGinContext(c *gin.Context) *PATPrincipal {
+	if pat, ok := c.Get(string(PATContextKey)); ok {
+		if principal, ok := pat.(*PATPrincipal); ok {
+			return principal
+		}
+	}
+	return nil
+}
+
+func authenticatePAT(c *gin.Context, token string, policy *ScopePolicy) {
+	if policy == nil || patLookup == nil {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "Personal access tokens are not allowed for this endpoint",
+		})
+		return
+	}
+
+	principal, err := patLookup(c.Request.Context(), token)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check access token",
+		})
+		return
+	}
+	if principal == nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid or expired access token",
+		})
+		return
+	}
+
+	scope := policy.requiredScope(c.Request.Method)
+	if scope == "" || !HasScope(principal.Scopes, scope) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "Access token is missing the required scope",
+			"scope": scope,
+		})
+		return
+	}
+
+	c.Set(string(UserContextKey), principal.User)
+	c.Set(string(PATContextKey), principal)
+	c.Set(string(AuthMethodContextKey), AuthMethodPAT)
+	c.Next()
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+func TestPersonalAccessTokenScopes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	SetPATLookup(func(ctx context.Context, token string) (*PATPrincipal, error) {
+		if token != "pat_valid" {
+			return nil, nil
+		}
+		return &PATPrincipal{
+			TokenID: "t1",
+			User:    &types.UserInfo{UserId: "u1"},
+			Scopes:  []string{types.ScopeRead, types.ScopePostsWrite},
+		}, nil
+	})
+	defer SetPATLookup(nil)
+
+	r := gin.New()
+	handler := func(c *gin.Context) {
+		user, _ := GetUserFromGinContext(c)
+		c.String(http.StatusOK, user.UserId)
+	}
+	posts := r.Group("/post", AuthMiddlewareWithScopes(nil, ScopePolicy{Read: types.ScopeRead, Write: types.ScopePostsWrite}))
+	posts.GET("", handler)
+	posts.POST("", handler)
+	comments := r.Group("/comment", AuthMiddlewareWithScopes(nil, ScopePolicy{Read: types.ScopeRead, Write: types.ScopeCommentsWrite}))
+	comments.POST("", handler)
+	r.GET("/sessions", AuthMiddleware(nil), handler)
+
+	serve := func(method, path, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "/post", "pat_valid"))
+	assert.Equal(t, http.StatusOK, serve(http.MethodPost, "/post", "pat_valid"))
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, "/comment", "pat_valid"), "missing comments:write")
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodGet, "/sessions", "pat_valid"), "session-only route")
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/post", "pat_revoked"))
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RoleLookup mengambil role terkini user dari database
+type RoleLookup func(ctx context.Context, userID string) (string, error)
+
+// RequireRole hanya meneruskan request dari user dengan salah satu roles.
+// Role di claim bisa basi sampai access token diperbarui, jadi role selalu dicek
+// lewat lookup agar penurunan role langsung berlaku.
+func RequireRole(lookup RoleLookup, roles ...string) gin.HandlerFunc {
+	allowed := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		allowed[role] = true
+	}
+
+	return func(c *gin.Context) {
+		user, err := GetUserFromGinContext(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Unauthorized",
+			})
+			return
+		}
+
+		role, err := lookup(c.Request.Context(), user.UserId)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to check role",
+			})
+			return
+		}
+		if !allowed[role] {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "Insufficient role",
+			})
+			return
+		}
+
+		user.Role = role
+		c.Next()
+	}
+}
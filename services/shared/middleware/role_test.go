@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/chatting-app/services/shared/types"
+)
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	roles := map[string]string{
+		"admin-1": types.RoleAdmin,
+		"mod-1":   types.RoleModerator,
+		"user-1":  types.RoleUser,
+	}
+	lookup := func(ctx context.Context, userID string) (string, error) {
+		role, ok := roles[userID]
+		if !ok {
+			return "", errors.New("not found")
+		}
+		return role, nil
+	}
+
+	serve := func(userID string, claimRole string) int {
+		r := gin.New()
+		r.Use(func(c *gin.Context) {
+			if userID != "" {
+				c.Set(string(UserContextKey), &types.UserInfo{UserId: userID, Role: claimRole})
+			}
+		})
+		r.GET("/", RequireRole(lookup, types.RoleModerator, types.RoleAdmin), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("admin-1", types.RoleAdmin))
+	assert.Equal(t, http.StatusOK, serve("mod-1", types.RoleModerator))
+	// claim basi tidak boleh memberi akses
+	assert.Equal(t, http.StatusForbidden, serve("user-1", types.RoleAdmin))
+	assert.Equal(t, http.StatusUnauthorized, serve("", ""))
+	assert.Equal(t, http.StatusInternalServerError, serve("ghost", ""))
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const SessionContextKey contextKey = "session_id"
+
+// SessionChecker mengecek ke database apakah sesi masih aktif
+type SessionChecker func(ctx context.Context, sessionID string) (bool, error)
+
+type sessionEntry struct {
+	active    bool
+	expiresAt time.Time
+}
+
+// SessionCache menyimpan hasil SessionChecker selama ttl supaya tidak query database
+// di setiap request. Revoke di instance ini langsung berlaku lewat Invalidate,
+// instance lain paling lambat setelah ttl.
+type SessionCache struct {
+	mu      sync.Mutex
+	check   SessionChecker
+	ttl     time.Duration
+	maxSize int
+	entries map[string]sessionEntry
+	now     func() time.Time
+}
+
+func NewSessionCache(check SessionChecker, ttl time.Duration, maxSize int) *SessionCache {
+	return &SessionCache{
+		check:   check,
+		ttl:     ttl,
+		maxSize: maxSize,
+		entries: make(map[string]sessionEntry),
+		now:     time.Now,
+	}
+}
+
+func (sc *SessionCache) IsActive(ctx context.Context, sessionID string) (bool, error) {
+	if sessionID == "" {
+		return false, nil
+	}
+
+	sc.mu.Lock()
+	entry, ok := sc.entries[sessionID]
+	sc.mu.Unlock()
+	if ok && sc.now().Before(entry.expiresAt) {
+		return entry.active, nil
+	}
+
+	active, err := sc.check(ctx, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if len(sc.entries) >= sc.maxSize {
+		sc.evictLocked()
+	}
+	sc.entries[sessionID] = sessionEntry{
+		active:    active,
+		expiresAt: sc.now().Add(sc.ttl),
+	}
+
+	return active, nil
+}
+
+// Invalidate menandai sesi sebagai tidak aktif, dipanggil setelah logout/revoke
+func (sc *SessionCache) Invalidate(sessionIDs ...string) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for _, id := range sessionIDs {
+		sc.entries[id] = sessionEntry{
+			active:    false,
+			expiresAt: sc.now().Add(sc.ttl),
+		}
+	}
+}
+
+func (sc *SessionCache) evictLocked() {
+	now := sc.now()
+	for id, entry := range sc.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(sc.entries, id)
+		}
+	}
+	// Masih penuh: kosongkan saja, cache ini hanya optimisasi
+	if len(sc.entries) >= sc.maxSize {
+		sc.entries = make(map[string]sessionEntry)
+	}
+}
+
+func GetSessionIDFromGinContext(c *gin.Context) string {
+	return c.GetString(string(SessionContextKey))
+}
@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionCache(t *testing.T) {
+	calls := 0
+	active := true
+	cache := NewSessionCache(func(ctx context.Context, sessionID string) (bool, error) {
+		calls++
+		return active, nil
+	}, time.Minute, 10)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	ok, err := cache.IsActive(context.Background(), "s1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// Masih dalam ttl, tidak query ulang
+	active = false
+	ok, _ = cache.IsActive(context.Background(), "s1")
+	assert.True(t, ok)
+	assert.Equal(t, 1, calls)
+
+	// Setelah ttl habis hasil terbaru dipakai
+	now = now.Add(2 * time.Minute)
+	ok, _ = cache.IsActive(context.Background(), "s1")
+	assert.False(t, ok)
+	assert.Equal(t, 2, calls)
+
+	// Invalidate berlaku langsung tanpa query
+	active = true
+	cache.Invalidate("s2")
+	ok, _ = cache.IsActive(context.Background(), "s2")
+	assert.False(t, ok)
+	assert.Equal(t, 2, calls)
+
+	ok, _ = cache.IsActive(context.Background(), "")
+	assert.False(t, ok)
+}
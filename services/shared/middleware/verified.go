@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailVerificationChecker mengecek status verifikasi langsung ke database
+type EmailVerificationChecker func(ctx context.Context, userID string) (bool, error)
+
+// RequireVerifiedEmail menolak request dari user yang emailnya belum diverifikasi.
+// Claim di token bisa basi setelah user verifikasi, jadi claim false dicek ulang lewat checker.
+func RequireVerifiedEmail(check EmailVerificationChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := GetUserFromGinContext(c)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Unauthorized",
+			})
+			return
+		}
+
+		if !user.IsEmailVerified {
+			verified, err := check(c.Request.Context(), user.UserId)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to check email verification",
+				})
+				return
+			}
+			if !verified {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+					"error": "Email not verified",
+				})
+				return
+			}
+			user.IsEmailVerified = true
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,80 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/wafi04/chatting-app/services/shared/pkg/logger"
+)
+
+// LogMailer tidak mengirim email, melainkan menuliskannya ke file (satu JSON per baris)
+// atau ke logger jika path kosong. Dipakai untuk development lokal dan test.
+type LogMailer struct {
+	mu   sync.Mutex
+	path string
+	log  *logger.Logger
+}
+
+type loggedMessage struct {
+	SentAt time.Time `json:"sent_at"`
+	*Message
+}
+
+func NewLogMailer(path string) *LogMailer {
+	return &LogMailer{
+		path: path,
+		log:  logger.NewLogger(),
+	}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	if m.path == "" {
+		m.log.Log(logger.InfoLevel, "Email to=%s subject=%q\n%s", msg.To, msg.Subject, msg.TextBody)
+		return nil
+	}
+
+	data, err := json.Marshal(loggedMessage{SentAt: time.Now(), Message: msg})
+	if err != nil {
+		return fmt.Errorf("failed to encode email: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open mail log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write mail log: %w", err)
+	}
+
+	return nil
+}
+
+// ReadLogFile membaca semua email yang ditulis LogMailer ke path, berguna untuk test
+func ReadLogFile(path string) ([]*Message, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []*Message
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for dec.More() {
+		var m loggedMessage
+		if err := dec.Decode(&m); err != nil {
+			return nil, fmt.Errorf("failed to decode mail log: %w", err)
+		}
+		messages = append(messages, m.Message)
+	}
+
+	return messages, nil
+}
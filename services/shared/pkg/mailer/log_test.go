@@ -0,0 +1,26 @@
+package mailer_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/chatting-app/services/shared/pkg/mailer"
+)
+
+func TestLogMailerWritesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.log")
+	m := mailer.NewLogMailer(path)
+
+	require.NoError(t, m.Send(context.Background(), &mailer.Message{To: "a@example.com", Subject: "first", TextBody: "code 123456"}))
+	require.NoError(t, m.Send(context.Background(), &mailer.Message{To: "b@example.com", Subject: "second"}))
+
+	messages, err := mailer.ReadLogFile(path)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "a@example.com", messages[0].To)
+	assert.Equal(t, "code 123456", messages[0].TextBody)
+	assert.Equal(t, "second", messages[1].Subject)
+}
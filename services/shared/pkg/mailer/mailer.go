@@ -0,0 +1,51 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/wafi04/chatting-app/config/env"
+)
+
+// Message adalah email yang akan dikirim. HTMLBody boleh kosong.
+type Message struct {
+	To       string
+	Subject  string
+	TextBody string
+	HTMLBody string
+	// Headers tambahan, misal List-Unsubscribe
+	Headers map[string]string `json:",omitempty"`
+}
+
+// Mailer mengirim email. Implementasi: SMTPMailer untuk production dan
+// LogMailer untuk development lokal dan test.
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// NewFromEnv memilih implementasi berdasarkan env MAILER ("smtp" atau "log").
+// MAILER wajib diisi supaya deploy yang lupa konfigurasi SMTP gagal saat start,
+// bukan diam-diam menulis email verifikasi dan reset password ke file log.
+func NewFromEnv() (Mailer, error) {
+	switch driver := env.LoadEnv("MAILER"); driver {
+	case "":
+		return nil, fmt.Errorf("MAILER is not set; use MAILER=smtp, or MAILER=log for local development")
+	case "log":
+		return NewLogMailer(env.LoadEnv("MAIL_LOG_FILE")), nil
+	case "smtp":
+		port, err := strconv.Atoi(env.LoadEnv("SMTP_PORT"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid SMTP_PORT: %w", err)
+		}
+		return NewSMTPMailer(SMTPConfig{
+			Host:     env.LoadEnv("SMTP_HOST"),
+			Port:     port,
+			Username: env.LoadEnv("SMTP_USERNAME"),
+			Password: env.LoadEnv("SMTP_PASSWORD"),
+			From:     env.LoadEnv("SMTP_FROM"),
+		})
+	default:
+		return nil, fmt.Errorf("unknown mailer: %s", driver)
+	}
+}
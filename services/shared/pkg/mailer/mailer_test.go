@@ -0,0 +1,21 @@
+package mailer_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/chatting-app/services/shared/pkg/mailer"
+)
+
+func TestNewFromEnvRequiresDriver(t *testing.T) {
+	t.Setenv("MAILER", "")
+	_, err := mailer.NewFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("MAILER", "log")
+	t.Setenv("MAIL_LOG_FILE", "")
+	m, err := mailer.NewFromEnv()
+	require.NoError(t, err)
+	assert.IsType(t, &mailer.LogMailer{}, m)
+}
@@ -0,0 +1,30 @@
+package types
+
+type AccountPasswordRequest struct {
+	Password string `json:"password"`
+}
+
+type DeactivateAccountResponse struct {
+	Success bool `json:"success"`
+	// Unix time penghapusan permanen, 0 jika hanya dinonaktifkan
+	DeletionScheduledAt int64    `json:"deletion_scheduled_at,omitempty"`
+	RevokedSessions     []string `json:"revoked_sessions"`
+}
+
+const (
+	DataExportPending    = "pending"
+	DataExportProcessing = "processing"
+	DataExportCompleted  = "completed"
+	DataExportFailed     = "failed"
+)
+
+type DataExport struct {
+	Id          string `json:"id"`
+	UserId      string `json:"user_id"`
+	Status      string `json:"status"`
+	FilePath    string `json:"-"`
+	Error       string `json:"error,omitempty"`
+	CreatedAt   int64  `json:"created_at"`
+	CompletedAt int64  `json:"completed_at,omitempty"`
+	ExpiresAt   int64  `json:"expires_at,omitempty"`
+}
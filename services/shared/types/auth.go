@@ -50,6 +50,9 @@ type ResetPasswordResponse struct {
 	Success   bool   `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	Message   string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 	UpdatedAt int64  `protobuf:"varint,3,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	UserId    string `json:"-"`
+	// Sesi yang dicabut oleh reset, untuk dibuang dari cache sesi
+	RevokedSessions []string `json:"-"`
 }
 
 type UserInfo struct {
@@ -61,6 +64,7 @@ type UserInfo struct {
 	UpdatedAt       int64  `protobuf:"varint,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
 	LastLoginAt     int64  `protobuf:"varint,7,opt,name=last_login_at,json=lastLoginAt,proto3" json:"last_login_at,omitempty"`
 	Picture         string `protobuf:"bytes,8,opt,name=picture,proto3" json:"picture,omitempty"`
+	Role            string `protobuf:"bytes,9,opt,name=role,proto3" json:"role,omitempty"`
 }
 
 type CreateUserRequest struct {
@@ -80,6 +84,8 @@ type CreateUserResponse struct {
 	CreatedAt   int64    `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	Picture     string   `protobuf:"bytes,6,opt,name=picture,proto3" json:"picture,omitempty"`
 	SessionInfo *Session `protobuf:"bytes,7,opt,name=session_info,json=sessionInfo,proto3,oneof" json:"session_info,omitempty"`
+	// Token verifikasi email; kode 6 digitnya dikirim lewat email
+	VerificationToken string `json:"verification_token,omitempty"`
 }
 
 type UpdateUserRequest struct {
@@ -121,6 +127,8 @@ type VerificationToken struct {
 }
 
 type LoginRequest struct {
+	// Identifier berisi email atau username
+	Identifier string `json:"identifier,omitempty"`
 	Name       string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	Password   string `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
 	DeviceInfo string `protobuf:"bytes,3,opt,name=device_info,json=deviceInfo,proto3" json:"device_info,omitempty"`
@@ -135,6 +143,10 @@ type LoginResponse struct {
 	SessionInfo  *SessionInfo `protobuf:"bytes,5,opt,name=session_info,json=sessionInfo,proto3" json:"session_info,omitempty"`
 	Session      string       `protobuf:"bytes,6,opt,name=session,proto3" json:"session,omitempty"`
 	ExpiresAt    int64        `protobuf:"varint,7,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	// Diisi jika akun memakai 2FA: client harus memanggil /auth/2fa/login dengan challenge token
+	TwoFactorRequired  bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken     string `json:"challenge_token,omitempty"`
+	ChallengeExpiresAt int64  `json:"challenge_expires_at,omitempty"`
 }
 
 type SessionInfo struct {
@@ -143,6 +155,11 @@ type SessionInfo struct {
 	IpAddress      string `protobuf:"bytes,3,opt,name=ip_address,json=ipAddress,proto3" json:"ip_address,omitempty"`
 	CreatedAt      int64  `protobuf:"varint,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
 	LastActivityAt int64  `protobuf:"varint,5,opt,name=last_activity_at,json=lastActivityAt,proto3" json:"last_activity_at,omitempty"`
+	Current        bool   `json:"current,omitempty"`
+	// Hasil parsing DeviceInfo (User-Agent)
+	Device  string `json:"device,omitempty"`
+	Browser string `json:"browser,omitempty"`
+	OS      string `json:"os,omitempty"`
 }
 
 type LogoutRequest struct {
@@ -175,6 +192,7 @@ type RefreshTokenResponse struct {
 	AccessToken  string `protobuf:"bytes,1,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`
 	RefreshToken string `protobuf:"bytes,2,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
 	ExpiresAt    int64  `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	SessionId    string `json:"session_id,omitempty"`
 }
 
 type UpdateUserResponse struct {
@@ -224,6 +242,11 @@ type RevokeSessionResponse struct {
 	Success bool `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 }
 
+type RevokeOtherSessionsResponse struct {
+	Success bool     `json:"success"`
+	Revoked []string `json:"revoked"`
+}
+
 type ListSessionsRequest struct {
 	UserId string `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 }
@@ -16,21 +16,25 @@ type CreateComment struct {
 }
 
 type Comment struct {
-	ID        string         `db:"id" json:"Id"`
-	PostID    string         `db:"post_id" json:"postId"`
-	UserID    string         `db:"user_id" json:"userId"`
-	Content   string         `db:"content" json:"content"`
-	Depth     int64          `db:"depth" json:"depth"`
-	Path      pq.StringArray `json:"-"`
-	UserInfo  UserInfo       `json:"user_info"`
-	CreatedAT time.Time      `db:"created_at" json:"createdAt"`
-	Replies   []*Comment     `json:"replies"`
-	ParentID  *string        `db:"parent_id" json:"parentId"`
+	ID         string         `db:"id" json:"Id"`
+	PostID     string         `db:"post_id" json:"postId"`
+	UserID     string         `db:"user_id" json:"userId"`
+	Content    string         `db:"content" json:"content"`
+	Depth      int64          `db:"depth" json:"depth"`
+	LikeCount  int64          `db:"like_count" json:"likeCount"`
+	ReplyCount int64          `db:"reply_count" json:"replyCount"`
+	Path       pq.StringArray `json:"-"`
+	UserInfo   UserInfo       `json:"user_info"`
+	CreatedAT  time.Time      `db:"created_at" json:"createdAt"`
+	Replies    []*Comment     `json:"replies"`
+	ParentID   *string        `db:"parent_id" json:"parentId"`
 }
 
 type DeleteComment struct {
 	CommentID      string `json:"commentID"`
 	DeleteChildren bool   `json:"delete"`
+	// OwnerID diisi untuk hapus oleh user biasa; kosong hanya untuk route moderasi
+	OwnerID string `json:"-"`
 }
 
 type DeleteCommentReponse struct {
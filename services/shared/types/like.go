@@ -28,3 +28,23 @@ type LikePost struct {
 	PostID    *string            `bson:"post_id,omitempty"  json:"postId"`
 	CreatedAt time.Time          `bson:"created_at"  json:"createdAt"`
 }
+
+type Liker struct {
+	UserInfo    UserInfo  `json:"userInfo"`
+	IsFollowing bool      `json:"isFollowing"`
+	LikedAt     time.Time `json:"likedAt"`
+}
+
+type ListLikersRequest struct {
+	ViewerID string `json:"viewerId"`
+	Field    string `json:"field"` // post_id atau comment_id
+	TargetID string `json:"targetId"`
+	Page     int64  `json:"page"`
+	Limit    int64  `json:"limit"`
+}
+
+type ListLikersResponse struct {
+	Likers []*Liker `json:"likers"`
+	Total  int64    `json:"total"`
+	Page   int64    `json:"page"`
+}
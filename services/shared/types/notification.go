@@ -0,0 +1,181 @@
+package types
+
+// Jenis notifikasi in-app
+const (
+	NotificationNewFollower    = "new_follower"
+	NotificationFollowRequest  = "follow_request"
+	NotificationFollowAccepted = "follow_accepted"
+	NotificationPostLike       = "post_like"
+	NotificationCommentLike    = "comment_like"
+	NotificationComment        = "comment"
+	NotificationReply          = "reply"
+	NotificationMention        = "mention"
+	NotificationMessage        = "new_message"
+)
+
+// NotificationEvent dikirim oleh service lain ke notifications.Notifier
+type NotificationEvent struct {
+	Type    string
+	ActorID string
+	// Boleh kosong untuk like/comment/reply: penerima diambil dari pemilik TargetID
+	RecipientID string
+	// Post, comment, follow request atau percakapan yang menjadi subjek notifikasi
+	TargetID string
+	// Entitas yang memicu event (id comment, id pesan). Kosong berarti event yang sama
+	// dari actor yang sama hanya dicatat sekali (misal like berulang).
+	SourceID string
+	// Post atau percakapan induk, dipakai untuk mute thread (misal reply di post yang di-mute)
+	ThreadID string
+	Preview  string
+	// Khusus mention: username atau user id yang di-mention
+	Mentions []string
+}
+
+type NotificationActor struct {
+	UserId  string `json:"user_id"`
+	Name    string `json:"name"`
+	Picture string `json:"picture,omitempty"`
+}
+
+// Notification adalah satu grup event sejenis, misal "Budi and 12 others liked your post"
+type Notification struct {
+	GroupKey   string               `json:"group_key"`
+	Type       string               `json:"type"`
+	TargetID   string               `json:"target_id,omitempty"`
+	Actors     []*NotificationActor `json:"actors"`
+	ActorCount int                  `json:"actor_count"`
+	EventCount int                  `json:"event_count"`
+	Text       string               `json:"text"`
+	Preview    string               `json:"preview,omitempty"`
+	Unread     bool                 `json:"unread"`
+	LatestAt   int64                `json:"latest_at"`
+}
+
+type ListNotificationsRequest struct {
+	UserId     string `form:"-"`
+	UnreadOnly bool   `form:"unread"`
+	Limit      int    `form:"limit"`
+	Offset     int    `form:"offset"`
+}
+
+type ListNotificationsResponse struct {
+	Notifications []*Notification `json:"notifications"`
+	UnreadCount   int             `json:"unread_count"`
+}
+
+type UnreadCountResponse struct {
+	Count int `json:"count"`
+}
+
+// MarkNotificationsReadRequest: isi GroupKeys, atau All untuk menandai semuanya
+type MarkNotificationsReadRequest struct {
+	GroupKeys []string `json:"group_keys"`
+	All       bool     `json:"all"`
+}
+
+type MarkNotificationsReadResponse struct {
+	Updated     int64 `json:"updated"`
+	UnreadCount int   `json:"unread_count"`
+}
+
+// NotificationItem adalah satu event notifikasi (tanpa pengelompokan) yang dikirim
+// lewat stream SSE; Id dipakai sebagai SSE event id untuk resume
+type NotificationItem struct {
+	Id        int64              `json:"id"`
+	Type      string             `json:"type"`
+	GroupKey  string             `json:"group_key"`
+	TargetID  string             `json:"target_id,omitempty"`
+	Actor     *NotificationActor `json:"actor"`
+	Text      string             `json:"text"`
+	Preview   string             `json:"preview,omitempty"`
+	CreatedAt int64              `json:"created_at"`
+}
+
+// Channel pengiriman notifikasi
+const (
+	NotificationChannelInApp = "in_app"
+	NotificationChannelEmail = "email"
+	NotificationChannelPush  = "push"
+)
+
+var (
+	NotificationTypes = []string{
+		NotificationNewFollower, NotificationFollowRequest, NotificationFollowAccepted,
+		NotificationPostLike, NotificationCommentLike, NotificationComment,
+		NotificationReply, NotificationMention, NotificationMessage,
+	}
+	NotificationChannels = []string{NotificationChannelInApp, NotificationChannelEmail, NotificationChannelPush}
+)
+
+func IsValidNotificationType(t string) bool {
+	for _, v := range NotificationTypes {
+		if v == t {
+			return true
+		}
+	}
+	return false
+}
+
+func IsValidNotificationChannel(channel string) bool {
+	for _, v := range NotificationChannels {
+		if v == channel {
+			return true
+		}
+	}
+	return false
+}
+
+type NotificationPreference struct {
+	Type  string `json:"type"`
+	InApp bool   `json:"in_app"`
+	Email bool   `json:"email"`
+	Push  bool   `json:"push"`
+}
+
+// QuietHours memakai format jam "HH:MM" di timezone user
+type QuietHours struct {
+	Enabled bool   `json:"enabled"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+}
+
+type NotificationSettings struct {
+	Timezone   string     `json:"timezone"`
+	QuietHours QuietHours `json:"quiet_hours"`
+	// Digest email berkala saat user lama tidak membuka aplikasi
+	Digest      bool                      `json:"digest"`
+	Preferences []*NotificationPreference `json:"preferences"`
+}
+
+// NotificationPreferenceUpdate: field nil berarti tidak diubah
+type NotificationPreferenceUpdate struct {
+	Type  string `json:"type"`
+	InApp *bool  `json:"in_app"`
+	Email *bool  `json:"email"`
+	Push  *bool  `json:"push"`
+}
+
+type UpdateNotificationSettingsRequest struct {
+	Timezone    *string                         `json:"timezone"`
+	QuietHours  *QuietHours                     `json:"quiet_hours"`
+	Digest      *bool                           `json:"digest"`
+	Preferences []*NotificationPreferenceUpdate `json:"preferences"`
+}
+
+// Jenis target yang bisa di-mute
+const (
+	MuteTargetPost         = "post"
+	MuteTargetComment      = "comment"
+	MuteTargetConversation = "conversation"
+)
+
+type NotificationMute struct {
+	TargetID   string `json:"target_id"`
+	TargetType string `json:"target_type"`
+	CreatedAt  int64  `json:"created_at"`
+}
+
+type MuteNotificationsRequest struct {
+	TargetID   string `json:"target_id"`
+	TargetType string `json:"target_type"`
+}
@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 type Post struct {
 	Id           string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	UserId       string   `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
@@ -89,3 +91,34 @@ type UpdatePostRequest struct {
 	UserId  string `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // For authorization
 	Caption string `protobuf:"bytes,3,opt,name=caption,proto3" json:"caption,omitempty"`
 }
+
+type GetPostsByTagRequest struct {
+	ViewerID string `json:"viewer_id"`
+	Tag      string `json:"tag"`
+	Page     int32  `json:"page"`
+	PerPage  int32  `json:"per_page"`
+}
+
+type GetPostsByTagResponse struct {
+	Tag   string  `json:"tag"`
+	Posts []*Post `json:"posts"`
+	Total int64   `json:"total"`
+}
+
+type TrendingTag struct {
+	Tag       string  `json:"tag"`
+	PostCount int64   `json:"post_count"`
+	Score     float64 `json:"score"`
+}
+
+type GetTrendingTagsRequest struct {
+	ViewerID string        `json:"viewer_id"`
+	Window   time.Duration `json:"window"`
+	HalfLife time.Duration `json:"half_life"`
+	Limit    int32         `json:"limit"`
+}
+
+type GetTrendingTagsResponse struct {
+	Tags   []*TrendingTag `json:"tags"`
+	Window int64          `json:"window"` // dalam detik
+}
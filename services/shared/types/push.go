@@ -0,0 +1,27 @@
+package types
+
+// Platform subscription push
+const (
+	PushPlatformWeb = "web"
+)
+
+type PushKeys struct {
+	P256dh string `json:"p256dh"`
+	Auth   string `json:"auth"`
+}
+
+// RegisterPushSubscriptionRequest mengikuti bentuk PushSubscription.toJSON() di browser
+type RegisterPushSubscriptionRequest struct {
+	Platform string   `json:"platform"`
+	Endpoint string   `json:"endpoint"`
+	Keys     PushKeys `json:"keys"`
+}
+
+type PushSubscription struct {
+	Id         string `json:"id"`
+	Platform   string `json:"platform"`
+	Endpoint   string `json:"endpoint"`
+	UserAgent  string `json:"user_agent,omitempty"`
+	CreatedAt  int64  `json:"created_at"`
+	LastUsedAt int64  `json:"last_used_at,omitempty"`
+}
@@ -0,0 +1,52 @@
+package types
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ReactionTargetPost    = "post"
+	ReactionTargetComment = "comment"
+)
+
+// Reaction represents a single emoji reaction of a user on a post or comment
+type Reaction struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID     string             `bson:"user_id" json:"userId"`
+	TargetType string             `bson:"target_type" json:"targetType"`
+	TargetID   string             `bson:"target_id" json:"targetId"`
+	Reaction   string             `bson:"reaction" json:"reaction"`
+	CreatedAt  time.Time          `bson:"created_at" json:"createdAt"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updatedAt"`
+}
+
+type ReactionSummary struct {
+	TargetType string           `json:"targetType"`
+	TargetID   string           `json:"targetId"`
+	Counts     map[string]int64 `json:"counts"`
+	Total      int64            `json:"total"`
+	MyReaction string           `json:"myReaction,omitempty"`
+}
+
+type ReactedUser struct {
+	UserInfo  UserInfo  `json:"userInfo"`
+	Reaction  string    `json:"reaction"`
+	ReactedAt time.Time `json:"reactedAt"`
+}
+
+type ListReactedUsersRequest struct {
+	ViewerID   string `json:"viewerId"`
+	TargetType string `json:"targetType"`
+	TargetID   string `json:"targetId"`
+	Reaction   string `json:"reaction"`
+	Page       int64  `json:"page"`
+	Limit      int64  `json:"limit"`
+}
+
+type ListReactedUsersResponse struct {
+	Users []*ReactedUser `json:"users"`
+	Total int64          `json:"total"`
+	Page  int64          `json:"page"`
+}
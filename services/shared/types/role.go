@@ -0,0 +1,36 @@
+package types
+
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleUser, RoleModerator, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+type ListUsersRequest struct {
+	Query  string `form:"q"`
+	Role   string `form:"role"`
+	Limit  int    `form:"limit"`
+	Offset int    `form:"offset"`
+}
+
+type AdminUser struct {
+	UserInfo
+	IsActive bool `json:"is_active"`
+}
+
+type ListUsersResponse struct {
+	Users []*AdminUser `json:"users"`
+	Total int64        `json:"total"`
+}
+
+type UpdateRoleRequest struct {
+	Role string `json:"role"`
+}
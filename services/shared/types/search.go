@@ -0,0 +1,31 @@
+package types
+
+const (
+	SearchTypeUsers    = "users"
+	SearchTypePosts    = "posts"
+	SearchTypeComments = "comments"
+)
+
+type SearchRequest struct {
+	ViewerID string   `json:"viewer_id"`
+	Query    string   `json:"query"`
+	Types    []string `json:"types"`
+	Page     int32    `json:"page"`
+	Limit    int32    `json:"limit"`
+}
+
+type SearchUser struct {
+	UserId    string  `json:"user_id"`
+	Name      string  `json:"name"`
+	Username  string  `json:"username,omitempty"`
+	Picture   string  `json:"picture,omitempty"`
+	IsPrivacy bool    `json:"is_privacy"`
+	Rank      float64 `json:"rank"`
+}
+
+type SearchResponse struct {
+	Query    string        `json:"query"`
+	Users    []*SearchUser `json:"users,omitempty"`
+	Posts    []*Post       `json:"posts,omitempty"`
+	Comments []*Comment    `json:"comments,omitempty"`
+}
@@ -0,0 +1,40 @@
+package types
+
+// Jenis event di security log
+const (
+	SecurityEventLogin                   = "login"
+	SecurityEventNewDevice               = "new_device"
+	SecurityEventPasswordChanged         = "password_changed"
+	SecurityEventTwoFactorEnabled        = "2fa_enabled"
+	SecurityEventTwoFactorDisabled       = "2fa_disabled"
+	SecurityEventRecoveryCodesRegenerate = "recovery_codes_regenerated"
+	SecurityEventSessionRevoked          = "session_revoked"
+	SecurityEventSessionsRevoked         = "sessions_revoked"
+	SecurityEventAccessTokenCreated      = "access_token_created"
+	SecurityEventAccessTokenRevoked      = "access_token_revoked"
+)
+
+type SecurityEvent struct {
+	Id        int64             `json:"id"`
+	UserId    string            `json:"-"`
+	Type      string            `json:"type"`
+	IpAddress string            `json:"ip_address,omitempty"`
+	UserAgent string            `json:"user_agent,omitempty"`
+	Device    string            `json:"device,omitempty"`
+	Browser   string            `json:"browser,omitempty"`
+	OS        string            `json:"os,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	CreatedAt int64             `json:"created_at"`
+}
+
+type ListSecurityEventsRequest struct {
+	UserId string `form:"-"`
+	// Cursor: id event terakhir dari halaman sebelumnya
+	Before int64 `form:"before"`
+	Limit  int   `form:"limit"`
+}
+
+type ListSecurityEventsResponse struct {
+	Events     []*SecurityEvent `json:"events"`
+	NextBefore int64            `json:"next_before,omitempty"`
+}
@@ -0,0 +1,44 @@
+package types
+
+// Scope personal access token
+const (
+	ScopeRead          = "read"
+	ScopePostsWrite    = "posts:write"
+	ScopeCommentsWrite = "comments:write"
+	ScopeLikesWrite    = "likes:write"
+	ScopeModeration    = "moderation"
+)
+
+var AllScopes = []string{ScopeRead, ScopePostsWrite, ScopeCommentsWrite, ScopeLikesWrite, ScopeModeration}
+
+func IsValidScope(scope string) bool {
+	for _, s := range AllScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateAccessTokenRequest struct {
+	UserId        string   `json:"-"`
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+type AccessToken struct {
+	Id         string   `json:"id"`
+	Name       string   `json:"name"`
+	Prefix     string   `json:"prefix"`
+	Scopes     []string `json:"scopes"`
+	CreatedAt  int64    `json:"created_at"`
+	ExpiresAt  int64    `json:"expires_at"`
+	LastUsedAt int64    `json:"last_used_at,omitempty"`
+}
+
+// CreateAccessTokenResponse berisi token mentah yang hanya ditampilkan sekali
+type CreateAccessTokenResponse struct {
+	AccessToken
+	Token string `json:"token"`
+}
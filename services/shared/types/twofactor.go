@@ -0,0 +1,42 @@
+package types
+
+type TwoFactorStatus struct {
+	Enabled                bool `json:"enabled"`
+	RemainingRecoveryCodes int  `json:"remaining_recovery_codes"`
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type TwoFactorConfirmRequest struct {
+	UserId string `json:"-"`
+	Code   string `json:"code"`
+}
+
+type TwoFactorDisableRequest struct {
+	UserId   string `json:"-"`
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// TwoFactorRegenerateRequest butuh password dan kode 2FA, sama seperti disable
+type TwoFactorRegenerateRequest struct {
+	UserId   string `json:"-"`
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+// RecoveryCodesResponse hanya dikembalikan sekali; server hanya menyimpan hash-nya
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	// Code berisi kode TOTP 6 digit atau recovery code
+	Code       string `json:"code"`
+	DeviceInfo string `json:"-"`
+	IpAddress  string `json:"-"`
+}
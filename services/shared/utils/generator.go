@@ -1,7 +1,11 @@
 package utils
 
 import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"math/big"
 	"math/rand"
 	"strings"
 	"time"
@@ -24,7 +28,26 @@ type IDOptions struct {
 }
 
 func GenerateVerificationCode() string {
-	return fmt.Sprintf("%06d", rand.Intn(1000000))
+	n, err := crand.Int(crand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return fmt.Sprintf("%06d", rand.Intn(1000000))
+	}
+	return fmt.Sprintf("%06d", n.Int64())
+}
+
+// GenerateSecureToken membuat token acak hex dari n byte crypto/rand
+func GenerateSecureToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := crand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashToken menghasilkan sha256 hex dari token, dipakai agar token mentah tidak disimpan di database
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 func GenerateRandomId(folder string) string {
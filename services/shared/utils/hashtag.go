@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxHashtagLength batas panjang tag setelah dinormalisasi
+const MaxHashtagLength = 64
+
+// NormalizeHashtag mengubah tag menjadi bentuk baku: tanpa '#', huruf kecil,
+// hanya huruf, angka dan underscore. Mengembalikan string kosong jika tag tidak valid.
+func NormalizeHashtag(tag string) string {
+	tag = strings.TrimSpace(tag)
+	tag = strings.TrimLeft(tag, "#")
+
+	var b strings.Builder
+	for _, r := range tag {
+		if !isHashtagRune(r) {
+			break
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	normalized := b.String()
+	if normalized == "" || len(normalized) > MaxHashtagLength {
+		return ""
+	}
+
+	// Tag yang hanya berisi angka (misal "#1") tidak dianggap hashtag
+	if strings.IndexFunc(normalized, func(r rune) bool { return !unicode.IsDigit(r) }) == -1 {
+		return ""
+	}
+
+	return normalized
+}
+
+// ExtractHashtags mengambil semua hashtag dari caption dalam urutan kemunculan tanpa duplikat
+func ExtractHashtags(caption string) []string {
+	var tags []string
+	runes := []rune(caption)
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '#' {
+			continue
+		}
+		// "#" harus berada di awal kata, bukan bagian dari kata lain (misal "abc#def")
+		if i > 0 && isHashtagRune(runes[i-1]) {
+			continue
+		}
+
+		j := i + 1
+		for j < len(runes) && isHashtagRune(runes[j]) {
+			j++
+		}
+		if tag := NormalizeHashtag(string(runes[i+1 : j])); tag != "" {
+			tags = append(tags, tag)
+		}
+		i = j - 1
+	}
+
+	return MergeHashtags(tags)
+}
+
+// MergeHashtags menormalisasi dan menggabungkan beberapa daftar tag tanpa duplikat
+func MergeHashtags(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	merged := []string{}
+
+	for _, list := range lists {
+		for _, tag := range list {
+			normalized := NormalizeHashtag(tag)
+			if normalized == "" || seen[normalized] {
+				continue
+			}
+			seen[normalized] = true
+			merged = append(merged, normalized)
+		}
+	}
+
+	return merged
+}
+
+func isHashtagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
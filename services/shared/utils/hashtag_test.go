@@ -0,0 +1,58 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/chatting-app/services/shared/utils"
+)
+
+func TestExtractHashtags(t *testing.T) {
+	tests := []struct {
+		name    string
+		caption string
+		want    []string
+	}{
+		{
+			name:    "simple tags",
+			caption: "Sunset di pantai #Bali #travel",
+			want:    []string{"bali", "travel"},
+		},
+		{
+			name:    "duplicate tags with different case",
+			caption: "#Golang is fun #golang #GOLANG",
+			want:    []string{"golang"},
+		},
+		{
+			name:    "punctuation ends the tag",
+			caption: "liburan #summer2024, #beach!",
+			want:    []string{"summer2024", "beach"},
+		},
+		{
+			name:    "ignore numeric and embedded tags",
+			caption: "rank #1 email me@x.com abc#def ##double",
+			want:    []string{"double"},
+		},
+		{
+			name:    "unicode letters",
+			caption: "#café #東京",
+			want:    []string{"café", "東京"},
+		},
+		{
+			name:    "no tags",
+			caption: "just a caption",
+			want:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, utils.ExtractHashtags(tt.caption))
+		})
+	}
+}
+
+func TestMergeHashtags(t *testing.T) {
+	got := utils.MergeHashtags([]string{"#Go", " travel ", ""}, []string{"go", "food"})
+	assert.Equal(t, []string{"go", "travel", "food"}, got)
+}
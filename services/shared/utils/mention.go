@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxMentionLength batas panjang username yang dianggap mention
+const MaxMentionLength = 64
+
+// ExtractMentions mengambil username yang di-mention ("@budi") dari teks, dalam urutan
+// kemunculan tanpa duplikat (tidak case-sensitive). Alamat email tidak dianggap mention.
+func ExtractMentions(text string) []string {
+	var mentions []string
+	seen := make(map[string]bool)
+	runes := []rune(text)
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '@' {
+			continue
+		}
+		if i > 0 && (isMentionRune(runes[i-1]) || runes[i-1] == '@') {
+			continue
+		}
+
+		j := i + 1
+		for j < len(runes) && isMentionRune(runes[j]) {
+			j++
+		}
+		// Titik di akhir biasanya tanda baca, bukan bagian username
+		name := strings.TrimRight(string(runes[i+1:j]), ".")
+		i = j - 1
+
+		key := strings.ToLower(name)
+		if name == "" || len(name) > MaxMentionLength || seen[key] {
+			continue
+		}
+		seen[key] = true
+		mentions = append(mentions, name)
+	}
+
+	return mentions
+}
+
+func isMentionRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.'
+}
@@ -0,0 +1,43 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/wafi04/chatting-app/services/shared/utils"
+)
+
+func TestExtractMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{
+			name: "simple mentions",
+			text: "makan bareng @budi dan @ani_99",
+			want: []string{"budi", "ani_99"},
+		},
+		{
+			name: "duplicates ignore case",
+			text: "@Budi @budi @BUDI",
+			want: []string{"Budi"},
+		},
+		{
+			name: "trailing punctuation",
+			text: "thanks @ani.sari. and @joko!",
+			want: []string{"ani.sari", "joko"},
+		},
+		{
+			name: "emails and lone at signs",
+			text: "mail me@x.com or @ @@double",
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, utils.ExtractMentions(tt.text))
+		})
+	}
+}
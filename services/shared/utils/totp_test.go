@@ -0,0 +1,66 @@
+package utils_test
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/wafi04/chatting-app/services/shared/utils"
+)
+
+// Test vector RFC 6238 (SHA1, secret "12345678901234567890"), dipotong ke 6 digit
+func TestTOTPCodeRFCVectors(t *testing.T) {
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := utils.TOTPCode(secret, utils.TOTPStep(time.Unix(tt.unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, code, "unix=%d", tt.unix)
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	secret, err := utils.GenerateTOTPSecret()
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+
+	code, err := utils.TOTPCode(secret, utils.TOTPStep(now)-1)
+	require.NoError(t, err)
+
+	step, ok := utils.ValidateTOTP(secret, code, now)
+	assert.True(t, ok)
+	assert.Equal(t, utils.TOTPStep(now)-1, step)
+
+	_, ok = utils.ValidateTOTP(secret, code, now.Add(3*utils.TOTPPeriod*time.Second))
+	assert.False(t, ok)
+
+	_, ok = utils.ValidateTOTP(secret, "12345", now)
+	assert.False(t, ok)
+}
+
+func TestTOTPProvisioningURI(t *testing.T) {
+	uri := utils.TOTPProvisioningURI("Chatting App", "a@example.com", "ABC")
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Chatting%20App:a@example.com?"))
+	assert.Contains(t, uri, "secret=ABC")
+	assert.Contains(t, uri, "issuer=Chatting+App")
+}
+
+func TestRecoveryCode(t *testing.T) {
+	code, err := utils.GenerateRecoveryCode()
+	require.NoError(t, err)
+	assert.Len(t, code, 11)
+	assert.Equal(t, code, utils.NormalizeRecoveryCode(strings.ToUpper(strings.ReplaceAll(code, "-", " "))))
+}
@@ -0,0 +1,119 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+	DeviceOther   = "other"
+)
+
+type UserAgent struct {
+	Browser        string `json:"browser"`
+	BrowserVersion string `json:"browser_version,omitempty"`
+	OS             string `json:"os"`
+	OSVersion      string `json:"os_version,omitempty"`
+	Device         string `json:"device"`
+}
+
+// Fingerprint mengidentifikasi "perangkat" secara kasar tanpa versi browser maupun OS,
+// supaya update browser atau OS tidak dianggap perangkat baru
+func (ua UserAgent) Fingerprint() string {
+	return ua.Browser + "|" + ua.OS + "|" + ua.Device
+}
+
+func (ua UserAgent) String() string {
+	return ua.Browser + " on " + ua.OSName()
+}
+
+// OSName menggabungkan nama dan versi OS untuk ditampilkan, misal "iOS 17.1"
+func (ua UserAgent) OSName() string {
+	if ua.OSVersion == "" {
+		return ua.OS
+	}
+	return ua.OS + " " + ua.OSVersion
+}
+
+// Urutan penting: Edge/Opera/Samsung mengandung "Chrome", Chrome mengandung "Safari"
+var browserPatterns = []struct {
+	name    string
+	pattern *regexp.Regexp
+}{
+	{"Edge", regexp.MustCompile(`(?:Edg|Edge|EdgA|EdgiOS)/([\d.]+)`)},
+	{"Opera", regexp.MustCompile(`(?:OPR|Opera)/([\d.]+)`)},
+	{"Samsung Internet", regexp.MustCompile(`SamsungBrowser/([\d.]+)`)},
+	{"Chrome", regexp.MustCompile(`(?:Chrome|CriOS)/([\d.]+)`)},
+	{"Firefox", regexp.MustCompile(`(?:Firefox|FxiOS)/([\d.]+)`)},
+	{"Safari", regexp.MustCompile(`Version/([\d.]+).*Safari/`)},
+	{"curl", regexp.MustCompile(`^curl/([\d.]+)`)},
+	{"Python", regexp.MustCompile(`python-requests/([\d.]+)`)},
+	{"Go", regexp.MustCompile(`Go-http-client/([\d.]+)`)},
+	{"okhttp", regexp.MustCompile(`okhttp/([\d.]+)`)},
+}
+
+var (
+	androidVersion = regexp.MustCompile(`Android ([\d.]+)`)
+	iosVersion     = regexp.MustCompile(`OS (\d+)[_.](\d+)`)
+	botPattern     = regexp.MustCompile(`(?i)bot|crawler|spider|slurp`)
+)
+
+// ParseUserAgent mengurai header User-Agent menjadi browser, OS dan jenis perangkat
+func ParseUserAgent(raw string) UserAgent {
+	ua := UserAgent{Browser: "Unknown", OS: "Unknown", Device: DeviceOther}
+	if raw == "" {
+		return ua
+	}
+
+	for _, b := range browserPatterns {
+		if m := b.pattern.FindStringSubmatch(raw); m != nil {
+			ua.Browser = b.name
+			ua.BrowserVersion = m[1]
+			break
+		}
+	}
+
+	switch {
+	case strings.Contains(raw, "iPad"):
+		ua.OS = "iPadOS"
+		if m := iosVersion.FindStringSubmatch(raw); m != nil {
+			ua.OSVersion = m[1] + "." + m[2]
+		}
+	case strings.Contains(raw, "iPhone") || strings.Contains(raw, "iPod"):
+		ua.OS = "iOS"
+		if m := iosVersion.FindStringSubmatch(raw); m != nil {
+			ua.OSVersion = m[1] + "." + m[2]
+		}
+	case strings.Contains(raw, "Android"):
+		ua.OS = "Android"
+		if m := androidVersion.FindStringSubmatch(raw); m != nil {
+			ua.OSVersion = m[1]
+		}
+	case strings.Contains(raw, "Windows"):
+		ua.OS = "Windows"
+	case strings.Contains(raw, "CrOS"):
+		ua.OS = "ChromeOS"
+	case strings.Contains(raw, "Mac OS X") || strings.Contains(raw, "Macintosh"):
+		ua.OS = "macOS"
+	case strings.Contains(raw, "Linux"):
+		ua.OS = "Linux"
+	}
+
+	switch {
+	case botPattern.MatchString(raw):
+		ua.Device = DeviceBot
+	case strings.Contains(raw, "iPad"), strings.Contains(raw, "Tablet"),
+		strings.Contains(raw, "Android") && !strings.Contains(raw, "Mobile"):
+		ua.Device = DeviceTablet
+	case strings.Contains(raw, "Mobi"), strings.Contains(raw, "iPhone"):
+		ua.Device = DeviceMobile
+	case strings.HasPrefix(raw, "Mozilla/"):
+		ua.Device = DeviceDesktop
+	}
+
+	return ua
+}
@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		raw     string
+		browser string
+		os      string
+		device  string
+	}{
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			"Chrome", "Windows", DeviceDesktop,
+		},
+		{
+			"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91",
+			"Edge", "Windows", DeviceDesktop,
+		},
+		{
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Safari/605.1.15",
+			"Safari", "macOS", DeviceDesktop,
+		},
+		{
+			"Mozilla/5.0 (iPhone; CPU iPhone OS 17_1_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1",
+			"Safari", "iOS", DeviceMobile,
+		},
+		{
+			"Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.144 Mobile Safari/537.36",
+			"Chrome", "Android", DeviceMobile,
+		},
+		{
+			"Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:121.0) Gecko/20100101 Firefox/121.0",
+			"Firefox", "Linux", DeviceDesktop,
+		},
+		{
+			"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			"Unknown", "Unknown", DeviceBot,
+		},
+		{"curl/8.4.0", "curl", "Unknown", DeviceOther},
+		{"", "Unknown", "Unknown", DeviceOther},
+	}
+
+	for _, tt := range tests {
+		ua := ParseUserAgent(tt.raw)
+		assert.Equal(t, tt.browser, ua.Browser, tt.raw)
+		assert.Equal(t, tt.os, ua.OS, tt.raw)
+		assert.Equal(t, tt.device, ua.Device, tt.raw)
+	}
+
+	// update versi browser tidak mengubah fingerprint
+	a := ParseUserAgent("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/119.0.0.0 Safari/537.36")
+	b := ParseUserAgent("Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	assert.Equal(t, a.Fingerprint(), b.Fingerprint())
+
+	// update iOS juga tidak mengubah fingerprint, versinya tetap tersedia untuk ditampilkan
+	ios17 := ParseUserAgent("Mozilla/5.0 (iPhone; CPU iPhone OS 17_1_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.1 Mobile/15E148 Safari/604.1")
+	ios18 := ParseUserAgent("Mozilla/5.0 (iPhone; CPU iPhone OS 18_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/18.0 Mobile/15E148 Safari/604.1")
+	assert.Equal(t, ios17.Fingerprint(), ios18.Fingerprint())
+	assert.Equal(t, "iOS 17.1", ios17.OSName())
+	assert.Equal(t, "iOS 18.0", ios18.OSName())
+}